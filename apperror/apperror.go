@@ -0,0 +1,98 @@
+// Package apperror mendefinisikan error aplikasi yang membawa status HTTP dan
+// kode yang bisa dibaca mesin. Handler cukup memanggil c.Error(err); middleware
+// ErrorHandler yang mengubahnya menjadi response standar.
+package apperror
+
+import (
+	"net/http"
+)
+
+// Kode error umum. Frontend sebaiknya bercabang berdasarkan kode, bukan pesan.
+const (
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeValidation      = "VALIDATION_ERROR"
+	CodeUnauthorized    = "UNAUTHORIZED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeConflict        = "CONFLICT"
+	CodeTooManyRequests = "TOO_MANY_REQUESTS"
+	CodeInternal        = "INTERNAL_ERROR"
+	CodeUnavailable     = "SERVICE_UNAVAILABLE"
+)
+
+// Kode error spesifik untuk alur autentikasi
+const (
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeAccountInactive    = "ACCOUNT_INACTIVE"
+	CodeAccountLocked      = "ACCOUNT_LOCKED"
+	CodeInvalidTOTP        = "INVALID_TWO_FACTOR_CODE"
+)
+
+// Error adalah error aplikasi beserta status HTTP dan kodenya
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details interface{}
+	Err     error // Penyebab asli, hanya untuk log dan tidak pernah dikirim ke client
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap mengembalikan penyebab asli agar errors.Is/As tetap bekerja
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails mengembalikan salinan error dengan detail tambahan
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// New membuat error dengan status dan kode bebas
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// BadRequest untuk request yang tidak bisa dibaca (JSON rusak, ID tidak valid)
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Validation untuk input yang terbaca tetapi nilainya tidak memenuhi aturan
+func Validation(message string) *Error {
+	return New(http.StatusBadRequest, CodeValidation, message)
+}
+
+// Unauthorized untuk request tanpa kredensial yang valid
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden untuk user yang dikenali tetapi tidak berhak
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound untuk data yang tidak ditemukan
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict untuk operasi yang bertabrakan dengan data yang sudah ada
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// Internal untuk kegagalan server. Pesan dikirim ke client, err hanya dicatat di log.
+func Internal(message string, err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: message, Err: err}
+}
@@ -0,0 +1,118 @@
+// Package audit membawa identitas pelaku perubahan data lewat context.Context
+// dan menghitung selisih field sebelum/sesudah untuk audit log.
+package audit
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actor adalah pelaku perubahan: user yang login beserta asal request-nya.
+// Actor kosong berarti perubahan dilakukan sistem, misalnya job terjadwal.
+type Actor struct {
+	UserID    *primitive.ObjectID
+	IP        string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor menyimpan actor di context agar ikut tercatat oleh repository
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom mengambil actor dari context; actor kosong jika tidak ada
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// redacted adalah field rahasia yang nilainya tidak boleh masuk audit log;
+// perubahannya tetap dicatat tanpa isi
+var redacted = map[string]bool{
+	"password":       true,
+	"totp_secret":    true,
+	"totp_last_step": true,
+	"recovery_codes": true,
+}
+
+// redactedValue menggantikan nilai field rahasia
+const redactedValue = "[redacted]"
+
+// Diff membandingkan dua dokumen berdasarkan field bson tingkat atas dan
+// mengembalikan field yang berubah, urut nama field. before atau after boleh
+// nil (create dan delete). Field _id tidak pernah dimasukkan.
+func Diff(before, after interface{}) ([]models.AuditChange, error) {
+	b, err := toDoc(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toDoc(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for k := range b {
+		fields[k] = true
+	}
+	for k := range a {
+		fields[k] = true
+	}
+	delete(fields, "_id")
+
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	changes := []models.AuditChange{}
+	for _, name := range names {
+		bv, av := b[name], a[name]
+		if reflect.DeepEqual(bv, av) {
+			continue
+		}
+		if redacted[name] {
+			bv, av = redact(bv), redact(av)
+		}
+		changes = append(changes, models.AuditChange{Field: name, Before: bv, After: av})
+	}
+	return changes, nil
+}
+
+func redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return redactedValue
+}
+
+// toDoc mengubah struct menjadi bson.M dengan nama field seperti di database
+func toDoc(v interface{}) (bson.M, error) {
+	if v == nil {
+		return bson.M{}, nil
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Sub-dokumen juga dibaca sebagai map agar tampil sebagai objek di JSON
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	dec.DefaultDocumentM()
+	doc := bson.M{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
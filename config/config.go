@@ -0,0 +1,492 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Profil environment yang dikenali
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Duration adalah time.Duration yang bisa dibaca dari string seperti "15m" di file YAML/TOML
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText mem-parsing format durasi Go ("10s", "15m", "168h")
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// ServerConfig mengatur HTTP server
+type ServerConfig struct {
+	ListenAddr      string   `yaml:"listen_addr" toml:"listen_addr"`
+	BaseURL         string   `yaml:"base_url" toml:"base_url"`     // Alamat publik, dipakai untuk link di email
+	ResetURL        string   `yaml:"reset_url" toml:"reset_url"`   // Halaman frontend reset password (token ditambahkan sebagai ?token=); kosong berarti GET {BaseURL}/auth/reset-password
+	VerifyURL       string   `yaml:"verify_url" toml:"verify_url"` // Halaman frontend verifikasi email; kosong berarti GET {BaseURL}/auth/verify-email
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+}
+
+// MongoConfig mengatur koneksi MongoDB
+type MongoConfig struct {
+	URI            string   `yaml:"uri" toml:"uri"`
+	Database       string   `yaml:"database" toml:"database"`
+	ConnectTimeout Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	AutoMigrate    bool     `yaml:"auto_migrate" toml:"auto_migrate"` // Jalankan migration yang tertunda saat server start
+}
+
+// JWTConfig mengatur kunci dan masa berlaku token
+type JWTConfig struct {
+	Secret          string   `yaml:"secret" toml:"secret"`
+	KeysDir         string   `yaml:"keys_dir" toml:"keys_dir"`
+	ActiveKID       string   `yaml:"active_kid" toml:"active_kid"`
+	AccessTokenTTL  Duration `yaml:"access_token_ttl" toml:"access_token_ttl"`
+	RefreshTokenTTL Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"`
+}
+
+// MailConfig mengatur pengiriman email
+type MailConfig struct {
+	Driver       string `yaml:"driver" toml:"driver"` // "smtp" atau "log"
+	SMTPHost     string `yaml:"smtp_host" toml:"smtp_host"`
+	SMTPPort     string `yaml:"smtp_port" toml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username" toml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password" toml:"smtp_password"`
+	From         string `yaml:"from" toml:"from"`
+	LogFile      string `yaml:"log_file" toml:"log_file"`
+}
+
+// SecurityConfig mengatur 2FA
+type SecurityConfig struct {
+	TOTPIssuer        string   `yaml:"totp_issuer" toml:"totp_issuer"`
+	TOTPRequiredRoles []string `yaml:"totp_required_roles" toml:"totp_required_roles"`
+}
+
+// TrashConfig mengatur penghapusan permanen data yang sudah di-soft delete
+type TrashConfig struct {
+	Retention     Duration `yaml:"retention" toml:"retention"` // Lama data disimpan di trash; 0 berarti tidak pernah dihapus permanen
+	PurgeInterval Duration `yaml:"purge_interval" toml:"purge_interval"`
+}
+
+// LateFeeConfig mengatur denda keterlambatan tagihan. Flat dan Percent 0 berarti tanpa denda.
+type LateFeeConfig struct {
+	Flat       float64 `yaml:"flat" toml:"flat"`               // Nominal tetap per denda
+	Percent    float64 `yaml:"percent" toml:"percent"`         // Persen dari nominal tagihan sebelum denda
+	GraceDays  int     `yaml:"grace_days" toml:"grace_days"`   // Hari toleransi setelah jatuh tempo
+	RepeatDays int     `yaml:"repeat_days" toml:"repeat_days"` // Denda diulang setiap sekian hari; 0 berarti sekali
+	MaxFees    int     `yaml:"max_fees" toml:"max_fees"`       // Batas jumlah denda per tagihan; 0 berarti tidak dibatasi
+}
+
+// BillingConfig mengatur job penagihan berkala
+type BillingConfig struct {
+	IssueInterval   Duration      `yaml:"issue_interval" toml:"issue_interval"`     // Jeda pengecekan jadwal tagihan; 0 mematikan penerbitan otomatis
+	OverdueInterval Duration      `yaml:"overdue_interval" toml:"overdue_interval"` // Jeda pengecekan tagihan terlambat; 0 mematikan job ini
+	LateFee         LateFeeConfig `yaml:"late_fee" toml:"late_fee"`
+}
+
+// SchoolConfig adalah identitas sekolah yang dicetak di kop invoice dan kuitansi
+type SchoolConfig struct {
+	Name    string `yaml:"name" toml:"name"`
+	Address string `yaml:"address" toml:"address"`
+	Phone   string `yaml:"phone" toml:"phone"`
+	Email   string `yaml:"email" toml:"email"`
+	Website string `yaml:"website" toml:"website"`
+}
+
+// InvoiceConfig mengatur penomoran invoice dan kuitansi, contoh INV/2026/000001
+type InvoiceConfig struct {
+	InvoicePrefix string `yaml:"invoice_prefix" toml:"invoice_prefix"`
+	ReceiptPrefix string `yaml:"receipt_prefix" toml:"receipt_prefix"`
+}
+
+// Config adalah seluruh konfigurasi aplikasi
+type Config struct {
+	Env         string          `yaml:"env" toml:"env"`
+	Server      ServerConfig    `yaml:"server" toml:"server"`
+	Mongo       MongoConfig     `yaml:"mongo" toml:"mongo"`
+	JWT         JWTConfig       `yaml:"jwt" toml:"jwt"`
+	Mail        MailConfig      `yaml:"mail" toml:"mail"`
+	Security    SecurityConfig  `yaml:"security" toml:"security"`
+	Trash       TrashConfig     `yaml:"trash" toml:"trash"`
+	Billing     BillingConfig   `yaml:"billing" toml:"billing"`
+	School      SchoolConfig    `yaml:"school" toml:"school"`
+	Invoice     InvoiceConfig   `yaml:"invoice" toml:"invoice"`
+	CORSOrigins []string        `yaml:"cors_origins" toml:"cors_origins"`
+	Timezone    string          `yaml:"timezone" toml:"timezone"`
+	Locale      string          `yaml:"locale" toml:"locale"` // Bahasa default jika client tidak mengirim Accept-Language
+	Features    map[string]bool `yaml:"features" toml:"features"`
+
+	location *time.Location
+}
+
+// defaults mengembalikan nilai bawaan sesuai profil environment
+func defaults(env string) *Config {
+	cfg := &Config{
+		Env: env,
+		Server: ServerConfig{
+			ListenAddr:      ":8080",
+			BaseURL:         "http://localhost:8080",
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			ShutdownTimeout: Duration{15 * time.Second},
+		},
+		Mongo: MongoConfig{
+			Database:       "tubesbackend",
+			ConnectTimeout: Duration{10 * time.Second},
+			AutoMigrate:    true,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  Duration{15 * time.Minute},
+			RefreshTokenTTL: Duration{7 * 24 * time.Hour},
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			SMTPPort: "587",
+		},
+		Security: SecurityConfig{
+			TOTPIssuer: "Tubes Backend",
+		},
+		Trash: TrashConfig{
+			Retention:     Duration{30 * 24 * time.Hour},
+			PurgeInterval: Duration{24 * time.Hour},
+		},
+		Billing: BillingConfig{
+			IssueInterval:   Duration{time.Hour},
+			OverdueInterval: Duration{time.Hour},
+		},
+		School: SchoolConfig{
+			Name: "Tubes Backend",
+		},
+		Invoice: InvoiceConfig{
+			InvoicePrefix: "INV",
+			ReceiptPrefix: "KWT",
+		},
+		Timezone: "Asia/Jakarta",
+		Locale:   i18n.Indonesian,
+		Features: map[string]bool{
+			"registration":        true, // POST /auth/register
+			"course_registration": true, // POST /courses/register (formulir publik)
+		},
+	}
+
+	if env == EnvDevelopment {
+		cfg.CORSOrigins = []string{"http://127.0.0.1:5504", "http://localhost:5504"}
+	}
+	if env == EnvProduction {
+		// Di production admin wajib memakai 2FA
+		cfg.Security.TOTPRequiredRoles = []string{"admin"}
+	}
+	return cfg
+}
+
+// Load membaca konfigurasi dengan urutan prioritas (yang belakang menimpa):
+// default profil -> file konfigurasi (CONFIG_FILE, .yaml/.yml/.toml) -> environment / .env.
+// Profil dipilih lewat APP_ENV (development, staging, production).
+func Load() (*Config, error) {
+	// .env opsional, variabel environment yang sudah ada tidak ditimpa
+	_ = godotenv.Load()
+
+	env := strings.ToLower(os.Getenv("APP_ENV"))
+	if env == "" {
+		env = EnvDevelopment
+	}
+	cfg := defaults(env)
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+		// APP_ENV tetap menjadi acuan profil walaupun file mengisi env
+		cfg.Env = env
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile membaca file YAML atau TOML di atas nilai yang sudah ada
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		return toml.Unmarshal(data, cfg)
+	}
+	return errors.New("unsupported config file extension (use .yaml, .yml or .toml)")
+}
+
+// applyEnv menimpa konfigurasi dengan variabel environment yang diisi
+func (cfg *Config) applyEnv() error {
+	setString := func(key string, dst *string) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			*dst = v
+		}
+	}
+	setList := func(key string, dst *[]string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = splitList(v)
+		}
+	}
+
+	var errs []error
+	setBool := func(key string, dst *bool) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = b
+		}
+	}
+	setInt := func(key string, dst *int) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = n
+		}
+	}
+	setFloat := func(key string, dst *float64) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = f
+		}
+	}
+	setDuration := func(key string, dst *Duration) {
+		if v, ok := os.LookupEnv(key); ok && v != "" {
+			if err := dst.UnmarshalText([]byte(v)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+
+	setString("LISTEN_ADDR", &cfg.Server.ListenAddr)
+	if port := os.Getenv("PORT"); port != "" && os.Getenv("LISTEN_ADDR") == "" {
+		cfg.Server.ListenAddr = ":" + port
+	}
+	setString("APP_BASE_URL", &cfg.Server.BaseURL)
+	setString("RESET_PASSWORD_URL", &cfg.Server.ResetURL)
+	setString("VERIFY_EMAIL_URL", &cfg.Server.VerifyURL)
+	setDuration("READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	setDuration("WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	setDuration("SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+
+	setString("MONGOSTRING", &cfg.Mongo.URI)
+	setString("DB_NAME", &cfg.Mongo.Database)
+	setDuration("DB_CONNECT_TIMEOUT", &cfg.Mongo.ConnectTimeout)
+	setBool("AUTO_MIGRATE", &cfg.Mongo.AutoMigrate)
+
+	setString("JWT_SECRET", &cfg.JWT.Secret)
+	setString("JWT_KEYS_DIR", &cfg.JWT.KeysDir)
+	setString("JWT_ACTIVE_KID", &cfg.JWT.ActiveKID)
+	setDuration("ACCESS_TOKEN_TTL", &cfg.JWT.AccessTokenTTL)
+	setDuration("REFRESH_TOKEN_TTL", &cfg.JWT.RefreshTokenTTL)
+
+	setString("MAIL_DRIVER", &cfg.Mail.Driver)
+	setString("SMTP_HOST", &cfg.Mail.SMTPHost)
+	setString("SMTP_PORT", &cfg.Mail.SMTPPort)
+	setString("SMTP_USERNAME", &cfg.Mail.SMTPUsername)
+	setString("SMTP_PASSWORD", &cfg.Mail.SMTPPassword)
+	setString("MAIL_FROM", &cfg.Mail.From)
+	setString("MAIL_LOG_FILE", &cfg.Mail.LogFile)
+
+	setString("TOTP_ISSUER", &cfg.Security.TOTPIssuer)
+	setList("TOTP_REQUIRED_ROLES", &cfg.Security.TOTPRequiredRoles)
+
+	setDuration("TRASH_RETENTION", &cfg.Trash.Retention)
+	setDuration("TRASH_PURGE_INTERVAL", &cfg.Trash.PurgeInterval)
+
+	setDuration("BILLING_ISSUE_INTERVAL", &cfg.Billing.IssueInterval)
+	setDuration("BILLING_OVERDUE_INTERVAL", &cfg.Billing.OverdueInterval)
+	setFloat("LATE_FEE_FLAT", &cfg.Billing.LateFee.Flat)
+	setFloat("LATE_FEE_PERCENT", &cfg.Billing.LateFee.Percent)
+	setInt("LATE_FEE_GRACE_DAYS", &cfg.Billing.LateFee.GraceDays)
+	setInt("LATE_FEE_REPEAT_DAYS", &cfg.Billing.LateFee.RepeatDays)
+	setInt("LATE_FEE_MAX_FEES", &cfg.Billing.LateFee.MaxFees)
+
+	setString("SCHOOL_NAME", &cfg.School.Name)
+	setString("SCHOOL_ADDRESS", &cfg.School.Address)
+	setString("SCHOOL_PHONE", &cfg.School.Phone)
+	setString("SCHOOL_EMAIL", &cfg.School.Email)
+	setString("SCHOOL_WEBSITE", &cfg.School.Website)
+	setString("INVOICE_PREFIX", &cfg.Invoice.InvoicePrefix)
+	setString("RECEIPT_PREFIX", &cfg.Invoice.ReceiptPrefix)
+
+	setList("CORS_ORIGINS", &cfg.CORSOrigins)
+	setString("TIMEZONE", &cfg.Timezone)
+	setString("LOCALE", &cfg.Locale)
+
+	// FEATURE_<NAMA>=true/false, contoh FEATURE_REGISTRATION=false
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, "FEATURE_") {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if cfg.Features == nil {
+			cfg.Features = map[string]bool{}
+		}
+		cfg.Features[strings.ToLower(strings.TrimPrefix(key, "FEATURE_"))] = enabled
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate memeriksa konfigurasi saat startup. Semua kesalahan dikumpulkan sekaligus.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	switch cfg.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		errs = append(errs, fmt.Errorf("APP_ENV must be development, staging or production, got %q", cfg.Env))
+	}
+
+	if cfg.Server.ListenAddr == "" {
+		errs = append(errs, errors.New("server listen address is required"))
+	}
+	if cfg.Mongo.URI == "" {
+		errs = append(errs, errors.New("MONGOSTRING is required"))
+	}
+	if cfg.Mongo.Database == "" {
+		errs = append(errs, errors.New("database name is required"))
+	}
+	if cfg.JWT.AccessTokenTTL.Duration <= 0 || cfg.JWT.RefreshTokenTTL.Duration <= cfg.JWT.AccessTokenTTL.Duration {
+		errs = append(errs, errors.New("refresh token TTL must be longer than a positive access token TTL"))
+	}
+
+	// cors.New panic jika daftar origin kosong; hanya profil development yang punya default
+	if len(cfg.CORSOrigins) == 0 {
+		errs = append(errs, errors.New("CORS_ORIGINS is required"))
+	}
+
+	if cfg.Trash.Retention.Duration < 0 {
+		errs = append(errs, errors.New("trash retention must not be negative"))
+	}
+	if cfg.Trash.Retention.Duration > 0 && cfg.Trash.PurgeInterval.Duration <= 0 {
+		errs = append(errs, errors.New("trash purge interval must be positive"))
+	}
+	if cfg.Billing.IssueInterval.Duration < 0 || cfg.Billing.OverdueInterval.Duration < 0 {
+		errs = append(errs, errors.New("billing job intervals must not be negative"))
+	}
+	fee := cfg.Billing.LateFee
+	if fee.Flat < 0 || fee.Percent < 0 || fee.Percent > 100 {
+		errs = append(errs, errors.New("late fee must not be negative and late fee percent must not exceed 100"))
+	}
+	if fee.GraceDays < 0 || fee.RepeatDays < 0 || fee.MaxFees < 0 {
+		errs = append(errs, errors.New("late fee grace days, repeat days and max fees must not be negative"))
+	}
+
+	if cfg.School.Name == "" {
+		errs = append(errs, errors.New("school name is required for invoice headers"))
+	}
+	if cfg.Invoice.InvoicePrefix == "" || cfg.Invoice.ReceiptPrefix == "" || cfg.Invoice.InvoicePrefix == cfg.Invoice.ReceiptPrefix {
+		errs = append(errs, errors.New("invoice and receipt number prefixes are required and must differ"))
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err))
+	}
+	cfg.location = loc
+
+	if !i18n.Supported(cfg.Locale) {
+		errs = append(errs, fmt.Errorf("unsupported locale %q", cfg.Locale))
+	}
+
+	switch cfg.Mail.Driver {
+	case "log":
+	case "smtp":
+		if cfg.Mail.SMTPHost == "" || cfg.Mail.From == "" {
+			errs = append(errs, errors.New("SMTP_HOST and MAIL_FROM are required for the smtp mail driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_DRIVER must be smtp or log, got %q", cfg.Mail.Driver))
+	}
+
+	if cfg.Env == EnvProduction {
+		if cfg.JWT.Secret == "" && cfg.JWT.KeysDir == "" {
+			errs = append(errs, errors.New("JWT_SECRET or JWT_KEYS_DIR is required in production"))
+		}
+		if cfg.Mail.Driver != "smtp" {
+			errs = append(errs, errors.New("MAIL_DRIVER must be smtp in production"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Location mengembalikan zona waktu aplikasi (default Asia/Jakarta)
+func (cfg *Config) Location() *time.Location {
+	if cfg.location == nil {
+		if loc, err := time.LoadLocation(cfg.Timezone); err == nil {
+			cfg.location = loc
+		} else {
+			cfg.location = time.UTC
+		}
+	}
+	return cfg.location
+}
+
+// FeatureEnabled mengecek feature toggle; fitur yang tidak terdaftar dianggap nonaktif
+func (cfg *Config) FeatureEnabled(name string) bool {
+	return cfg.Features[name]
+}
+
+// IsProduction mengecek apakah aplikasi berjalan di profil production
+func (cfg *Config) IsProduction() bool {
+	return cfg.Env == EnvProduction
+}
+
+// splitList memecah daftar yang dipisah koma dan membuang item kosong
+func splitList(v string) []string {
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/audit"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditController menampilkan audit log untuk admin
+type AuditController struct {
+	Audit repository.AuditRepository
+}
+
+// actorID mengembalikan ID user yang sedang login, dicatat sebagai deleted_by dan pelaku di audit log
+func actorID(c *gin.Context) *primitive.ObjectID {
+	id, err := primitive.ObjectIDFromHex(c.GetString("user_id"))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// auditContext adalah context dasar untuk handler yang mengubah data: membawa
+// user, IP dan request ID agar perubahan tercatat di audit log beserta pelakunya
+func auditContext(c *gin.Context) context.Context {
+	return audit.WithActor(context.Background(), audit.Actor{
+		UserID:    actorID(c),
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// auditListSpec adalah field yang boleh dipakai di parameter sort GET /audit
+var auditListSpec = listSpec{
+	sorts: map[string]string{
+		"id":         "_id",
+		"created_at": "created_at",
+	},
+	defaultSort: "-created_at",
+}
+
+// GetAuditLog menampilkan audit log per halaman.
+// Filter: actor_id, entity, entity_id, action, from, to (YYYY-MM-DD).
+func (ac *AuditController) GetAuditLog(c *gin.Context) {
+	var query dto.AuditListQuery
+	opts, ok := bindList(c, auditListSpec, &query)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := ac.Audit.ListPage(ctx, query.ToFilter(), opts)
+	if err != nil {
+		respondListError(c, err, "Failed to fetch audit log")
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
+}
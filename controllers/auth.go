@@ -2,11 +2,20 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/config"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
 	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
 	"github.com/organisasi/tubesbackend/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,27 +23,25 @@ import (
 )
 
 type AuthController struct {
-	DB *mongo.Database
+	DB     *mongo.Database
+	Mailer utils.Mailer
+	Config *config.Config
+	Audit  repository.AuditRepository // Mencatat perubahan data user oleh admin
 }
 
 // Register: Setiap user baru akan memiliki role "user" dan status "inactive"
 func (ctrl *AuthController) Register(c *gin.Context) {
-	var input models.User
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-		return
-	}
-
-	// Validasi semua kolom harus diisi
-	if input.Username == "" || input.Email == "" || input.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username, email, dan password harus diisi"})
+	// Password pada models.User tidak ikut JSON, jadi input dibaca lewat struct terpisah
+	var body dto.RegisterRequest
+	if !bindJSON(c, &body) {
 		return
 	}
+	input := models.User{Username: body.Username, Email: body.Email, Password: body.Password}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(input.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		c.Error(apperror.Internal("Failed to hash password", nil))
 		return
 	}
 	input.Password = hashedPassword
@@ -44,9 +51,9 @@ func (ctrl *AuthController) Register(c *gin.Context) {
 	input.Status = "inactive"
 	input.CreatedAt = time.Now()
 
-	// Check if username or email exists
+	// Cek lebih dulu agar pesan error jelas; index unik tetap menjaga jika dua request datang bersamaan
 	userCollection := ctrl.DB.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
 	filter := bson.M{"$or": []bson.M{
@@ -56,34 +63,42 @@ func (ctrl *AuthController) Register(c *gin.Context) {
 
 	count, err := userCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Terjadi kesalahan saat memeriksa username atau email"})
+		c.Error(apperror.Internal("Failed to check username or email", nil))
 		return
 	}
 
 	if count > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Username atau email sudah digunakan"})
+		c.Error(apperror.Conflict("Username or email is already used"))
 		return
 	}
 
 	// Insert user ke database
-	_, err = userCollection.InsertOne(ctx, input)
+	result, err := userCollection.InsertOne(ctx, input)
+	if mongo.IsDuplicateKeyError(err) {
+		c.Error(apperror.Conflict("Username or email is already used"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register"})
+		c.Error(apperror.Internal("Failed to register", nil))
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, waiting for admin approval"})
+	// Kirim link verifikasi email; kegagalan kirim email tidak membatalkan registrasi
+	if userID, ok := result.InsertedID.(primitive.ObjectID); ok {
+		input.ID = userID
+		repository.RecordAudit(ctx, ctrl.Audit, models.AuditActionCreate, "user", userID, nil, input)
+		if err := ctrl.sendVerificationEmail(ctx, userID, input.Username, input.Email); err != nil {
+			log.Printf("Failed to send verification email to %s: %v", input.Email, err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(c, "User registered successfully, waiting for admin approval")})
 }
 
 // Login: Autentikasi user dengan username dan password
 func (ctrl *AuthController) Login(c *gin.Context) {
-	var input struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var input dto.LoginRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
@@ -91,138 +106,195 @@ func (ctrl *AuthController) Login(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Tolak lebih awal jika IP sedang dikunci, sebelum ada perbandingan bcrypt
+	clientIP := c.ClientIP()
+	remaining, err := ctrl.ipLockedFor(ctx, clientIP)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to process login", nil))
+		return
+	}
+	if remaining > 0 {
+		respondLocked(c, remaining)
+		return
+	}
+
 	// Cari user berdasarkan username
 	var user models.User
-	err := userCollection.FindOne(ctx, bson.M{"username": input.Username}).Decode(&user)
+	err = userCollection.FindOne(ctx, bson.M{"username": input.Username, "deleted_at": nil}).Decode(&user)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		if err := ctrl.recordIPFailure(ctx, clientIP); err != nil {
+			log.Printf("Failed to record login failure for %s: %v", clientIP, err)
+		}
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid username or password"))
+		return
+	}
+
+	// Akun yang sedang dikunci tidak boleh mencoba password
+	if remaining := lockRemaining(user.LockedUntil); remaining > 0 {
+		respondLocked(c, remaining)
 		return
 	}
 
 	// Cek status aktif
 	if user.Status != "active" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "User is not active"})
+		c.Error(apperror.New(http.StatusForbidden, apperror.CodeAccountInactive, "User is not active"))
 		return
 	}
 
 	// Verifikasi password
 	if !utils.CheckPasswordHash(input.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		if err := ctrl.recordUserFailure(ctx, user.ID, clientIP); err != nil {
+			log.Printf("Failed to record login failure for user %s: %v", user.ID.Hex(), err)
+		}
+		if err := ctrl.recordIPFailure(ctx, clientIP); err != nil {
+			log.Printf("Failed to record login failure for %s: %v", clientIP, err)
+		}
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid username or password"))
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID.Hex(), user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	// Jika 2FA aktif, access token baru diberikan setelah kode TOTP diverifikasi.
+	// Counter kegagalan baru direset setelah faktor kedua lolos.
+	if user.TOTPEnabled {
+		respondWithChallenge(c, user, challengePurposeLogin)
 		return
 	}
 
-	// Simpan token di cookie
-	c.SetCookie("auth_token", token, 3600, "/", "", false, true) 
+	// Login berhasil, reset counter kegagalan akun
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if _, err := resetUserFailures(ctx, ctrl.DB, user.ID); err != nil {
+			log.Printf("Failed to reset login failures for user %s: %v", user.ID.Hex(), err)
+		}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
-		"role":    user.Role, // Pastikan ini ada!
-	})
+	if ctrl.twoFactorRequired(user.Role) {
+		respondWithChallenge(c, user, challengePurposeSetup)
+		return
+	}
 
+	// Buat sesi baru beserta access token dan refresh token
+	ctrl.respondWithSession(c, ctx, user, nil)
 }
 
-// Update status user (aktif/inaktif) oleh admin
+// Update status user (aktif/inaktif) oleh admin.
+// Akses dibatasi permission users:update di routes.
 func (ctrl *AuthController) UpdateUserStatus(c *gin.Context) {
 	userID := c.Param("id")
 
 	// Konversi userID ke ObjectID MongoDB
 	objID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		c.Error(apperror.BadRequest("Invalid user ID format"))
 		return
 	}
 
-	var input struct {
-		Status string `json:"status" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	// Status hanya bisa "active" atau "inactive"
+	var input dto.UpdateUserStatusRequest
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	// Validasi status hanya bisa "active" atau "inactive"
-	if input.Status != "active" && input.Status != "inactive" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status value"})
-		return
-	}
-
-	userCollection := ctrl.DB.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	// Ambil user yang sedang melakukan request dari context
-	authUser, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	loggedInUser, ok := authUser.(models.User)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user data"})
-		return
-	}
-
-	// Hanya admin yang boleh mengubah status user lain
-	if loggedInUser.Role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
-
 	// Update status user
 	update := bson.M{"$set": bson.M{"status": input.Status}}
-	result, err := userCollection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate, bson.M{"_id": objID, "deleted_at": nil}, update)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status"})
+		c.Error(apperror.Internal("Failed to update user status", nil))
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
+	// User yang dinonaktifkan langsung kehilangan semua sesinya
+	if input.Status == "inactive" {
+		if err := revokeUserSessions(ctx, ctrl.DB, objID); err != nil {
+			c.Error(apperror.Internal("Failed to revoke user sessions", nil))
+			return
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User status updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User status updated successfully")})
 }
 
-// Mendapatkan daftar semua user tanpa filter status
+// userListSpec adalah field yang boleh dipakai di parameter sort GET /auth/users
+var userListSpec = listSpec{
+	sorts: map[string]string{
+		"username":   "username",
+		"email":      "email",
+		"role":       "role",
+		"status":     "status",
+		"created_at": "created_at",
+	},
+	defaultSort: "-created_at",
+}
+
+// Mendapatkan daftar user per halaman dengan filter opsional:
+// role, status, created_from/created_to (YYYY-MM-DD), q (username/email),
+// locked=true dan deleted=true (hanya yang dihapus).
 func (ctrl *AuthController) GetAllUsers(c *gin.Context) {
-	userCollection := ctrl.DB.Collection("users")
+	var query dto.UserListQuery
+	opts, ok := bindList(c, userListSpec, &query)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Query semua user dari database
-	cursor, err := userCollection.Find(ctx, bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
-		return
+	filter := bson.M{"deleted_at": nil}
+	if query.Deleted == "true" {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	}
+	if query.Role != "" {
+		filter["role"] = query.Role
+	}
+	if query.Status != "" {
+		filter["status"] = query.Status
 	}
-	defer cursor.Close(ctx)
 
-	var users []models.User
-	for cursor.Next(ctx) {
-		var user models.User
-		if err := cursor.Decode(&user); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error decoding user data"})
-			return
-		}
-		users = append(users, user)
+	// ?locked=true hanya menampilkan akun yang sedang dikunci karena login gagal
+	if query.Locked == "true" {
+		filter["locked_until"] = bson.M{"$gt": time.Now()}
+	}
+
+	createdAt := bson.M{}
+	if query.CreatedFrom != "" {
+		fromDate, _ := time.Parse("2006-01-02", query.CreatedFrom)
+		createdAt["$gte"] = fromDate
+	}
+	if query.CreatedTo != "" {
+		toDate, _ := time.Parse("2006-01-02", query.CreatedTo)
+		createdAt["$lt"] = toDate.AddDate(0, 0, 1) // Inklusif sampai akhir hari
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	if q := strings.TrimSpace(query.Q); q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		filter["$or"] = []bson.M{{"username": pattern}, {"email": pattern}}
 	}
 
-	// Jika tidak ada user ditemukan
-	if len(users) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No users found"})
+	page, err := repository.FindPage[models.User](ctx, ctrl.DB.Collection("users"), filter, opts)
+	if err != nil {
+		respondListError(c, err, "Failed to fetch users")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
+}
+
+// findUserByHex mengambil user berdasarkan ID dalam bentuk string hex
+func findUserByHex(ctx context.Context, db *mongo.Database, id string) (models.User, error) {
+	var user models.User
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return user, err
+	}
+	err = db.Collection("users").FindOne(ctx, bson.M{"_id": objID, "deleted_at": nil}).Decode(&user)
+	return user, err
 }
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+)
+
+// EnrollSiswa mendaftarkan siswa ke kursus dan membuat seluruh jadwal tagihannya
+// sesuai rencana penagihan kursus. Tagihan siklus pertama langsung diterbitkan.
+func (ctrl *TagihanController) EnrollSiswa(c *gin.Context) {
+	var req dto.EnrollRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	schedule, issued, err := ctrl.Billing.Enroll(ctx, req.ToInput())
+	if err != nil {
+		respondServiceError(c, err, "Failed to enroll siswa")
+		return
+	}
+
+	schedule.StatusLabel = i18n.StatusLabel(c, "billing_schedule", schedule.Status)
+	labelTagihan(c, issued)
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  i18n.T(c, "Siswa enrolled successfully"),
+		"schedule": schedule,
+		"tagihan":  issued,
+	})
+}
+
+// GetBillingSchedules menampilkan jadwal tagihan. Filter: siswa_id, course_id, status.
+func (ctrl *TagihanController) GetBillingSchedules(c *gin.Context) {
+	var query dto.BillingScheduleListQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedules, err := ctrl.Schedules.List(ctx, query.ToFilter())
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch billing schedules", err))
+		return
+	}
+
+	labelBillingSchedules(c, schedules)
+	c.JSON(http.StatusOK, schedules)
+}
+
+// GetBillingScheduleByID menampilkan satu jadwal tagihan beserta semua siklusnya
+func (ctrl *TagihanController) GetBillingScheduleByID(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid billing schedule ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schedule, err := ctrl.Billing.Schedule(ctx, objID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to fetch billing schedules")
+		return
+	}
+
+	schedule.StatusLabel = i18n.StatusLabel(c, "billing_schedule", schedule.Status)
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CancelBillingSchedule menghentikan penerbitan tagihan berikutnya dari sebuah jadwal
+func (ctrl *TagihanController) CancelBillingSchedule(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid billing schedule ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	schedule, err := ctrl.Billing.CancelSchedule(ctx, objID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to cancel billing schedule")
+		return
+	}
+
+	schedule.StatusLabel = i18n.StatusLabel(c, "billing_schedule", schedule.Status)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  i18n.T(c, "Billing schedule cancelled successfully"),
+		"schedule": schedule,
+	})
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/validation"
+)
+
+// bindJSON membaca body JSON ke dst dan menjalankan aturan di tag binding.
+// Jika gagal, semua field yang salah dikirim di details.fields lalu fungsi
+// mengembalikan false sehingga handler cukup return.
+func bindJSON(c *gin.Context, dst interface{}) bool {
+	err := c.ShouldBindJSON(dst)
+	if err == nil {
+		return true
+	}
+
+	fields := validation.Fields(err)
+	if fields == nil {
+		c.Error(apperror.BadRequest("Invalid input"))
+		return false
+	}
+	respondInvalidFields(c, fields...)
+	return false
+}
+
+// bindQuery sama dengan bindJSON tetapi membaca query string
+func bindQuery(c *gin.Context, dst interface{}) bool {
+	err := c.ShouldBindQuery(dst)
+	if err == nil {
+		return true
+	}
+
+	fields := validation.Fields(err)
+	if fields == nil {
+		c.Error(apperror.BadRequest("Invalid input"))
+		return false
+	}
+	respondInvalidFields(c, fields...)
+	return false
+}
+
+// respondInvalidFields mengirim error validasi untuk field tertentu. Message pada
+// FieldError berisi key katalog dan diterjemahkan di sini.
+func respondInvalidFields(c *gin.Context, fields ...validation.FieldError) {
+	for i := range fields {
+		fields[i].Message = strings.ReplaceAll(i18n.T(c, fields[i].Message), "{param}", fields[i].Param)
+	}
+	c.Error(apperror.Validation("Some fields are invalid").WithDetails(gin.H{"fields": fields}))
+}
@@ -2,233 +2,186 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/organisasi/tubesbackend/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // CourseController mengelola endpoint kursus
 type CourseController struct {
-	DB *mongo.Database
+	Course repository.CourseRepository
 }
 
 // NewCourseController membuat instance CourseController
-func NewCourseController(db *mongo.Database) *CourseController {
-	return &CourseController{DB: db}
+func NewCourseController(courses repository.CourseRepository) *CourseController {
+	return &CourseController{Course: courses}
 }
 
 // CreateCourse membuat course baru
 func (cc *CourseController) CreateCourse(c *gin.Context) {
-	var course struct {
-		Name        string  `json:"name"`
-		Duration    int     `json:"duration"`
-		Cost        float64 `json:"cost"`
-		Description string  `json:"description"`
-		Schedule    string  `json:"schedule"` // Field Schedule ditambahkan
-	}
-
 	// Validasi input JSON
-	if err := c.ShouldBindJSON(&course); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
-		return
-	}
-
-	// Validasi field yang wajib diisi
-	if course.Name == "" || course.Schedule == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and Schedule are required"})
+	var req dto.CourseRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
 	// Create a new Course struct based on input
-	newCourse := models.Course{
-		ID:          primitive.NewObjectID(),
-		Name:        course.Name,
-		Duration:    course.Duration,
-		Cost:        course.Cost,
-		Description: course.Description,
-		Schedule:    course.Schedule,
-		CreatedAt:   primitive.NewDateTimeFromTime(time.Now()), // Set creation time
-	}
+	newCourse := req.ToModel()
+	newCourse.ID = primitive.NewObjectID()
+	newCourse.CreatedAt = primitive.NewDateTimeFromTime(time.Now()) // Set creation time
 
 	// Insert the new course into the MongoDB collection
-	collection := cc.DB.Collection("courses")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	_, err := collection.InsertOne(ctx, newCourse)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create course"})
+	if err := cc.Course.Create(ctx, &newCourse); err != nil {
+		c.Error(apperror.Internal("Failed to create course", nil))
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Course created successfully"})
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(c, "Course created successfully")})
 }
 
+// courseListSpec adalah field yang boleh dipakai di parameter sort GET /courses
+var courseListSpec = listSpec{
+	sorts: map[string]string{
+		"id":        "_id",
+		"name":      "name",
+		"cost":      "cost",
+		"duration":  "duration",
+		"createdAt": "createdAt",
+	},
+	defaultSort: "name",
+}
+
+// GetCourses mendapatkan daftar kursus per halaman, bisa dicari dengan ?q=
 func (cc *CourseController) GetCourses(c *gin.Context) {
-	collection := cc.DB.Collection("courses")
+	var query dto.CourseListQuery
+	opts, ok := bindList(c, courseListSpec, &query)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := collection.Find(ctx, bson.M{})
+	page, err := cc.Course.ListPage(ctx, query.ToFilter(), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch courses"})
-		return
-	}
-	defer cursor.Close(ctx)
-
-	var courses []bson.M
-	if err = cursor.All(ctx, &courses); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse courses"})
+		respondListError(c, err, "Failed to fetch courses")
 		return
 	}
 
-	c.JSON(http.StatusOK, courses)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 
 // FindCourseById mendapatkan kursus berdasarkan ID
 func (cc *CourseController) FindCourseById(c *gin.Context) {
 	id := c.Param("id")
 
-	collection := cc.DB.Collection("courses")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var course bson.M
-	var err error
-
-	// Coba cari berdasarkan custom field "id" (string)
-	err = collection.FindOne(ctx, bson.M{"id": id}).Decode(&course)
+	// Cari berdasarkan custom field "id" (string), lalu "_id" (ObjectID)
+	course, err := cc.Course.FindByRef(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Course not found"))
+		return
+	}
 	if err != nil {
-		// Jika gagal, coba cari berdasarkan "_id" (ObjectID)
-		objID, objErr := primitive.ObjectIDFromHex(id)
-		if objErr == nil {
-			err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&course)
-		}
-
-		// Jika tetap tidak ditemukan, kembalikan 404
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-			return
-		}
+		c.Error(apperror.Internal("Failed to fetch course", nil))
+		return
 	}
 
 	c.JSON(http.StatusOK, course)
 }
 
 func (cc *CourseController) UpdateCourseById(c *gin.Context) {
-	c.Header("Access-Control-Allow-Origin", "http://127.0.0.1:5504")
-	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	// Ambil ID dari parameter URL
 	id := c.Param("id")
 
 	// Pastikan ID valid untuk ObjectID
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
-		return
-	}
-
-	// Buat struktur untuk menerima data yang diupdate
-	var updatedCourse struct {
-		Name        string  `json:"name"`
-		Duration    int     `json:"duration"`
-		Cost        float64 `json:"cost"`
-		Description string  `json:"description"`
-		Schedule    string  `json:"schedule"` // Kolom schedule yang bisa diupdate
-	}
-
-	// Bind data dari JSON request body ke struktur updatedCourse
-	if err := c.ShouldBindJSON(&updatedCourse); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperror.BadRequest("Invalid ID format"))
 		return
 	}
 
-	// Validasi bahwa schedule tidak kosong
-	if updatedCourse.Schedule == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Schedule is required"})
+	// Bind dan validasi data dari JSON request body
+	var req dto.CourseRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Ambil koleksi "courses" dari database
-	collection := cc.DB.Collection("courses")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	// Membuat data yang akan diupdate
-	update := bson.M{
-		"$set": bson.M{
-			"name":        updatedCourse.Name,
-			"duration":    updatedCourse.Duration,
-			"cost":        updatedCourse.Cost,
-			"description": updatedCourse.Description,
-			"schedule":    updatedCourse.Schedule, // Update schedule
-		},
-	}
-
 	// Melakukan update pada dokumen yang sesuai dengan ObjectID
-	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update course"})
-		return
-	}
+	err = cc.Course.Update(ctx, objID, req.ToModel())
 
 	// Cek apakah kursus ditemukan
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Course not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to update course", nil))
 		return
 	}
 
 	// Jika berhasil, kirimkan respon sukses
-	c.JSON(http.StatusOK, gin.H{"message": "Course updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Course updated successfully")})
 }
 
-// DeleteCourse menghapus data kursus berdasarkan ID
+// DeleteCourse memindahkan kursus ke trash; bisa dipulihkan lewat RestoreCourse
 func (cc *CourseController) DeleteCourse(c *gin.Context) {
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		c.Error(apperror.BadRequest("Invalid ID format"))
 		return
 	}
 
-	collection := cc.DB.Collection("courses")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	result, err := collection.DeleteOne(ctx, bson.M{"_id": objID})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete course"})
+	err = cc.Course.Delete(ctx, objID, actorID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Course not found"))
 		return
 	}
-
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to delete course", nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Course deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Course deleted successfully")})
+}
+
+// GetCourseTrash menampilkan kursus yang sudah dihapus
+func (cc *CourseController) GetCourseTrash(c *gin.Context) {
+	listTrash(c, cc.Course, nil)
+}
+
+// RestoreCourse memulihkan kursus dari trash
+func (cc *CourseController) RestoreCourse(c *gin.Context) {
+	restoreFromTrash(c, cc.Course, "Deleted course not found", "Course restored successfully")
 }
 
 func (cc *CourseController) GetNextCourseId(c *gin.Context) {
-	collection := cc.DB.Collection("courses")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	count, err := collection.CountDocuments(ctx, bson.M{})
+	count, err := cc.Course.Count(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count courses"})
+		c.Error(apperror.Internal("Failed to count courses", nil))
 		return
 	}
 
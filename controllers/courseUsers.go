@@ -3,95 +3,72 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/repository"
 )
 
-// Struct Registration untuk mapping data JSON dan MongoDB
-type Registration struct {
-	CourseId    string   `json:"courseId" bson:"courseId"`
-	StudentName string   `json:"studentName" bson:"studentName"`
-	Email       string   `json:"email" bson:"email"`
-	Phonenumber string   `json:"phonenumber" bson:"phonenumber"`
-	Status      string   `json:"status" bson:"status"`
-	Courses     []string `json:"courses" bson:"courses"`
-}
-
 // Struct CourseUsers menangani pendaftaran kursus
 type CourseUsers struct {
-	DB *mongo.Database
+	Registration repository.RegistrationRepository
 }
 
 // Constructor NewCourseUsers
-func NewCourseUsers(db *mongo.Database) *CourseUsers {
-	return &CourseUsers{DB: db}
+func NewCourseUsers(registrations repository.RegistrationRepository) *CourseUsers {
+	return &CourseUsers{Registration: registrations}
 }
 
 // RegisterCourse untuk mendaftarkan kursus
 func (cu *CourseUsers) RegisterCourse(c *gin.Context) {
-	var registration Registration
-
-	// Bind JSON ke struct
-	if err := c.ShouldBindJSON(&registration); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Bind JSON ke struct dan validasi semua field wajib
+	var req dto.CourseRegistrationRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Validasi semua field wajib diisi
-	if registration.CourseId == "" || registration.StudentName == "" || registration.Email == "" ||
-		registration.Phonenumber == "" || registration.Status == "" || len(registration.Courses) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
-		return
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	// Menyimpan ke MongoDB
-	collection := cu.DB.Collection("course_registrations")
-	_, err := collection.InsertOne(context.TODO(), registration)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := cu.Registration.Create(ctx, req.ToModel()); err != nil {
+		c.Error(apperror.Internal("Internal server error", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Pendaftaran kursus berhasil"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Course registration successful")})
 }
 
-// GetAllCourseRegistrations untuk mengambil semua pendaftaran kursus
-func (cu *CourseUsers) GetAllCourseRegistrations(c *gin.Context) {
-	collection := cu.DB.Collection("course_registrations")
+// registrationListSpec adalah field yang boleh dipakai di parameter sort GET /courses/registrations.
+// Tanpa sort, pendaftaran diurutkan sesuai waktu masuk.
+var registrationListSpec = listSpec{
+	sorts: map[string]string{
+		"studentName": "studentName",
+		"email":       "email",
+		"courseId":    "courseId",
+		"status":      "status",
+	},
+}
 
-	cursor, err := collection.Find(context.TODO(), bson.M{}) // Mengambil semua data tanpa filter
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// GetAllCourseRegistrations untuk mengambil pendaftaran kursus per halaman, filter ?course= dan ?status=
+func (cu *CourseUsers) GetAllCourseRegistrations(c *gin.Context) {
+	var query dto.RegistrationListQuery
+	opts, ok := bindList(c, registrationListSpec, &query)
+	if !ok {
 		return
 	}
-	defer cursor.Close(context.TODO())
 
-	var registrations []struct {
-		CourseId    string   `json:"courseId" bson:"courseId"`
-		StudentName string   `json:"studentName" bson:"studentName"`
-		Email       string   `json:"email" bson:"email"`
-		Phonenumber string   `json:"phonenumber" bson:"phonenumber"`
-		Status      string   `json:"status" bson:"status"`
-		Courses     []string `json:"courses" bson:"courses"`
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	for cursor.Next(context.TODO()) {
-		var registration struct {
-			CourseId    string   `json:"courseId" bson:"courseId"`
-			StudentName string   `json:"studentName" bson:"studentName"`
-			Email       string   `json:"email" bson:"email"`
-			Phonenumber string   `json:"phonenumber" bson:"phonenumber"`
-			Status      string   `json:"status" bson:"status"`
-			Courses     []string `json:"courses" bson:"courses"`
-		}
-		if err := cursor.Decode(&registration); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		registrations = append(registrations, registration)
+	page, err := cu.Registration.ListPage(ctx, query.ToFilter(), opts)
+	if err != nil {
+		respondListError(c, err, "Internal server error")
+		return
 	}
 
-	c.JSON(http.StatusOK, registrations)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/invoice"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/service"
+)
+
+// DocumentController melayani verifikasi invoice dan kuitansi lewat QR code
+type DocumentController struct {
+	Documents *service.DocumentService
+}
+
+// VerifyDocument menampilkan data dokumen dari kode di QR code. Route ini
+// publik agar orang tua atau pihak lain bisa mengecek keaslian dokumen cetak.
+func (dc *DocumentController) VerifyDocument(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := dc.Documents.Verify(ctx, c.Param("code"))
+	if err != nil {
+		respondServiceError(c, err, "Failed to verify document")
+		return
+	}
+
+	result.StatusLabel = i18n.StatusLabel(c, statusKind(result.Entity), result.Status)
+	c.JSON(http.StatusOK, gin.H{"valid": true, "document": result})
+}
+
+// documentPDF menyusun invoice atau kuitansi sebuah entitas lalu mengirimnya
+// sebagai PDF. build adalah DocumentService.TagihanDocument atau TransaksiDocument.
+func documentPDF(c *gin.Context, docs *service.DocumentService, kind, entity string, build func(context.Context, string, primitive.ObjectID) (invoice.Document, error)) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	doc, err := build(ctx, kind, objID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to generate document")
+		return
+	}
+	sendDocumentPDF(c, docs, entity, doc)
+}
+
+// sendDocumentPDF merender dokumen yang sudah disusun lalu mengirimnya sebagai PDF
+func sendDocumentPDF(c *gin.Context, docs *service.DocumentService, entity string, doc invoice.Document) {
+	doc.Status = i18n.StatusLabel(c, statusKind(entity), doc.Status)
+	pdf, err := docs.Render(doc, func(key string) string { return i18n.T(c, key) })
+	if err != nil {
+		c.Error(apperror.Internal("Failed to generate document", err))
+		return
+	}
+
+	// Nomor dokumen berisi "/", jadi diganti agar aman sebagai nama file
+	filename := strings.ReplaceAll(doc.Number, "/", "-") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// statusKind mengembalikan jenis label status (lihat i18n.StatusLabel) untuk entitas dokumen
+func statusKind(entity string) string {
+	if entity == models.DocumentEntityTransaksiSiswa {
+		return "transaksi"
+	}
+	return "tagihan"
+}
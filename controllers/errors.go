@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/service"
+)
+
+// respondServiceError meneruskan error domain dari service ke middleware ErrorHandler.
+// Error lain dianggap kegagalan internal dan dibalas dengan pesan fallback.
+func respondServiceError(c *gin.Context, err error, fallback string) {
+	var notFound *service.NotFoundError
+	var invalid *service.ValidationError
+	var conflict *service.ConflictError
+
+	if errors.As(err, &notFound) || errors.As(err, &invalid) || errors.As(err, &conflict) {
+		c.Error(err)
+		return
+	}
+	c.Error(apperror.Internal(fallback, err))
+}
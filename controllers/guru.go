@@ -2,106 +2,103 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
+
+	"github.com/organisasi/tubesbackend/i18n"
+
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
 
-	"github.com/organisasi/tubesbackend/models"
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type GuruController struct {
-	DB *mongo.Database
+	Guru repository.GuruRepository
 }
 
-// GetAllGuru retrieves all Guru records.
+// guruListSpec lists the fields allowed in the sort parameter of GET /gurus.
+var guruListSpec = listSpec{
+	sorts: map[string]string{
+		"id":             "_id",
+		"fullname":       "fullname",
+		"email":          "email",
+		"school_subject": "school_subject",
+		"status":         "status",
+	},
+	defaultSort: "fullname",
+}
+
+// GetAllGuru retrieves one page of Guru records, filtered by ?status=, ?school_subject= and ?q=.
 func (ctrl *GuruController) GetAllGuru(c *gin.Context) {
-	var gurus []models.Guru
-	cursor, err := ctrl.DB.Collection("gurus").Find(context.TODO(), bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch data"})
+	var query dto.GuruListQuery
+	opts, ok := bindList(c, guruListSpec, &query)
+	if !ok {
 		return
 	}
-	defer cursor.Close(context.TODO())
 
-	for cursor.Next(context.TODO()) {
-		var guru models.Guru
-		if err := cursor.Decode(&guru); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error decoding data"})
-			return
-		}
-		gurus = append(gurus, guru)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := ctrl.Guru.ListPage(ctx, query.ToFilter(), opts)
+	if err != nil {
+		respondListError(c, err, "Failed to fetch data")
+		return
 	}
 
-	c.JSON(http.StatusOK, gurus)
+	labelGuru(c, page.Items)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 // GetGuruByStatus retrieves Guru records based on their status (e.g., active).
 func (ctrl *GuruController) GetGuruByStatus(c *gin.Context) {
 	status := c.DefaultQuery("status", "") // Mendapatkan nilai status dari query parameter, default ke "" jika tidak ada
 
 	if status == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Status parameter is required"})
+		c.Error(apperror.Validation("Status parameter is required"))
 		return
 	}
 
-	var gurus []models.Guru
-	cursor, err := ctrl.DB.Collection("gurus").Find(context.TODO(), bson.M{"status": status})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gurus, err := ctrl.Guru.List(ctx, repository.GuruFilter{Status: models.NormalizeStatus(status)})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch data"})
+		c.Error(apperror.Internal("Failed to fetch data", nil))
 		return
 	}
-	defer cursor.Close(context.TODO())
-
-	for cursor.Next(context.TODO()) {
-		var guru models.Guru
-		if err := cursor.Decode(&guru); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error decoding data"})
-			return
-		}
-		gurus = append(gurus, guru)
-	}
 
 	if len(gurus) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "No gurus found with the given status"})
+		c.JSON(http.StatusNotFound, gin.H{"message": i18n.T(c, "No gurus found with the given status")})
 		return
 	}
 
+	labelGuru(c, gurus)
 	c.JSON(http.StatusOK, gurus)
 }
 
 // CreateGuru creates a new Guru record.
 func (ctrl *GuruController) CreateGuru(c *gin.Context) {
-	var guruInput struct {
-		FullName      string `json:"fullname"`
-		Address       string `json:"address"`
-		PhoneNumber   string `json:"phonenumber"`
-		Email         string `json:"email"`
-		SchoolSubject string `json:"school_subject"`
-		Status        string `json:"status"`
-	}
-
-	if err := c.ShouldBindJSON(&guruInput); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var req dto.GuruRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	guru := models.Guru{
-		ID:            primitive.NewObjectID(),
-		FullName:      guruInput.FullName,
-		Address:       guruInput.Address,
-		PhoneNumber:   guruInput.PhoneNumber,
-		Email:         guruInput.Email,
-		SchoolSubject: guruInput.SchoolSubject,
-		Status:        guruInput.Status,
-	}
+	guru := req.ToModel()
+	guru.ID = primitive.NewObjectID()
 
-	_, err := ctrl.DB.Collection("gurus").InsertOne(context.TODO(), guru)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Guru"})
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	if err := ctrl.Guru.Create(ctx, &guru); err != nil {
+		c.Error(apperror.Internal("Failed to create Guru", nil))
 		return
 	}
 
+	guru.StatusLabel = i18n.StatusLabel(c, "guru", guru.Status)
 	c.JSON(http.StatusCreated, guru)
 }
 
@@ -110,17 +107,24 @@ func (ctrl *GuruController) GetGuruByID(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	var guru models.Guru
-	err = ctrl.DB.Collection("gurus").FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&guru)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	guru, err := ctrl.Guru.Get(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Guru not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Guru not found"})
+		c.Error(apperror.Internal("Failed to fetch data", nil))
 		return
 	}
 
+	guru.StatusLabel = i18n.StatusLabel(c, "guru", guru.Status)
 	c.JSON(http.StatusOK, guru)
 }
 
@@ -129,56 +133,63 @@ func (ctrl *GuruController) UpdateGuru(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	var updateData struct {
-		FullName      string `json:"fullname"`
-		Address       string `json:"address"`
-		PhoneNumber   string `json:"phonenumber"`
-		Email         string `json:"email"`
-		SchoolSubject string `json:"school_subject"`
-		Status        string `json:"status"`
-	}
-
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var req dto.GuruRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	update := req.ToModel()
 
-	update := bson.M{
-		"fullname":       updateData.FullName,
-		"address":        updateData.Address,
-		"phonenumber":    updateData.PhoneNumber,
-		"email":          updateData.Email,
-		"school_subject": updateData.SchoolSubject,
-		"status":         updateData.Status,
-	}
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
-	_, err = ctrl.DB.Collection("gurus").UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": update})
+	err = ctrl.Guru.Update(ctx, objID, update)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Guru not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update Guru"})
+		c.Error(apperror.Internal("Failed to update Guru", nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Guru updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Guru updated successfully")})
 }
 
-// DeleteGuru deletes a Guru record by ID.
+// DeleteGuru moves a Guru record to the trash. It can be restored with RestoreGuru.
 func (ctrl *GuruController) DeleteGuru(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	_, err = ctrl.DB.Collection("gurus").DeleteOne(context.TODO(), bson.M{"_id": objID})
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.Guru.Delete(ctx, objID, actorID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Guru not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete Guru"})
+		c.Error(apperror.Internal("Failed to delete Guru", nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Guru deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Guru deleted successfully")})
+}
+
+// GetGuruTrash lists deleted Guru records.
+func (ctrl *GuruController) GetGuruTrash(c *gin.Context) {
+	listTrash(c, ctrl.Guru, labelGuru)
+}
+
+// RestoreGuru moves a Guru record out of the trash.
+func (ctrl *GuruController) RestoreGuru(c *gin.Context) {
+	restoreFromTrash(c, ctrl.Guru, "Deleted guru not found", "Guru restored successfully")
 }
\ No newline at end of file
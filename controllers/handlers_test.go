@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/controllers"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/middlewares"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/service"
+)
+
+// newTestRouter menyusun route siswa dan tagihan seperti routes.SetupRoutes,
+// tetapi tanpa autentikasi dan memakai repository memory
+func newTestRouter(t *testing.T) (*gin.Engine, *repository.Repositories) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repos := repository.NewMemoryRepositories()
+	billing := service.NewBillingService(repos)
+	enrollment := service.NewEnrollmentService(repos)
+	documents := service.NewDocumentService(repos, service.DocumentOptions{InvoicePrefix: "INV", ReceiptPrefix: "KWT", Location: time.UTC})
+
+	router := gin.New()
+	router.Use(middlewares.RequestID(), i18n.Middleware(), middlewares.ErrorHandler())
+
+	siswaCtrl := controllers.SiswaController{Siswa: repos.Siswa, Transaksi: repos.TransaksiSiswa, Enrollment: enrollment, Documents: documents}
+	siswaRoutes := router.Group("/siswa")
+	siswaRoutes.POST("", siswaCtrl.CreateSiswa)
+	siswaRoutes.GET("", siswaCtrl.GetSiswa)
+	siswaRoutes.GET("/:id", siswaCtrl.GetSiswaByID)
+	siswaRoutes.PUT("/:id", siswaCtrl.UpdateSiswa)
+	siswaRoutes.DELETE("/:id", siswaCtrl.DeleteSiswa)
+	siswaRoutes.GET("/trash", siswaCtrl.GetSiswaTrash)
+	siswaRoutes.POST("/:id/restore", siswaCtrl.RestoreSiswa)
+
+	tagihanCtrl := controllers.TagihanController{Tagihan: repos.Tagihan, Schedules: repos.Billing, Billing: billing, Documents: documents}
+	tagihanRoutes := router.Group("/tagihan")
+	tagihanRoutes.GET("/:id", tagihanCtrl.GetTagihanByID)
+	tagihanRoutes.POST("", tagihanCtrl.CreateTagihan)
+	tagihanRoutes.PUT("/:id", tagihanCtrl.UpdateTagihan)
+	tagihanRoutes.DELETE("/:id", tagihanCtrl.DeleteTagihan)
+	tagihanRoutes.POST("/:id/restore", tagihanCtrl.RestoreTagihan)
+	tagihanRoutes.GET("/:id/invoice.pdf", tagihanCtrl.GetTagihanInvoice)
+	tagihanRoutes.GET("/:id/payments", tagihanCtrl.GetTagihanPayments)
+	tagihanRoutes.POST("/:id/payments", tagihanCtrl.CreateTagihanPayment)
+	tagihanRoutes.GET("/:id/payments/:paymentId/receipt.pdf", tagihanCtrl.GetPaymentReceipt)
+
+	return router, repos
+}
+
+// serve mengirim request ke router dan mengembalikan response-nya. body nil
+// berarti request tanpa body.
+func serve(t *testing.T, router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode membaca body JSON response ke dst
+func decode(t *testing.T, rec *httptest.ResponseRecorder, dst interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), dst); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+}
+
+// errorCode mengembalikan kode error dari body {"error": {code, ...}}
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	decode(t, rec, &body)
+	return body.Error.Code
+}
+
+// expectStatus menghentikan test jika status response tidak sesuai
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int, step string) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("%s: status = %d, want %d; body %s", step, rec.Code, want, rec.Body.String())
+	}
+}
+
+// expectError memastikan response adalah error dengan status dan kode tertentu
+func expectError(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int, wantCode, step string) {
+	t.Helper()
+	expectStatus(t, rec, wantStatus, step)
+	if code := errorCode(t, rec); code != wantCode {
+		t.Errorf("%s: error code = %q, want %q", step, code, wantCode)
+	}
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// HealthController menyediakan endpoint liveness dan readiness untuk orchestrator
+type HealthController struct {
+	DB *mongo.Database
+}
+
+// Healthz: Liveness, hanya memastikan proses masih bisa melayani request
+func (ctrl *HealthController) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz: Readiness, memastikan MongoDB bisa dijangkau sebelum menerima traffic
+func (ctrl *HealthController) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := ctrl.DB.Client().Ping(ctx, readpref.Primary()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "Database unreachable"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
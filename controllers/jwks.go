@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/utils"
+)
+
+// GetJWKS mempublikasikan public key JWT agar service lain bisa memverifikasi token
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": utils.PublicJWKS()})
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+)
+
+// Fungsi di bawah mengisi StatusLabel sesuai bahasa request sebelum data dikirim.
+// Kode status di field Status tidak pernah diubah.
+
+func labelSiswa(c *gin.Context, list []models.Siswa) {
+	for i := range list {
+		list[i].StatusLabel = i18n.StatusLabel(c, "siswa", list[i].Status)
+	}
+}
+
+func labelGuru(c *gin.Context, list []models.Guru) {
+	for i := range list {
+		list[i].StatusLabel = i18n.StatusLabel(c, "guru", list[i].Status)
+	}
+}
+
+func labelTagihan(c *gin.Context, list []models.Tagihan) {
+	for i := range list {
+		list[i].StatusLabel = i18n.StatusLabel(c, "tagihan", list[i].Status)
+	}
+}
+
+func labelTransaksiSiswa(c *gin.Context, list []models.TransaksiSiswa) {
+	for i := range list {
+		list[i].StatusLabel = i18n.StatusLabel(c, "transaksi", list[i].Status)
+	}
+}
+
+func labelBillingSchedules(c *gin.Context, list []models.BillingSchedule) {
+	for i := range list {
+		list[i].StatusLabel = i18n.StatusLabel(c, "billing_schedule", list[i].Status)
+	}
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/validation"
+)
+
+// Batas ukuran halaman untuk semua endpoint daftar
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// listSpec menjelaskan parameter sort yang boleh dipakai sebuah endpoint daftar
+type listSpec struct {
+	// sorts memetakan nama field di parameter sort ke nama field bson
+	sorts map[string]string
+	// defaultSort dipakai jika parameter sort kosong, contoh "-created_at"
+	defaultSort string
+}
+
+// bindList membaca parameter paging (limit, offset, cursor, sort) dan filter
+// endpoint dari query string. filter boleh nil. Semua parameter yang salah
+// dilaporkan sekaligus; jika ada, fungsi mengembalikan false dan handler cukup return.
+//
+// sort berisi daftar field dipisah koma, awalan "-" berarti menurun: sort=-created_at,fullname
+func bindList(c *gin.Context, spec listSpec, filter interface{}) (repository.ListOptions, bool) {
+	opts := repository.ListOptions{Limit: defaultPageLimit, Cursor: c.Query("cursor")}
+	var fields []validation.FieldError
+
+	if filter != nil {
+		if err := c.ShouldBindQuery(filter); err != nil {
+			invalid := validation.Fields(err)
+			if invalid == nil {
+				c.Error(apperror.BadRequest("Invalid input"))
+				return opts, false
+			}
+			fields = append(fields, invalid...)
+		}
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		switch {
+		case err != nil:
+			fields = append(fields, validation.FieldError{Field: "limit", Rule: "type", Param: "int", Message: "validation.type"})
+		case limit < 1:
+			fields = append(fields, validation.FieldError{Field: "limit", Rule: "gte", Param: "1", Message: "validation.gte"})
+		case limit > maxPageLimit:
+			fields = append(fields, validation.FieldError{Field: "limit", Rule: "lte", Param: strconv.Itoa(maxPageLimit), Message: "validation.lte"})
+		default:
+			opts.Limit = limit
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		switch {
+		case err != nil:
+			fields = append(fields, validation.FieldError{Field: "offset", Rule: "type", Param: "int", Message: "validation.type"})
+		case offset < 0:
+			fields = append(fields, validation.FieldError{Field: "offset", Rule: "gte", Param: "0", Message: "validation.gte"})
+		default:
+			opts.Offset = offset
+		}
+	}
+
+	sortParam := c.DefaultQuery("sort", spec.defaultSort)
+	for _, name := range strings.Split(sortParam, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		field, ok := spec.sorts[strings.TrimPrefix(name, "-")]
+		if !ok {
+			fields = append(fields, validation.FieldError{Field: "sort", Rule: "oneof", Param: sortNames(spec), Message: "validation.oneof"})
+			break
+		}
+		opts.Sort = append(opts.Sort, repository.SortField{Field: field, Desc: desc})
+	}
+
+	if len(fields) > 0 {
+		respondInvalidFields(c, fields...)
+		return opts, false
+	}
+	return opts, true
+}
+
+func sortNames(spec listSpec) string {
+	names := make([]string, 0, len(spec.sorts))
+	for name := range spec.sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// respondListError melaporkan error dari ListPage. Cursor yang salah adalah
+// kesalahan input, error lain dilaporkan sebagai error internal dengan pesan fallback.
+func respondListError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		c.Error(err)
+		return
+	}
+	c.Error(apperror.Internal(fallback, err))
+}
+
+// listMeta adalah bagian "meta" dari respons daftar
+type listMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     *int   `json:"offset,omitempty"` // Tidak ada jika halaman diambil dengan cursor
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// listLinks berisi URL relatif ke halaman ini dan halaman sekitarnya
+type listLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// listResponse adalah amplop respons semua endpoint daftar
+type listResponse[T any] struct {
+	Data  []T       `json:"data"`
+	Meta  listMeta  `json:"meta"`
+	Links listLinks `json:"links"`
+}
+
+// newListResponse membungkus satu halaman hasil repository. Link next memakai
+// cursor jika request memakai cursor, selain itu memakai offset.
+func newListResponse[T any](c *gin.Context, page repository.Page[T], opts repository.ListOptions) listResponse[T] {
+	resp := listResponse[T]{
+		Data:  page.Items,
+		Meta:  listMeta{Total: page.Total, Limit: opts.Limit, NextCursor: page.NextCursor},
+		Links: listLinks{Self: c.Request.URL.RequestURI()},
+	}
+
+	if opts.Cursor != "" {
+		if page.NextCursor != "" {
+			resp.Links.Next = pageLink(c, map[string]string{"cursor": page.NextCursor, "offset": ""})
+		}
+		return resp
+	}
+
+	offset := opts.Offset
+	resp.Meta.Offset = &offset
+	if page.NextCursor != "" {
+		resp.Links.Next = pageLink(c, map[string]string{"offset": strconv.Itoa(offset + opts.Limit)})
+	}
+	if offset > 0 {
+		prev := offset - opts.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		resp.Links.Prev = pageLink(c, map[string]string{"offset": strconv.Itoa(prev)})
+	}
+	return resp
+}
+
+// pageLink menyalin URL request dengan parameter yang diganti; nilai kosong menghapus parameter
+func pageLink(c *gin.Context, params map[string]string) string {
+	query := c.Request.URL.Query()
+	for key, value := range params {
+		if value == "" {
+			query.Del(key)
+		} else {
+			query.Set(key, value)
+		}
+	}
+	link := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return link.String()
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// loginThrottle mengatur kapan percobaan login berikutnya boleh dilakukan.
+// Mulai kegagalan ke-BackoffAfter, jeda bertambah dua kali lipat setiap kali
+// gagal (BaseDelay, 2x, 4x, ...). Pada kegagalan ke-LockAfter, percobaan
+// dikunci selama LockDuration. Hitungan direset jika tidak ada kegagalan
+// selama Window.
+type loginThrottle struct {
+	BackoffAfter int
+	LockAfter    int
+	BaseDelay    time.Duration
+	LockDuration time.Duration
+	Window       time.Duration
+}
+
+var (
+	// Per username: dikunci setelah 10 kali gagal
+	userThrottle = loginThrottle{BackoffAfter: 3, LockAfter: 10, BaseDelay: time.Second, LockDuration: 30 * time.Minute, Window: time.Hour}
+	// Per IP: lebih longgar karena satu IP bisa dipakai banyak user (misalnya jaringan sekolah)
+	ipThrottle = loginThrottle{BackoffAfter: 10, LockAfter: 50, BaseDelay: time.Second, LockDuration: 30 * time.Minute, Window: time.Hour}
+)
+
+// delay menghitung lama penguncian setelah sejumlah kegagalan
+func (t loginThrottle) delay(failures int) time.Duration {
+	if failures >= t.LockAfter {
+		return t.LockDuration
+	}
+	if failures < t.BackoffAfter {
+		return 0
+	}
+	d := time.Duration(float64(t.BaseDelay) * math.Pow(2, float64(failures-t.BackoffAfter)))
+	if d > t.LockDuration {
+		return t.LockDuration
+	}
+	return d
+}
+
+// failureUpdate adalah pipeline update atomik yang menaikkan counter kegagalan,
+// atau memulai dari 1 jika kegagalan terakhir sudah di luar window
+func (t loginThrottle) failureUpdate(counterField, lastField string, now time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			counterField: bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$" + lastField, now.Add(-t.Window)}},
+				1,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + counterField, 0}}, 1}},
+			}},
+			lastField: now,
+		}}},
+	}
+}
+
+// recordFailure mencatat kegagalan pada dokumen yang cocok dengan filter lalu
+// mengisi locked_until sesuai jumlah kegagalan
+func (t loginThrottle) recordFailure(ctx context.Context, collection *mongo.Collection, filter bson.M, counterField string, upsert bool) error {
+	now := time.Now()
+
+	var doc bson.M
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(upsert)
+	err := collection.FindOneAndUpdate(ctx, filter, t.failureUpdate(counterField, "last_failed_at", now), opts).Decode(&doc)
+	if err != nil {
+		return err
+	}
+
+	var failures int
+	switch v := doc[counterField].(type) {
+	case int32:
+		failures = int(v)
+	case int64:
+		failures = int(v)
+	}
+	if d := t.delay(failures); d > 0 {
+		_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"locked_until": now.Add(d)}})
+	}
+	return err
+}
+
+// ipLockedFor mengembalikan sisa waktu penguncian untuk IP tertentu (0 jika tidak dikunci)
+func (ctrl *AuthController) ipLockedFor(ctx context.Context, ip string) (time.Duration, error) {
+	var attempt struct {
+		LockedUntil *time.Time `bson:"locked_until"`
+	}
+	err := ctrl.DB.Collection("login_attempts").FindOne(ctx, bson.M{"_id": "ip:" + ip}).Decode(&attempt)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return lockRemaining(attempt.LockedUntil), nil
+}
+
+// recordIPFailure mencatat login gagal dari sebuah IP
+func (ctrl *AuthController) recordIPFailure(ctx context.Context, ip string) error {
+	return ipThrottle.recordFailure(ctx, ctrl.DB.Collection("login_attempts"), bson.M{"_id": "ip:" + ip}, "failures", true)
+}
+
+// recordUserFailure mencatat login gagal untuk sebuah akun beserta IP asalnya,
+// agar kunci IP tersebut ikut dibuka saat akun di-unlock atau password direset
+func (ctrl *AuthController) recordUserFailure(ctx context.Context, userID primitive.ObjectID, ip string) error {
+	users := ctrl.DB.Collection("users")
+	if err := userThrottle.recordFailure(ctx, users, bson.M{"_id": userID}, "failed_logins", false); err != nil {
+		return err
+	}
+
+	// Hanya IP terakhir yang disimpan, cukup untuk menutup satu putaran penguncian
+	_, err := users.UpdateOne(ctx, bson.M{"_id": userID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failed_ips": bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{
+					bson.M{"$filter": bson.M{
+						"input": bson.M{"$ifNull": bson.A{"$failed_ips", bson.A{}}},
+						"cond":  bson.M{"$ne": bson.A{"$$this", ip}},
+					}},
+					bson.A{ip},
+				}},
+				-userThrottle.LockAfter,
+			}},
+		}}},
+	})
+	return err
+}
+
+// resetFailuresUpdate menghapus counter kegagalan dan kunci akun
+var resetFailuresUpdate = bson.M{
+	"$set":   bson.M{"failed_logins": 0},
+	"$unset": bson.M{"locked_until": "", "last_failed_at": "", "failed_ips": ""},
+}
+
+// resetIPFailures menghapus counter kegagalan dan kunci untuk IP yang
+// tercatat pada login gagal seorang user
+func resetIPFailures(ctx context.Context, db *mongo.Database, ips []string) error {
+	if len(ips) == 0 {
+		return nil
+	}
+	ids := make([]string, len(ips))
+	for i, ip := range ips {
+		ids[i] = "ip:" + ip
+	}
+	_, err := db.Collection("login_attempts").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{
+			"$set":   bson.M{"failures": 0},
+			"$unset": bson.M{"locked_until": "", "last_failed_at": ""},
+		},
+	)
+	return err
+}
+
+// resetUserFailures menjalankan resetFailuresUpdate untuk satu user
+func resetUserFailures(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return db.Collection("users").UpdateOne(ctx, bson.M{"_id": userID}, resetFailuresUpdate)
+}
+
+// lockRemaining menghitung sisa waktu kunci
+func lockRemaining(lockedUntil *time.Time) time.Duration {
+	if lockedUntil == nil {
+		return 0
+	}
+	if remaining := time.Until(*lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// respondLocked mengirim 429 beserta header Retry-After
+func respondLocked(c *gin.Context, remaining time.Duration) {
+	seconds := int(math.Ceil(remaining.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.Error(apperror.New(http.StatusTooManyRequests, apperror.CodeAccountLocked,
+		"Too many failed login attempts, please try again later").
+		WithDetails(gin.H{"retry_after": seconds}))
+}
+
+// UnlockUser: Admin membuka kunci akun yang terkunci karena terlalu banyak login
+// gagal, termasuk kunci IP yang ikut tercatat pada kegagalan tersebut
+func (ctrl *AuthController) UnlockUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	before, err := ctrl.updateUserBefore(ctx, models.AuditActionUpdate, bson.M{"_id": objID}, resetFailuresUpdate)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to unlock user", nil))
+		return
+	}
+	if err := resetIPFailures(ctx, ctrl.DB, before.FailedIPs); err != nil {
+		c.Error(apperror.Internal("Failed to unlock user", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User unlocked successfully")})
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MeController menangani data milik user yang sedang login. Kepemilikan
+// ditentukan lewat link siswa_id / guru_id pada akun, bukan dari email.
+type MeController struct {
+	Tagihan       repository.TagihanRepository
+	Course        repository.CourseRepository
+	Schedule      repository.ScheduleRepository
+	TransaksiGuru repository.TransaksiGuruRepository
+}
+
+// NewMeController membuat instance MeController
+func NewMeController(repos *repository.Repositories) *MeController {
+	return &MeController{
+		Tagihan:       repos.Tagihan,
+		Course:        repos.Course,
+		Schedule:      repos.Schedule,
+		TransaksiGuru: repos.TransaksiGuru,
+	}
+}
+
+// linkedSiswaID mengambil siswa_id milik user yang login, atau mengirim 404 jika belum di-link
+func linkedSiswaID(c *gin.Context) (primitive.ObjectID, bool) {
+	user, ok := c.MustGet("user").(models.User)
+	if !ok || user.SiswaID == nil {
+		c.Error(apperror.NotFound("Account is not linked to a siswa"))
+		return primitive.NilObjectID, false
+	}
+	return *user.SiswaID, true
+}
+
+// linkedGuruID mengambil guru_id milik user yang login, atau mengirim 404 jika belum di-link
+func linkedGuruID(c *gin.Context) (primitive.ObjectID, bool) {
+	user, ok := c.MustGet("user").(models.User)
+	if !ok || user.GuruID == nil {
+		c.Error(apperror.NotFound("Account is not linked to a guru"))
+		return primitive.NilObjectID, false
+	}
+	return *user.GuruID, true
+}
+
+// MyTagihan mengambil semua tagihan milik siswa yang terhubung dengan akun
+func (mc *MeController) MyTagihan(c *gin.Context) {
+	siswaID, ok := linkedSiswaID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tagihans, err := mc.Tagihan.List(ctx, repository.TagihanFilter{SiswaID: &siswaID})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch tagihans", nil))
+		return
+	}
+
+	labelTagihan(c, tagihans)
+	c.JSON(http.StatusOK, tagihans)
+}
+
+// MyCourses mengambil kursus yang diikuti siswa (berdasarkan tagihan kursusnya)
+func (mc *MeController) MyCourses(c *gin.Context) {
+	siswaID, ok := linkedSiswaID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	courseIDs, err := mc.Tagihan.CourseIDsBySiswa(ctx, siswaID)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch courses", nil))
+		return
+	}
+
+	courses, err := mc.Course.GetMany(ctx, courseIDs)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch courses", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, courses)
+}
+
+// MySchedule mengambil jadwal dari kursus yang diikuti siswa
+func (mc *MeController) MySchedule(c *gin.Context) {
+	siswaID, ok := linkedSiswaID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	courseIDs, err := mc.Tagihan.CourseIDsBySiswa(ctx, siswaID)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch schedules", nil))
+		return
+	}
+
+	// Jadwal menyimpan courseId dalam bentuk string hex
+	hexIDs := make([]string, 0, len(courseIDs))
+	for _, id := range courseIDs {
+		hexIDs = append(hexIDs, id.Hex())
+	}
+
+	schedules, err := mc.Schedule.ListByCourseIDs(ctx, hexIDs)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch schedules", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// MySalarySlips mengambil slip gaji (transaksi guru) milik guru yang terhubung dengan akun
+func (mc *MeController) MySalarySlips(c *gin.Context) {
+	guruID, ok := linkedGuruID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	slips, err := mc.TransaksiGuru.List(ctx, repository.TransaksiGuruFilter{GuruID: &guruID})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch transactions", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, slips)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetMe: Mengembalikan profil user yang sedang login
+func (ctrl *AuthController) GetMe(c *gin.Context) {
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// UpdateMe: Memperbarui profil user yang sedang login (saat ini hanya username).
+// Email diubah lewat UpdateMyEmail karena harus diverifikasi ulang.
+func (ctrl *AuthController) UpdateMe(c *gin.Context) {
+	var input dto.UpdateProfileRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+	input.Username = strings.TrimSpace(input.Username)
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	userCollection := ctrl.DB.Collection("users")
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	count, err := userCollection.CountDocuments(ctx, bson.M{"username": input.Username, "_id": bson.M{"$ne": user.ID}})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to check username", nil))
+		return
+	}
+	if count > 0 {
+		c.Error(apperror.Conflict("Username is already used"))
+		return
+	}
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"username": input.Username}})
+	if mongo.IsDuplicateKeyError(err) {
+		c.Error(apperror.Conflict("Username is already used"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to update profile", nil))
+		return
+	}
+
+	user.Username = input.Username
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Profile updated successfully"), "user": user})
+}
+
+// ChangeMyPassword: Mengganti password user yang sedang login.
+// Sesi lain milik user dicabut, sesi yang sedang dipakai tetap berlaku.
+func (ctrl *AuthController) ChangeMyPassword(c *gin.Context) {
+	var input dto.ChangePasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	if !utils.CheckPasswordHash(input.CurrentPassword, user.Password) {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Current password is incorrect"))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to hash password", nil))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	// Nilai password tidak masuk audit log, hanya fakta bahwa password berubah
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"password": hashedPassword}})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to change password", nil))
+		return
+	}
+
+	_, err = ctrl.DB.Collection("sessions").UpdateMany(ctx,
+		bson.M{"user_id": user.ID, "jti": bson.M{"$ne": c.GetString("jti")}, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to revoke other sessions", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Password changed successfully")})
+}
+
+// UpdateMyEmail: Meminta perubahan email. Email baru baru dipakai setelah
+// link verifikasi yang dikirim ke alamat tersebut dibuka.
+func (ctrl *AuthController) UpdateMyEmail(c *gin.Context) {
+	var input dto.UpdateEmailRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	if !utils.CheckPasswordHash(input.Password, user.Password) {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid password"))
+		return
+	}
+	if strings.EqualFold(input.Email, user.Email) {
+		c.Error(apperror.BadRequest("New email is the same as the current email"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	count, err := ctrl.DB.Collection("users").CountDocuments(ctx, bson.M{"email": input.Email})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to check email", nil))
+		return
+	}
+	if count > 0 {
+		c.Error(apperror.Conflict("Email is already used"))
+		return
+	}
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate, bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"pending_email": input.Email}})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to update email", nil))
+		return
+	}
+
+	if err := ctrl.sendVerificationEmail(ctx, user.ID, user.Username, input.Email); err != nil {
+		c.Error(apperror.Internal("Failed to send verification email", nil))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": i18n.T(c, "Verification link sent to the new email address")})
+}
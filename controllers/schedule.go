@@ -2,105 +2,95 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
 	"github.com/organisasi/tubesbackend/models"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/organisasi/tubesbackend/repository"
 )
 
-// Struct untuk mapping data Schedule dengan nama kursus dan multiple dates
-type Schedule struct {
-	CourseId string   `json:"courseId" bson:"courseId"`
-	Name     string   `json:"name" bson:"name"` // Nama kursus ditambahkan di sini
-	Time     []string `json:"time" bson:"time"`
-	Dates    []string `json:"dates" bson:"dates"`
-}
-
 // Struct ScheduleController untuk menangani jadwal kursus
 type ScheduleController struct {
-	DB *mongo.Database
+	Schedule repository.ScheduleRepository
+	Course   repository.CourseRepository
 }
 
 // Constructor NewScheduleController
-func NewScheduleController(db *mongo.Database) *ScheduleController {
-	return &ScheduleController{DB: db}
+func NewScheduleController(schedules repository.ScheduleRepository, courses repository.CourseRepository) *ScheduleController {
+	return &ScheduleController{Schedule: schedules, Course: courses}
 }
 
 // AddSchedule untuk menambahkan jadwal kursus dengan beberapa tanggal
 func (sc *ScheduleController) AddSchedule(c *gin.Context) {
-	var schedule Schedule
-
-	// Bind JSON ke struct
-	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validasi field wajib
-	if schedule.CourseId == "" || len(schedule.Time) == 0 || len(schedule.Dates) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "CourseId, Time, and Dates are required"})
+	// Bind JSON ke struct dan validasi field wajib
+	var req dto.ScheduleRequest
+	if !bindJSON(c, &req) {
 		return
 	}
+	schedule := req.ToModel()
 
-	// Menyimpan jadwal ke MongoDB
-	collection := sc.DB.Collection("course_schedules")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	// Mengecek apakah courseId sudah ada
-	var existingSchedule Schedule
-	err := collection.FindOne(context.TODO(), bson.M{"courseId": schedule.CourseId}).Decode(&existingSchedule)
+	_, err := sc.Schedule.FindByCourseID(ctx, schedule.CourseId)
 
 	if err == nil {
 		// Jika sudah ada, update dengan menambahkan tanggal baru
-		_, err := collection.UpdateOne(
-			context.TODO(),
-			bson.M{"courseId": schedule.CourseId},
-			bson.M{"$push": bson.M{"dates": bson.M{"$each": schedule.Dates}}},
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := sc.Schedule.AppendDates(ctx, schedule.CourseId, schedule.Dates); err != nil {
+			c.Error(apperror.Internal("Internal server error", err))
 			return
 		}
-	} else if err == mongo.ErrNoDocuments {
-		// Jika belum ada, buat jadwal baru
-		_, err := collection.InsertOne(context.TODO(), schedule)
+	} else if errors.Is(err, repository.ErrNotFound) {
+		// Jika belum ada, buat jadwal baru. Jika request lain sudah membuatnya lebih dulu, tambahkan tanggalnya saja
+		err := sc.Schedule.Create(ctx, schedule)
+		if errors.Is(err, repository.ErrDuplicate) {
+			err = sc.Schedule.AppendDates(ctx, schedule.CourseId, schedule.Dates)
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(apperror.Internal("Internal server error", err))
 			return
 		}
 	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(apperror.Internal("Internal server error", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Course schedule added successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Course schedule added successfully")})
 }
 
-// GetAllSchedules untuk mengambil semua jadwal kursus
-func (sc *ScheduleController) GetAllSchedules(c *gin.Context) {
-	collection := sc.DB.Collection("course_schedules")
+// scheduleListSpec adalah field yang boleh dipakai di parameter sort GET /schedules
+var scheduleListSpec = listSpec{
+	sorts: map[string]string{
+		"courseId": "courseId",
+		"name":     "name",
+	},
+	defaultSort: "name",
+}
 
-	cursor, err := collection.Find(context.TODO(), bson.M{}) // Mengambil semua data tanpa filter
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// GetAllSchedules untuk mengambil jadwal kursus per halaman
+func (sc *ScheduleController) GetAllSchedules(c *gin.Context) {
+	opts, ok := bindList(c, scheduleListSpec, nil)
+	if !ok {
 		return
 	}
-	defer cursor.Close(context.TODO())
 
-	var schedules []Schedule
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	for cursor.Next(context.TODO()) {
-		var schedule Schedule
-		if err := cursor.Decode(&schedule); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		schedules = append(schedules, schedule)
+	page, err := sc.Schedule.ListPage(ctx, opts)
+	if err != nil {
+		respondListError(c, err, "Internal server error")
+		return
 	}
 
-	c.JSON(http.StatusOK, schedules)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 
 // GetScheduleByCourseId untuk mengambil jadwal berdasarkan CourseId
@@ -110,29 +100,27 @@ func (sc *ScheduleController) GetScheduleByCourseId(c *gin.Context) {
 	// Convert courseId menjadi lowercase agar konsisten
 	courseId = strings.ToLower(courseId)
 
-	// Cari kursus berdasarkan name yang cocok
-	collection := sc.DB.Collection("courses")
-	var course models.Course // Menggunakan models.Course
-	err := collection.FindOne(context.TODO(), bson.M{"name": courseId}).Decode(&course)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
+	// Cari kursus berdasarkan name yang cocok
+	course, err := sc.Course.FindByName(ctx, courseId)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Error(apperror.NotFound("Course not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(apperror.Internal("Internal server error", err))
 		}
 		return
 	}
 
 	// Jika kursus ditemukan, ambil jadwalnya
-	scheduleCollection := sc.DB.Collection("course_schedules")
-	var schedule models.Schedule // Pastikan ada model Schedule
-	err = scheduleCollection.FindOne(context.TODO(), bson.M{"courseId": course.ID.Hex()}).Decode(&schedule)
+	schedule, err := sc.Schedule.FindByCourseID(ctx, course.ID.Hex())
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Error(apperror.NotFound("Schedule not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(apperror.Internal("Internal server error", err))
 		}
 		return
 	}
@@ -141,60 +129,53 @@ func (sc *ScheduleController) GetScheduleByCourseId(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"courseId": course.ID.Hex(),
 		"name":     course.Name,
-		"schedule": schedule,
+		"schedule": models.Schedule{Time: schedule.Time, Dates: schedule.Dates},
 	})
 }
 
 // UpdateSchedule untuk memperbarui jadwal berdasarkan CourseId
 func (sc *ScheduleController) UpdateSchedule(c *gin.Context) {
 	courseId := c.Param("courseId")
-	var updatedSchedule Schedule
-
-	// Bind JSON ke struct
-	if err := c.ShouldBindJSON(&updatedSchedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Bind JSON ke struct dan validasi
+	var updatedSchedule dto.UpdateScheduleRequest
+	if !bindJSON(c, &updatedSchedule) {
 		return
 	}
 
-	// Validasi
-	if len(updatedSchedule.Time) == 0 || len(updatedSchedule.Dates) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Time and Dates are required"})
-		return
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	// Update jadwal berdasarkan courseId
-	collection := sc.DB.Collection("course_schedules")
-	result := collection.FindOneAndUpdate(
-		context.TODO(),
-		bson.M{"courseId": courseId},
-		bson.M{"$set": bson.M{"time": updatedSchedule.Time, "dates": updatedSchedule.Dates}},
-	)
-
-	if result.Err() != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Err().Error()})
+	err := sc.Schedule.Update(ctx, courseId, updatedSchedule.Time, updatedSchedule.Dates)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Schedule not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Internal server error", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Schedule updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Schedule updated successfully")})
 }
 
 // DeleteSchedule untuk menghapus jadwal berdasarkan CourseId
 func (sc *ScheduleController) DeleteSchedule(c *gin.Context) {
 	courseId := c.Param("courseId")
 
-	// Menghapus jadwal berdasarkan courseId
-	collection := sc.DB.Collection("course_schedules")
-	result, err := collection.DeleteOne(context.TODO(), bson.M{"courseId": courseId})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Menghapus jadwal berdasarkan courseId
+	err := sc.Schedule.Delete(ctx, courseId)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Schedule not found"))
 		return
 	}
-
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+	if err != nil {
+		c.Error(apperror.Internal("Internal server error", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Schedule deleted successfully")})
 }
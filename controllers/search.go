@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/middlewares"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/validation"
+)
+
+// Jumlah hasil per jenis data di GET /search
+const defaultSearchLimit = 5
+
+// SearchController menangani pencarian gabungan siswa, guru dan kursus
+type SearchController struct {
+	Siswa  repository.SiswaRepository
+	Guru   repository.GuruRepository
+	Course repository.CourseRepository
+}
+
+// NewSearchController membuat instance SearchController
+func NewSearchController(repos *repository.Repositories) *SearchController {
+	return &SearchController{Siswa: repos.Siswa, Guru: repos.Guru, Course: repos.Course}
+}
+
+// searchHit adalah satu hasil pencarian
+type searchHit struct {
+	Score float64     `json:"score"`
+	Data  interface{} `json:"data"`
+}
+
+// searchGroup adalah hasil pencarian untuk satu jenis data, paling relevan lebih dulu
+type searchGroup struct {
+	Type  string      `json:"type"`
+	Items []searchHit `json:"items"`
+}
+
+// Search: Mencari siswa, guru dan kursus sekaligus. Hasil dikelompokkan per
+// jenis data; kelompok dengan hasil paling relevan tampil paling atas.
+// Jenis data yang tidak boleh dibaca user tidak ikut dicari.
+func (sc *SearchController) Search(c *gin.Context) {
+	var query dto.SearchQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSearchLimit
+	}
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	types := query.TypeList()
+	explicit := len(types) > 0
+	if !explicit {
+		types = dto.SearchTypes
+	}
+	// Nama jenis data sama dengan nama resource di tabel permission
+	allowed := []string{}
+	for _, t := range types {
+		if !isSearchType(t) {
+			respondInvalidFields(c, validation.FieldError{
+				Field:   "types",
+				Rule:    "oneof",
+				Param:   strings.Join(dto.SearchTypes, " "),
+				Message: "validation.oneof",
+			})
+			return
+		}
+		if middlewares.HasPermission(user.Role, t, "read") {
+			allowed = append(allowed, t)
+		} else if explicit {
+			c.Error(apperror.Forbidden("Access denied"))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := strings.TrimSpace(query.Q)
+	groups := []searchGroup{}
+	for _, t := range allowed {
+		hits, err := sc.search(ctx, c, t, q, query.Limit)
+		if err != nil {
+			c.Error(apperror.Internal("Failed to search", err))
+			return
+		}
+		if len(hits) > 0 {
+			groups = append(groups, searchGroup{Type: t, Items: hits})
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Items[0].Score > groups[j].Items[0].Score
+	})
+
+	c.JSON(http.StatusOK, gin.H{"query": q, "groups": groups})
+}
+
+// search menjalankan pencarian untuk satu jenis data
+func (sc *SearchController) search(ctx context.Context, c *gin.Context, kind, q string, limit int) ([]searchHit, error) {
+	hits := []searchHit{}
+	switch kind {
+	case "siswa":
+		results, err := sc.Siswa.Search(ctx, q, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			r.Item.StatusLabel = i18n.StatusLabel(c, "siswa", r.Item.Status)
+			hits = append(hits, searchHit{Score: r.Score, Data: r.Item})
+		}
+	case "guru":
+		results, err := sc.Guru.Search(ctx, q, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			r.Item.StatusLabel = i18n.StatusLabel(c, "guru", r.Item.Status)
+			hits = append(hits, searchHit{Score: r.Score, Data: r.Item})
+		}
+	case "course":
+		results, err := sc.Course.Search(ctx, q, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			hits = append(hits, searchHit{Score: r.Score, Data: r.Item})
+		}
+	}
+	return hits, nil
+}
+
+func isSearchType(t string) bool {
+	for _, known := range dto.SearchTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errSessionInvalid = errors.New("invalid session")
+
+// createSession membuat sesi baru untuk user dan mengembalikan access token serta refresh token
+func (ctrl *AuthController) createSession(ctx context.Context, c *gin.Context, user models.User) (string, string, error) {
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		ID:               primitive.NewObjectID(),
+		UserID:           user.ID,
+		JTI:              jti,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        c.Request.UserAgent(),
+		IP:               c.ClientIP(),
+		CreatedAt:        primitive.NewDateTimeFromTime(now),
+		LastUsedAt:       primitive.NewDateTimeFromTime(now),
+		ExpiresAt:        primitive.NewDateTimeFromTime(now.Add(utils.RefreshTokenTTL)),
+	}
+	if _, err := ctrl.DB.Collection("sessions").InsertOne(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := utils.GenerateJWT(user.ID.Hex(), user.Role, jti)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// rotateSession menukar refresh token lama dengan pasangan token baru.
+// Refresh token yang sudah pernah dirotasi lalu dipakai lagi dianggap bocor,
+// sehingga seluruh sesinya langsung dicabut.
+func (ctrl *AuthController) rotateSession(ctx context.Context, refreshToken string) (models.User, string, string, error) {
+	sessions := ctrl.DB.Collection("sessions")
+	hash := utils.HashToken(refreshToken)
+	now := time.Now()
+
+	var session models.Session
+	err := sessions.FindOne(ctx, bson.M{"refresh_token_hash": hash}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		// Deteksi pemakaian ulang refresh token lama
+		revokeErr := sessions.FindOneAndUpdate(ctx,
+			bson.M{"previous_refresh_hash": hash, "revoked_at": nil},
+			bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(now)}},
+		).Err()
+		if revokeErr != nil && revokeErr != mongo.ErrNoDocuments {
+			return models.User{}, "", "", revokeErr
+		}
+		return models.User{}, "", "", errSessionInvalid
+	}
+	if err != nil {
+		return models.User{}, "", "", err
+	}
+
+	if session.RevokedAt != nil || session.ExpiresAt.Time().Before(now) {
+		return models.User{}, "", "", errSessionInvalid
+	}
+
+	var user models.User
+	if err := ctrl.DB.Collection("users").FindOne(ctx, bson.M{"_id": session.UserID, "deleted_at": nil}).Decode(&user); err != nil {
+		return models.User{}, "", "", errSessionInvalid
+	}
+	if user.Status != "active" {
+		return models.User{}, "", "", errSessionInvalid
+	}
+
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return models.User{}, "", "", err
+	}
+	newRefreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return models.User{}, "", "", err
+	}
+
+	// Filter menyertakan hash lama agar dua refresh paralel tidak sama-sama berhasil
+	result, err := sessions.UpdateOne(ctx,
+		bson.M{"_id": session.ID, "refresh_token_hash": hash},
+		bson.M{"$set": bson.M{
+			"jti":                   jti,
+			"refresh_token_hash":    utils.HashToken(newRefreshToken),
+			"previous_refresh_hash": hash,
+			"last_used_at":          primitive.NewDateTimeFromTime(now),
+			"expires_at":            primitive.NewDateTimeFromTime(now.Add(utils.RefreshTokenTTL)),
+		}},
+	)
+	if err != nil {
+		return models.User{}, "", "", err
+	}
+	if result.MatchedCount == 0 {
+		return models.User{}, "", "", errSessionInvalid
+	}
+
+	accessToken, err := utils.GenerateJWT(user.ID.Hex(), user.Role, jti)
+	if err != nil {
+		return models.User{}, "", "", err
+	}
+	return user, accessToken, newRefreshToken, nil
+}
+
+// revokeUserSessions mencabut semua sesi aktif milik user
+func revokeUserSessions(ctx context.Context, db *mongo.Database, userID primitive.ObjectID) error {
+	_, err := db.Collection("sessions").UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	return err
+}
+
+// setAuthCookies menyimpan access token dan refresh token di cookie HttpOnly
+func setAuthCookies(c *gin.Context, accessToken, refreshToken string) {
+	c.SetCookie("auth_token", accessToken, int(utils.AccessTokenTTL.Seconds()), "/", "", false, true)
+	c.SetCookie("refresh_token", refreshToken, int(utils.RefreshTokenTTL.Seconds()), "/auth", "", false, true)
+}
+
+// Refresh: Menukar refresh token dengan access token baru (refresh token ikut dirotasi)
+func (ctrl *AuthController) Refresh(c *gin.Context) {
+	var input dto.RefreshRequest
+	// Body boleh kosong jika refresh token dikirim lewat cookie
+	_ = c.ShouldBindJSON(&input)
+
+	if input.RefreshToken == "" {
+		if cookie, err := c.Cookie("refresh_token"); err == nil {
+			input.RefreshToken = cookie
+		}
+	}
+	if input.RefreshToken == "" {
+		c.Error(apperror.Validation("Refresh token required"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, accessToken, refreshToken, err := ctrl.rotateSession(ctx, input.RefreshToken)
+	if err == errSessionInvalid {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired refresh token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to refresh token", nil))
+		return
+	}
+
+	setAuthCookies(c, accessToken, refreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+		"role":          user.Role,
+	})
+}
+
+// Logout: Mencabut sesi yang sedang dipakai sehingga access token dan refresh token tidak berlaku lagi
+func (ctrl *AuthController) Logout(c *gin.Context) {
+	jti := c.GetString("jti")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := ctrl.DB.Collection("sessions").UpdateOne(ctx,
+		bson.M{"jti": jti, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": primitive.NewDateTimeFromTime(time.Now())}},
+	)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to logout", nil))
+		return
+	}
+
+	// Hapus cookie di browser
+	c.SetCookie("auth_token", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/auth", "", false, true)
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Logout successful")})
+}
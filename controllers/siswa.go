@@ -3,91 +3,88 @@ package controllers
 
 import (
   "context"
+  "errors"
   "net/http"
   "time"
 
 
   "github.com/gin-gonic/gin"
-  "go.mongodb.org/mongo-driver/bson"
   "go.mongodb.org/mongo-driver/bson/primitive"
-  "go.mongodb.org/mongo-driver/mongo"
 
 
+  "github.com/organisasi/tubesbackend/apperror"
+  "github.com/organisasi/tubesbackend/dto"
+  "github.com/organisasi/tubesbackend/i18n"
   "github.com/organisasi/tubesbackend/models"
+  "github.com/organisasi/tubesbackend/repository"
+  "github.com/organisasi/tubesbackend/service"
 )
 
 
 type SiswaController struct {
-  DB *mongo.Database
+  Siswa      repository.SiswaRepository
+  Transaksi  repository.TransaksiSiswaRepository
+  Enrollment *service.EnrollmentService
+  Documents  *service.DocumentService
 }
 
 
 func (sc *SiswaController) CreateSiswa(c *gin.Context) {
-  var siswa models.Siswa
-  if err := c.ShouldBindJSON(&siswa); err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+  var req dto.SiswaRequest
+  if !bindJSON(c, &req) {
     return
   }
 
 
-  // Validasi input
-  if siswa.FullName == "" || siswa.Address == "" || siswa.PhoneNumber == "" || siswa.Email == "" {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
-    return
-  }
-
-
-  // Set ID dan Status default ke "nonaktif"
-  siswa.ID = primitive.NewObjectID()
-  siswa.Status = "nonaktif"
-
-
-  collection := sc.DB.Collection("siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
-  // Simpan ke database
-  result, err := collection.InsertOne(ctx, siswa)
+  // Simpan ke database, status awal selalu "inactive"
+  created, err := sc.Enrollment.RegisterSiswa(ctx, req.ToModel())
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create siswa: " + err.Error()})
+    respondServiceError(c, err, "Failed to create siswa")
     return
   }
 
 
-  c.JSON(http.StatusCreated, gin.H{"id": result.InsertedID})
+  c.JSON(http.StatusCreated, gin.H{"id": created.ID})
 }
 
 
-// GetSiswa mendapatkan daftar siswa
-func (sc *SiswaController) GetSiswa(c *gin.Context) {
-  collection := sc.DB.Collection("siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-  defer cancel()
+// siswaListSpec adalah field yang boleh dipakai di parameter sort GET /siswa
+var siswaListSpec = listSpec{
+  sorts: map[string]string{
+    "id":       "_id",
+    "fullname": "fullname",
+    "email":    "email",
+    "status":   "status",
+  },
+  defaultSort: "fullname",
+}
 
 
-  cursor, err := collection.Find(ctx, bson.M{})
-  if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch siswa: " + err.Error()})
+// GetSiswa mendapatkan daftar siswa per halaman, bisa difilter dengan ?status= dan dicari dengan ?q=
+func (sc *SiswaController) GetSiswa(c *gin.Context) {
+  var query dto.SiswaListQuery
+  opts, ok := bindList(c, siswaListSpec, &query)
+  if !ok {
     return
   }
-  defer cursor.Close(ctx)
-
 
-  var siswaList []models.Siswa
-  if err = cursor.All(ctx, &siswaList); err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse siswa data: " + err.Error()})
-    return
-  }
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  defer cancel()
 
 
-  if len(siswaList) == 0 {
-    c.JSON(http.StatusOK, gin.H{"message": "No students found"})
+  page, err := sc.Siswa.ListPage(ctx, query.ToFilter(), opts)
+  if err != nil {
+    respondListError(c, err, "Failed to fetch siswa")
     return
   }
 
 
-  c.JSON(http.StatusOK, siswaList)
+  labelSiswa(c, page.Items)
+  c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 
 
@@ -96,24 +93,27 @@ func (sc *SiswaController) GetSiswaByID(c *gin.Context) {
   id := c.Param("id")
   objID, err := primitive.ObjectIDFromHex(id)
   if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+    c.Error(apperror.BadRequest("Invalid ID"))
     return
   }
 
 
-  collection := sc.DB.Collection("siswa")
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
 
-  var siswa models.Siswa
-  err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&siswa)
+  siswa, err := sc.Siswa.Get(ctx, objID)
+  if errors.Is(err, repository.ErrNotFound) {
+    c.Error(apperror.NotFound("Siswa not found"))
+    return
+  }
   if err != nil {
-    c.JSON(http.StatusNotFound, gin.H{"error": "Siswa not found"})
+    c.Error(apperror.Internal("Failed to fetch siswa", err))
     return
   }
 
 
+  siswa.StatusLabel = i18n.StatusLabel(c, "siswa", siswa.Status)
   c.JSON(http.StatusOK, siswa)
 }
 
@@ -123,227 +123,171 @@ func (sc *SiswaController) UpdateSiswa(c *gin.Context) {
   id := c.Param("id")
   objID, err := primitive.ObjectIDFromHex(id)
   if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
-    return
-  }
-
-
-  var siswa models.Siswa
-  if err := c.ShouldBindJSON(&siswa); err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+    c.Error(apperror.BadRequest("Invalid ID"))
     return
   }
 
 
-  if siswa.FullName == "" || siswa.Address == "" || siswa.PhoneNumber == "" || siswa.Email == "" {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "All fields are required"})
+  var req dto.SiswaRequest
+  if !bindJSON(c, &req) {
     return
   }
 
 
-  collection := sc.DB.Collection("siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
-  update := bson.M{
-    "$set": bson.M{
-      "fullname":    siswa.FullName,
-      "address":     siswa.Address,
-      "phonenumber": siswa.PhoneNumber,
-      "email":       siswa.Email,
-      "status":      siswa.Status,
-    },
+  err = sc.Siswa.Update(ctx, objID, req.ToModel())
+  if errors.Is(err, repository.ErrNotFound) {
+    c.Error(apperror.NotFound("Siswa not found"))
+    return
   }
-
-
-  _, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update siswa: " + err.Error()})
+    c.Error(apperror.Internal("Failed to update siswa", err))
     return
   }
 
 
-  c.JSON(http.StatusOK, gin.H{"message": "Siswa updated successfully"})
+  c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Siswa updated successfully")})
 }
 
 
-// DeleteSiswa menghapus data siswa berdasarkan ID
+// DeleteSiswa memindahkan siswa ke trash; bisa dipulihkan lewat RestoreSiswa
 func (sc *SiswaController) DeleteSiswa(c *gin.Context) {
   id := c.Param("id")
   objID, err := primitive.ObjectIDFromHex(id)
   if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+    c.Error(apperror.BadRequest("Invalid ID"))
     return
   }
 
 
-  collection := sc.DB.Collection("siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
-  _, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+  err = sc.Siswa.Delete(ctx, objID, actorID(c))
+  if errors.Is(err, repository.ErrNotFound) {
+    c.Error(apperror.NotFound("Siswa not found"))
+    return
+  }
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete siswa: " + err.Error()})
+    c.Error(apperror.Internal("Failed to delete siswa", err))
     return
   }
 
 
-  c.JSON(http.StatusOK, gin.H{"message": "Siswa deleted successfully"})
+  c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Siswa deleted successfully")})
 }
 
 
-// CreateTransaksiSiswa menangani pembuatan transaksi siswa
-func (tc *SiswaController) CreateTransaksiSiswa(c *gin.Context) {
-  var transaksi models.TransaksiSiswa
+// GetSiswaTrash menampilkan siswa yang sudah dihapus
+func (sc *SiswaController) GetSiswaTrash(c *gin.Context) {
+  listTrash(c, sc.Siswa, labelSiswa)
+}
 
 
-  // Bind JSON ke struct
-  if err := c.ShouldBindJSON(&transaksi); err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
-    return
-  }
+// RestoreSiswa memulihkan siswa dari trash
+func (sc *SiswaController) RestoreSiswa(c *gin.Context) {
+  restoreFromTrash(c, sc.Siswa, "Deleted siswa not found", "Siswa restored successfully")
+}
 
 
-  // Validasi input
-  if transaksi.Item == "" || transaksi.Harga <= 0 {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Item, dan Harga harus diisi dengan benar"})
+// CreateTransaksiSiswa menangani pembuatan transaksi siswa
+func (tc *SiswaController) CreateTransaksiSiswa(c *gin.Context) {
+  var req dto.CreateTransaksiSiswaRequest
+  if !bindJSON(c, &req) {
     return
   }
 
 
-  // Set ID dan tanggal transaksi
-  transaksi.ID = primitive.NewObjectID()
-  transaksi.Tanggal = primitive.NewDateTimeFromTime(time.Now())
-
-
-  collection := tc.DB.Collection("transaksi_siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
-  // Simpan transaksi ke database
-  result, err := collection.InsertOne(ctx, transaksi)
+  // Simpan transaksi ke database (ID dan tanggal diisi oleh service)
+  created, err := tc.Enrollment.CreateTransaksi(ctx, req.ToModel())
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan transaksi: " + err.Error()})
+    respondServiceError(c, err, "Failed to create transaksi")
     return
   }
 
 
   c.JSON(http.StatusCreated, gin.H{
-    "message":      "Transaksi berhasil dibuat",
-    "transaksi_id": result.InsertedID,
+    "message":      i18n.T(c, "Transaksi created successfully"),
+    "transaksi_id": created.ID,
   })
 }
 
 
 // UpdateStatusTransaksi mengubah status transaksi menjadi "paid"
 func (tc *SiswaController) UpdateStatusTransaksi(c *gin.Context) {
-  var req struct {
-    TransaksiID string `json:"transaksi_id"`
-  }
-
-
-  // Bind JSON ke struct
-  if err := c.ShouldBindJSON(&req); err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+  var req dto.PayTransaksiSiswaRequest
+  if !bindJSON(c, &req) {
     return
   }
 
 
-  // Konversi transaksi_id ke ObjectID
-  objID, err := primitive.ObjectIDFromHex(req.TransaksiID)
-  if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaksi ID"})
-    return
-  }
+  // transaksi_id sudah divalidasi sebagai ObjectID
+  objID, _ := primitive.ObjectIDFromHex(req.TransaksiID)
 
 
-  collection := tc.DB.Collection("transaksi_siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
-  // Ambil transaksi berdasarkan ID
-  var transaksi struct {
-    ID      primitive.ObjectID `bson:"_id"`
-    SiswaID primitive.ObjectID `bson:"siswa_id"`
-    Status  string             `bson:"status"`
-  }
-
-
-  err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&transaksi)
-  if err != nil {
-    c.JSON(http.StatusNotFound, gin.H{"error": "Transaksi tidak ditemukan"})
+  // Tandai transaksi "paid" lalu aktifkan siswa pemiliknya
+  if err := tc.Enrollment.MarkTransaksiPaid(ctx, objID); err != nil {
+    respondServiceError(c, err, "Failed to update transaksi")
     return
   }
 
 
-  // Jika transaksi sudah berstatus "paid", hentikan proses
-  if transaksi.Status == "paid" {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Transaksi sudah dibayar"})
-    return
-  }
+  c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Transaksi marked as paid and siswa activated")})
+}
 
 
-  // Update status transaksi menjadi "paid"
-  update := bson.M{"$set": bson.M{"status": "paid"}}
-  _, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-  if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui transaksi: " + err.Error()})
-    return
-  }
+// transaksiSiswaListSpec adalah field yang boleh dipakai di parameter sort GET /siswa/all/transaksi
+var transaksiSiswaListSpec = listSpec{
+  sorts: map[string]string{
+    "id":      "_id",
+    "tanggal": "tanggal",
+    "harga":   "harga",
+    "item":    "item",
+    "status":  "status",
+  },
+  defaultSort: "-tanggal",
+}
 
 
-  // Update status siswa berdasarkan id_siswa yang ada di transaksi
-  siswaCollection := tc.DB.Collection("siswa")
-  _, err = siswaCollection.UpdateOne(ctx, bson.M{"_id": transaksi.SiswaID}, bson.M{"$set": bson.M{"status": "aktif"}})
-  if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status siswa: " + err.Error()})
+// GetTransaksiSiswa menampilkan transaksi siswa per halaman, bisa difilter dengan ?siswa_id= dan ?status=
+func (tc *SiswaController) GetAllTransaksiSiswa(c *gin.Context) {
+  var query dto.TransaksiSiswaListQuery
+  opts, ok := bindList(c, transaksiSiswaListSpec, &query)
+  if !ok {
     return
   }
 
-
-  c.JSON(http.StatusOK, gin.H{"message": "Transaksi berhasil diperbarui menjadi paid dan status siswa diperbarui"})
-}
-
-
-// GetTransaksiSiswa menampilkan semua transaksi siswa
-func (tc *SiswaController) GetAllTransaksiSiswa(c *gin.Context) {
-  collection := tc.DB.Collection("transaksi_siswa")
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
 
-  cursor, err := collection.Find(ctx, bson.M{})
+  page, err := tc.Transaksi.ListPage(ctx, query.ToFilter(), opts)
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transaksi: " + err.Error()})
+    respondListError(c, err, "Failed to fetch transaksi")
     return
   }
-  defer cursor.Close(ctx)
 
 
-  var transaksiList []models.TransaksiSiswa
-  if err = cursor.All(ctx, &transaksiList); err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transaksi data: " + err.Error()})
-    return
-  }
-
-
-  if len(transaksiList) == 0 {
-    c.JSON(http.StatusOK, gin.H{"message": "No transactions found"})
-    return
-  }
-
-
-  c.JSON(http.StatusOK, transaksiList)
+  labelTransaksiSiswa(c, page.Items)
+  c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 
 
+// DeleteTransaksi memindahkan transaksi siswa ke trash; bisa dipulihkan lewat RestoreTransaksi
 func (sc *SiswaController) DeleteTransaksi(c *gin.Context) {
-  collection := sc.DB.Collection("transaksi_siswa")
-  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
   defer cancel()
 
 
@@ -352,26 +296,35 @@ func (sc *SiswaController) DeleteTransaksi(c *gin.Context) {
 
   objID, err := primitive.ObjectIDFromHex(transaksiID)
   if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID"})
+    c.Error(apperror.BadRequest("Invalid transaction ID"))
     return
   }
 
 
-  filter := bson.M{"_id": objID}
-  result, err := collection.DeleteOne(ctx, filter)
+  err = sc.Transaksi.Delete(ctx, objID, actorID(c))
+  if errors.Is(err, repository.ErrNotFound) {
+    c.Error(apperror.NotFound("Transaction not found"))
+    return
+  }
   if err != nil {
-    c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transaction"})
+    c.Error(apperror.Internal("Failed to delete transaction", nil))
     return
   }
 
 
-  if result.DeletedCount == 0 {
-    c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
-    return
-  }
+  c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Transaction deleted successfully")})
+}
+
+
+// GetTransaksiTrash menampilkan transaksi siswa yang sudah dihapus
+func (sc *SiswaController) GetTransaksiTrash(c *gin.Context) {
+  listTrash(c, sc.Transaksi, labelTransaksiSiswa)
+}
 
 
-  c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted successfully"})
+// RestoreTransaksi memulihkan transaksi siswa dari trash
+func (sc *SiswaController) RestoreTransaksi(c *gin.Context) {
+  restoreFromTrash(c, sc.Transaksi, "Deleted transaction not found", "Transaction restored successfully")
 }
 
 
@@ -383,26 +336,39 @@ func (tc *SiswaController) GetTransaksiByID(c *gin.Context) {
   // Konversi string ID ke ObjectID
   objID, err := primitive.ObjectIDFromHex(id)
   if err != nil {
-    c.JSON(http.StatusBadRequest, gin.H{"error": "ID tidak valid"})
+    c.Error(apperror.BadRequest("Invalid ID"))
     return
   }
 
 
-  collection := tc.DB.Collection("transaksi_siswa")
   ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
   defer cancel()
 
 
   // Ambil transaksi berdasarkan ID
-  var transaksi bson.M
-  err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&transaksi)
+  transaksi, err := tc.Transaksi.Get(ctx, objID)
+  if errors.Is(err, repository.ErrNotFound) {
+    c.Error(apperror.NotFound("Transaction not found"))
+    return
+  }
   if err != nil {
-    c.JSON(http.StatusNotFound, gin.H{"error": "Transaksi tidak ditemukan"})
+    c.Error(apperror.Internal("Failed to fetch transactions", err))
     return
   }
 
 
+  transaksi.StatusLabel = i18n.StatusLabel(c, "transaksi", transaksi.Status)
   c.JSON(http.StatusOK, gin.H{"transaksi": transaksi})
 }
 
 
+// GetTransaksiInvoice mencetak invoice transaksi siswa sebagai PDF
+func (tc *SiswaController) GetTransaksiInvoice(c *gin.Context) {
+  documentPDF(c, tc.Documents, models.DocumentInvoice, models.DocumentEntityTransaksiSiswa, tc.Documents.TransaksiDocument)
+}
+
+
+// GetTransaksiReceipt mencetak kuitansi transaksi siswa yang sudah dibayar sebagai PDF
+func (tc *SiswaController) GetTransaksiReceipt(c *gin.Context) {
+  documentPDF(c, tc.Documents, models.DocumentReceipt, models.DocumentEntityTransaksiSiswa, tc.Documents.TransaksiDocument)
+}
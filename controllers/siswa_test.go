@@ -0,0 +1,96 @@
+package controllers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSiswaHandlers(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	rec := serve(t, router, http.MethodPost, "/siswa", map[string]string{
+		"fullname":    "Budi",
+		"address":     "Jl. Merdeka 1",
+		"phonenumber": "081234567890",
+		"email":       "budi@example.com",
+	})
+	expectStatus(t, rec, http.StatusCreated, "create")
+	var created struct {
+		ID string `json:"id"`
+	}
+	decode(t, rec, &created)
+	path := "/siswa/" + created.ID
+
+	rec = serve(t, router, http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK, "get")
+	var siswa models.Siswa
+	decode(t, rec, &siswa)
+	if siswa.FullName != "Budi" || siswa.Status != models.SiswaStatusInactive {
+		t.Errorf("get: siswa = %q/%q, want Budi/%s", siswa.FullName, siswa.Status, models.SiswaStatusInactive)
+	}
+
+	rec = serve(t, router, http.MethodPut, path, map[string]string{
+		"fullname":    "Budi Santoso",
+		"address":     "Jl. Merdeka 2",
+		"phonenumber": "081234567890",
+		"email":       "budi@example.com",
+	})
+	expectStatus(t, rec, http.StatusOK, "update")
+	rec = serve(t, router, http.MethodGet, path, nil)
+	decode(t, rec, &siswa)
+	if siswa.FullName != "Budi Santoso" || siswa.Address != "Jl. Merdeka 2" {
+		t.Errorf("update: siswa = %q/%q, want Budi Santoso/Jl. Merdeka 2", siswa.FullName, siswa.Address)
+	}
+
+	rec = serve(t, router, http.MethodDelete, path, nil)
+	expectStatus(t, rec, http.StatusOK, "delete")
+	rec = serve(t, router, http.MethodGet, path, nil)
+	expectError(t, rec, http.StatusNotFound, apperror.CodeNotFound, "get deleted")
+
+	rec = serve(t, router, http.MethodPost, path+"/restore", nil)
+	expectStatus(t, rec, http.StatusOK, "restore")
+	rec = serve(t, router, http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK, "get restored")
+}
+
+func TestSiswaHandlerErrors(t *testing.T) {
+	router, _ := newTestRouter(t)
+	missing := "/siswa/" + primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid id", method: http.MethodGet, path: "/siswa/abc", wantStatus: http.StatusBadRequest, wantCode: apperror.CodeBadRequest},
+		{name: "missing siswa", method: http.MethodGet, path: missing, wantStatus: http.StatusNotFound, wantCode: apperror.CodeNotFound},
+		{name: "delete missing siswa", method: http.MethodDelete, path: missing, wantStatus: http.StatusNotFound, wantCode: apperror.CodeNotFound},
+		{name: "restore live siswa", method: http.MethodPost, path: missing + "/restore", wantStatus: http.StatusNotFound, wantCode: apperror.CodeNotFound},
+		{
+			name:   "invalid fields",
+			method: http.MethodPost,
+			path:   "/siswa",
+			body: map[string]string{
+				"fullname":    "Budi",
+				"address":     "Jl. Merdeka 1",
+				"phonenumber": "12345",
+				"email":       "bukan-email",
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperror.CodeValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, tt.method, tt.path, tt.body)
+			expectError(t, rec, tt.wantStatus, tt.wantCode, tt.name)
+		})
+	}
+}
@@ -2,374 +2,411 @@ package controllers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
 	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TagihanController struct {
-	DB *mongo.Database
+	Tagihan   repository.TagihanRepository
+	Schedules repository.BillingScheduleRepository
+	Billing   *service.BillingService
+	Documents *service.DocumentService
+}
+
+// tagihanListSpec adalah field yang boleh dipakai di parameter sort GET /tagihan
+var tagihanListSpec = listSpec{
+	sorts: map[string]string{
+		"id":         "_id",
+		"created_at": "created_at",
+		"due_date":   "due_date",
+		"amount":     "amount",
+		"siswa_nama": "siswa_nama",
+		"status":     "status",
+	},
+	defaultSort: "-created_at",
 }
 
-// GetTagihan mendapatkan daftar tagihan
+// GetTagihan mendapatkan daftar tagihan per halaman.
+// Filter: siswa_id, course_id, status, created_from, created_to.
 func (sc *TagihanController) GetTagihan(c *gin.Context) {
-	fmt.Println("GetTagihan called") // Tambahkan log
-	collection := sc.DB.Collection("tagihans")
+	var query dto.TagihanListQuery
+	opts, ok := bindList(c, tagihanListSpec, &query)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cursor, err := collection.Find(ctx, bson.M{})
+	page, err := sc.Tagihan.ListPage(ctx, query.ToFilter(), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tagihan: " + err.Error()})
+		respondListError(c, err, "Failed to fetch tagihan")
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var tagihanList []models.Tagihan
-	if err = cursor.All(ctx, &tagihanList); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse tagihan data: " + err.Error()})
+	labelTagihan(c, page.Items)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
+}
+
+func (ctrl *TagihanController) CreateTagihan(c *gin.Context) {
+	// Validasi input (due_date kosong berarti default 7 hari dari sekarang)
+	var req dto.CreateTagihanRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if len(tagihanList) == 0 {
-		c.JSON(http.StatusOK, gin.H{"message": "No tagihan found"})
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	// Nominal tagihan diambil dari biaya kursus
+	tagihan, err := ctrl.Billing.CreateTagihan(ctx, req.ToInput())
+	if err != nil {
+		respondServiceError(c, err, "Failed to create Tagihan")
 		return
 	}
 
-	c.JSON(http.StatusOK, tagihanList)
-}
-
-func (ctrl *TagihanController) CreateTagihan(c *gin.Context) {
-	var tagihanInput struct {
-    SiswaID  string `json:"siswa_id"`
-    CourseID string `json:"course_id"`
-    DueDate  string `json:"due_date"` // Format: "YYYY-MM-DD"
+	// Response
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c, "Tagihan created successfully"),
+		"tagihan": tagihan,
+	})
 }
 
-	// Validate input
-	if err := c.ShouldBindJSON(&tagihanInput); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+// GetTagihanByUser mengambil tagihan milik siswa yang terhubung dengan akun yang login
+func (sc *TagihanController) GetTagihanByUser(c *gin.Context) {
+	// Kepemilikan ditentukan lewat link siswa_id pada akun
+	siswaID, ok := linkedSiswaID(c)
+	if !ok {
 		return
 	}
 
-	// Convert string IDs to MongoDB ObjectIDs
-	siswaID, err := primitive.ObjectIDFromHex(tagihanInput.SiswaID)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tagihans, err := sc.Tagihan.List(ctx, repository.TagihanFilter{SiswaID: &siswaID})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SiswaID"})
+		c.Error(apperror.Internal("Failed to fetch tagihans", nil))
 		return
 	}
 
-	courseID, err := primitive.ObjectIDFromHex(tagihanInput.CourseID)
+	labelTagihan(c, tagihans)
+	c.JSON(http.StatusOK, tagihans)
+}
+
+// GetTagihanByID mengambil data siswa berdasarkan ID
+func (sc *TagihanController) GetTagihanByID(c *gin.Context) {
+	id := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CourseID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	// Fetch siswa data (ambil nama & email)
-	var siswa models.Siswa
-	err = ctrl.DB.Collection("siswa").FindOne(context.TODO(), bson.M{"_id": siswaID}).Decode(&siswa)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tagihan, err := sc.Tagihan.Get(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Tagihan not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa not found"})
+		c.Error(apperror.Internal("Failed to fetch tagihan", nil))
 		return
 	}
 
-	// Fetch course data (ambil nama & harga)
-	var course models.Course
-	err = ctrl.DB.Collection("courses").FindOne(context.TODO(), bson.M{"_id": courseID}).Decode(&course)
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusOK, tagihan)
+}
+
+// GetTagihanInvoice mencetak invoice tagihan sebagai PDF
+func (ctrl *TagihanController) GetTagihanInvoice(c *gin.Context) {
+	documentPDF(c, ctrl.Documents, models.DocumentInvoice, models.DocumentEntityTagihan, ctrl.Documents.TagihanDocument)
+}
+
+// GetPaymentReceipt mencetak kuitansi satu pembayaran tagihan sebagai PDF
+func (ctrl *TagihanController) GetPaymentReceipt(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-		return
-	}
-
-	// Determine due date
-	var dueDateTime time.Time
-	if tagihanInput.DueDate == "" {
-		dueDateTime = time.Now().AddDate(0, 0, 7) // Default: 7 hari dari sekarang
-	} else {
-		dueDateTime, err = time.Parse("2006-01-02", tagihanInput.DueDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid DueDate format. Use 'YYYY-MM-DD'"})
-			return
-		}
-	}
-	dueDate := primitive.NewDateTimeFromTime(dueDateTime)
-
-	// Create tagihan dengan data siswa & course
-	tagihan := models.Tagihan{
-		ID:        primitive.NewObjectID(),
-		SiswaID:   siswaID,
-		SiswaNama: siswa.FullName,
-		SiswaEmail: siswa.Email,
-		CourseID:  courseID,
-		CourseName: course.Name,
-		Amount:    course.Cost,
-		DueDate:   dueDate,
-		Paid:      false,
-		Status:    "Belum Bayar", // Set default status saat tagihan dibuat
-		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
-	}
-
-	// Insert into database
-	_, err = ctrl.DB.Collection("tagihans").InsertOne(context.TODO(), tagihan)
+		c.Error(apperror.BadRequest("Invalid ID"))
+		return
+	}
+	paymentID, err := primitive.ObjectIDFromHex(c.Param("paymentId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Tagihan"})
+		c.Error(apperror.BadRequest("Invalid payment ID"))
 		return
 	}
 
-	// Response
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Tagihan created successfully",
-		"tagihan": tagihan,
-	})
-}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-func (sc *TagihanController) GetTagihanByUser(c *gin.Context) {
-	// Ambil user_id dari context yang disimpan di middleware
-	userID := c.GetString("user_id")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	doc, err := ctrl.Documents.PaymentReceipt(ctx, objID, paymentID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to generate document")
 		return
 	}
+	sendDocumentPDF(c, ctrl.Documents, models.DocumentEntityTagihan, doc)
+}
 
-	// Konversi userID ke ObjectID
-	objID, err := primitive.ObjectIDFromHex(userID)
+// BayarTagihan melunasi sisa tagihan dengan satu pembayaran. Body opsional
+// berisi method (default cash), reference dan notes.
+func (ctrl *TagihanController) BayarTagihan(c *gin.Context) {
+	id := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	// Cari user berdasarkan ID
-	var user models.User
-	err = sc.DB.Collection("users").FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&user)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	var req dto.PayTagihanRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
 		return
 	}
 
-	// Cari siswa berdasarkan email
-	var siswa models.Siswa
-	err = sc.DB.Collection("siswa").FindOne(context.TODO(), bson.M{"email": user.Email}).Decode(&siswa)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	// Catat pembayaran sebesar sisa tagihan; status dan paid_at dihitung dari ledger
+	payment, tagihan, err := ctrl.Billing.RecordPayment(ctx, objID, req.ToInput(actorID(c)))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Siswa not found"})
+		respondServiceError(c, err, "Failed to update tagihan")
 		return
 	}
 
-	// Cari semua tagihan berdasarkan email siswa
-	collection := sc.DB.Collection("tagihans")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusOK, gin.H{
+		"message":    i18n.T(c, "Tagihan marked as paid"),
+		"paid_at":    tagihan.PaidAt,
+		"updated_at": tagihan.UpdatedAt,
+		"payment":    payment,
+		"tagihan":    tagihan,
+	})
+}
 
-	cursor, err := collection.Find(ctx, bson.M{"siswa_email": siswa.Email}) // Gunakan email
+// GetTagihanPayments menampilkan ledger pembayaran tagihan beserta saldonya
+func (ctrl *TagihanController) GetTagihanPayments(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tagihans"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var tagihans []models.Tagihan
-	if err = cursor.All(ctx, &tagihans); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode tagihans"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tagihan, entries, err := ctrl.Billing.Ledger(ctx, objID)
+	if err != nil {
+		respondServiceError(c, err, "Failed to fetch payments")
 		return
 	}
 
-	c.JSON(http.StatusOK, tagihans)
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusOK, gin.H{"tagihan": tagihan, "payments": entries})
 }
 
-// GetTagihanByID mengambil data siswa berdasarkan ID
-func (sc *TagihanController) GetTagihanByID(c *gin.Context) {
-	id := c.Param("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+// CreateTagihanPayment mencatat pembayaran, boleh sebagian atau melebihi sisa tagihan
+func (ctrl *TagihanController) CreateTagihanPayment(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	collection := sc.DB.Collection("tagihans")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	var req dto.PaymentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	var tagihan models.Tagihan
-	err = collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&tagihan)
+	payment, tagihan, err := ctrl.Billing.RecordPayment(ctx, objID, req.ToInput(actorID(c)))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Tagihan not found"})
+		respondServiceError(c, err, "Failed to record payment")
 		return
 	}
 
-	c.JSON(http.StatusOK, tagihan)
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c, "Payment recorded successfully"),
+		"payment": payment,
+		"tagihan": tagihan,
+	})
 }
 
-func (ctrl *TagihanController) BayarTagihan(c *gin.Context) {
-	id := c.Param("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+// RefundTagihan mencatat uang yang dikembalikan ke siswa
+func (ctrl *TagihanController) RefundTagihan(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	// Waktu saat ini
-	now := primitive.NewDateTimeFromTime(time.Now())
+	var req dto.PaymentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
 
-	// Perbarui status menjadi Lunas, serta set paid_at dan updated_at
-	collection := ctrl.DB.Collection("tagihans")
-	_, err = collection.UpdateOne(
-		context.TODO(),
-		bson.M{"_id": objID},
-		bson.M{"$set": bson.M{
-			"paid":      true,
-			"status":    "Lunas",
-			"paid_at":   now,
-			"updated_at": now,
-		}},
-	)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
+	refund, tagihan, err := ctrl.Billing.RefundPayment(ctx, objID, req.ToInput(actorID(c)))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tagihan"})
+		respondServiceError(c, err, "Failed to record refund")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tagihan updated to Lunas", "paid_at": now, "updated_at": now})
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c, "Refund recorded successfully"),
+		"payment": refund,
+		"tagihan": tagihan,
+	})
 }
 
-func (ctrl *TagihanController) UpdateTagihan(c *gin.Context) {
-	id := c.Param("id")
-	objID, err := primitive.ObjectIDFromHex(id)
+// VoidTagihanPayment membatalkan payment atau refund yang salah catat
+func (ctrl *TagihanController) VoidTagihanPayment(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
+		return
+	}
+	paymentID, err := primitive.ObjectIDFromHex(c.Param("paymentId"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid payment ID"))
 		return
 	}
 
-	// Parse request body untuk mendapatkan data yang ingin diupdate
-	var updateData map[string]interface{}
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var req dto.VoidPaymentRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	updateFields := bson.M{}
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
-	// Jika due_date ada, konversi string ke DateTime
-	if dueDateStr, exists := updateData["due_date"].(string); exists {
-		dueDateTime, err := time.Parse("2006-01-02", dueDateStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid DueDate format. Use 'YYYY-MM-DD'"})
-			return
-		}
-		updateFields["due_date"] = primitive.NewDateTimeFromTime(dueDateTime)
+	void, tagihan, err := ctrl.Billing.VoidEntry(ctx, objID, paymentID, req.Reason, actorID(c))
+	if err != nil {
+		respondServiceError(c, err, "Failed to void payment")
+		return
 	}
 
-	// Jika siswa_id ada, konversi string ke ObjectID dan ambil data siswa
-	if siswaIDStr, exists := updateData["siswa_id"].(string); exists {
-		siswaID, err := primitive.ObjectIDFromHex(siswaIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid siswa_id"})
-			return
-		}
-		updateFields["siswa_id"] = siswaID
+	tagihan.StatusLabel = i18n.StatusLabel(c, "tagihan", tagihan.Status)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": i18n.T(c, "Payment voided successfully"),
+		"payment": void,
+		"tagihan": tagihan,
+	})
+}
 
-		var siswa models.Siswa
-		err = ctrl.DB.Collection("siswa").FindOne(context.TODO(), bson.M{"_id": siswaID}).Decode(&siswa)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Siswa not found"})
-			return
-		}
-		updateFields["siswa_nama"] = siswa.FullName
-		updateFields["siswa_email"] = siswa.Email
+func (ctrl *TagihanController) UpdateTagihan(c *gin.Context) {
+	id := c.Param("id")
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid ID"))
+		return
 	}
 
-	// Jika course_id ada, konversi string ke ObjectID dan ambil data course
-	if courseIDStr, exists := updateData["course_id"].(string); exists {
-		courseID, err := primitive.ObjectIDFromHex(courseIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course_id"})
-			return
-		}
-		updateFields["course_id"] = courseID
-
-		var course models.Course
-		err = ctrl.DB.Collection("courses").FindOne(context.TODO(), bson.M{"_id": courseID}).Decode(&course)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
-			return
-		}
-		updateFields["course_name"] = course.Name
+	// Hanya field yang dikirim yang diubah (data siswa dan course diambil oleh service)
+	var req dto.UpdateTagihanRequest
+	if !bindJSON(c, &req) {
+		return
 	}
 
-	// Pastikan updated_at tidak diambil dari input user
-	updateFields["updated_at"] = primitive.NewDateTimeFromTime(time.Now())
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
 	// Perbarui tagihan berdasarkan data yang diberikan
-	collection := ctrl.DB.Collection("tagihans")
-	_, err = collection.UpdateOne(
-		context.TODO(),
-		bson.M{"_id": objID},
-		bson.M{"$set": updateFields},
-	)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tagihan"})
+	if _, err := ctrl.Billing.UpdateTagihan(ctx, objID, req.ToInput()); err != nil {
+		respondServiceError(c, err, "Failed to update tagihan")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tagihan updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Tagihan updated successfully")})
 }
 
-// DeleteTagihan menghapus data tagihan berdasarkan ID
+// DeleteTagihan memindahkan tagihan ke trash; bisa dipulihkan lewat RestoreTagihan
 func (ctrl *TagihanController) DeleteTagihan(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	collection := ctrl.DB.Collection("tagihans")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
 	defer cancel()
 
-	_, err = collection.DeleteOne(ctx, bson.M{"_id": objID})
+	err = ctrl.Tagihan.Delete(ctx, objID, actorID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Tagihan not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tagihan: " + err.Error()})
+		c.Error(apperror.Internal("Failed to delete tagihan", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tagihan deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Tagihan deleted successfully")})
+}
+
+// GetTagihanTrash menampilkan tagihan yang sudah dihapus
+func (ctrl *TagihanController) GetTagihanTrash(c *gin.Context) {
+	listTrash(c, ctrl.Tagihan, labelTagihan)
+}
+
+// RestoreTagihan memulihkan tagihan dari trash
+func (ctrl *TagihanController) RestoreTagihan(c *gin.Context) {
+	restoreFromTrash(c, ctrl.Tagihan, "Deleted tagihan not found", "Tagihan restored successfully")
 }
 
+// GetLaporanTagihan menampilkan laporan tagihan. Filter: status (boleh lebih
+// dari satu), start_date dan end_date. Dengan view=aging, response berisi
+// kelompok umur piutang 0-30, 31-60, 61-90 dan 90+ hari.
 func (ctrl *TagihanController) GetLaporanTagihan(c *gin.Context) {
-    status := c.QueryArray("status") // Mengambil status dari query parameter, bisa kosong
-    startDate, _ := time.Parse("2006-01-02", c.Query("start_date"))
-    endDate, _ := time.Parse("2006-01-02", c.Query("end_date"))
-    filter := bson.M{}
-
-    // Jika ada status yang diterima, lakukan filter berdasarkan status
-    if len(status) > 0 {
-        filter["status"] = bson.M{"$in": status}
-    }
-
-    // Jika ada rentang tanggal, filter berdasarkan tanggal
-    if !startDate.IsZero() && !endDate.IsZero() {
-        filter["created_at"] = bson.M{"$gte": startDate, "$lte": endDate}
-    }
-
-    collection := ctrl.DB.Collection("tagihans")
-    cursor, err := collection.Find(context.TODO(), filter)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil laporan"})
-        return
-    }
-
-    var tagihans []models.Tagihan
-    if err = cursor.All(context.TODO(), &tagihans); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca data"})
-        return
-    }
-
-    c.JSON(http.StatusOK, tagihans)
-}
\ No newline at end of file
+	status := c.QueryArray("status") // Mengambil status dari query parameter, bisa kosong
+	for i := range status {
+		status[i] = models.NormalizeStatus(status[i])
+	}
+	startDate, _ := time.Parse("2006-01-02", c.Query("start_date"))
+	endDate, _ := time.Parse("2006-01-02", c.Query("end_date"))
+	filter := repository.TagihanFilter{
+		Statuses: status, // Jika ada status yang diterima, lakukan filter berdasarkan status
+	}
+
+	// Jika ada rentang tanggal, filter berdasarkan tanggal
+	if !startDate.IsZero() && !endDate.IsZero() {
+		filter.CreatedFrom = startDate
+		filter.CreatedTo = endDate
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tagihans, err := ctrl.Tagihan.List(ctx, filter)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to fetch report", nil))
+		return
+	}
+
+	labelTagihan(c, tagihans)
+
+	// view=aging mengelompokkan sisa tagihan yang lewat jatuh tempo per umur keterlambatan
+	if c.Query("view") == "aging" {
+		asOf := time.Now()
+		c.JSON(http.StatusOK, gin.H{"as_of": asOf, "buckets": service.Aging(tagihans, asOf), "tagihan": tagihans})
+		return
+	}
+	c.JSON(http.StatusOK, tagihans)
+}
@@ -0,0 +1,131 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTagihanHandlers(t *testing.T) {
+	ctx := context.Background()
+	router, repos := newTestRouter(t)
+
+	siswa := models.Siswa{ID: primitive.NewObjectID(), FullName: "Budi", Email: "budi@example.com", Status: models.SiswaStatusActive}
+	if err := repos.Siswa.Create(ctx, &siswa); err != nil {
+		t.Fatalf("create siswa: %v", err)
+	}
+	course := models.Course{Name: "Piano", Cost: 100}
+	if err := repos.Course.Create(ctx, &course); err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+
+	rec := serve(t, router, http.MethodPost, "/tagihan", map[string]string{
+		"siswa_id":  siswa.ID.Hex(),
+		"course_id": course.ID.Hex(),
+	})
+	expectStatus(t, rec, http.StatusCreated, "create")
+	var created struct {
+		Tagihan models.Tagihan `json:"tagihan"`
+	}
+	decode(t, rec, &created)
+	if created.Tagihan.Amount != 100 || created.Tagihan.Status != models.TagihanStatusUnpaid {
+		t.Errorf("create: tagihan = %v/%q, want 100/%s", created.Tagihan.Amount, created.Tagihan.Status, models.TagihanStatusUnpaid)
+	}
+	path := "/tagihan/" + created.Tagihan.ID.Hex()
+
+	rec = serve(t, router, http.MethodPost, path+"/payments", map[string]interface{}{"amount": 40, "method": models.PaymentMethodCash})
+	expectStatus(t, rec, http.StatusCreated, "pay")
+	var paid struct {
+		Payment models.Payment `json:"payment"`
+		Tagihan models.Tagihan `json:"tagihan"`
+	}
+	decode(t, rec, &paid)
+	if paid.Tagihan.Status != models.TagihanStatusPartial || paid.Tagihan.Outstanding != 60 {
+		t.Errorf("pay: tagihan = %q outstanding %v, want %s outstanding 60", paid.Tagihan.Status, paid.Tagihan.Outstanding, models.TagihanStatusPartial)
+	}
+
+	rec = serve(t, router, http.MethodGet, path+"/payments", nil)
+	expectStatus(t, rec, http.StatusOK, "payments")
+	var ledger struct {
+		Payments []models.Payment `json:"payments"`
+	}
+	decode(t, rec, &ledger)
+	if len(ledger.Payments) != 1 || ledger.Payments[0].ID != paid.Payment.ID {
+		t.Errorf("payments = %+v, want only %s", ledger.Payments, paid.Payment.ID.Hex())
+	}
+
+	// Jatuh tempo diubah tanpa menyentuh saldo
+	due := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	rec = serve(t, router, http.MethodPut, path, map[string]string{"due_date": due})
+	expectStatus(t, rec, http.StatusOK, "update")
+	rec = serve(t, router, http.MethodGet, path, nil)
+	expectStatus(t, rec, http.StatusOK, "get")
+	var stored models.Tagihan
+	decode(t, rec, &stored)
+	if got := stored.DueDate.Time().UTC().Format("2006-01-02"); got != due {
+		t.Errorf("update: due date = %s, want %s", got, due)
+	}
+	if stored.PaidAmount != 40 || stored.Status != models.TagihanStatusPartial {
+		t.Errorf("update: tagihan = %q paid %v, want %s paid 40", stored.Status, stored.PaidAmount, models.TagihanStatusPartial)
+	}
+
+	for _, pdfPath := range []string{path + "/invoice.pdf", path + "/payments/" + paid.Payment.ID.Hex() + "/receipt.pdf"} {
+		rec = serve(t, router, http.MethodGet, pdfPath, nil)
+		expectStatus(t, rec, http.StatusOK, pdfPath)
+		if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+			t.Errorf("%s: content type = %q, want application/pdf", pdfPath, ct)
+		}
+	}
+}
+
+func TestTagihanHandlerErrors(t *testing.T) {
+	ctx := context.Background()
+	router, repos := newTestRouter(t)
+
+	course := models.Course{Name: "Piano", Cost: 100}
+	if err := repos.Course.Create(ctx, &course); err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+	missing := "/tagihan/" + primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "invalid id", method: http.MethodGet, path: "/tagihan/abc", wantStatus: http.StatusBadRequest, wantCode: apperror.CodeBadRequest},
+		{name: "missing tagihan", method: http.MethodGet, path: missing, wantStatus: http.StatusNotFound, wantCode: apperror.CodeNotFound},
+		{name: "invalid payment id", method: http.MethodGet, path: missing + "/payments/abc/receipt.pdf", wantStatus: http.StatusBadRequest, wantCode: apperror.CodeBadRequest},
+		{name: "invoice of missing tagihan", method: http.MethodGet, path: missing + "/invoice.pdf", wantStatus: http.StatusNotFound, wantCode: apperror.CodeNotFound},
+		{
+			name:       "payment without method",
+			method:     http.MethodPost,
+			path:       missing + "/payments",
+			body:       map[string]interface{}{"amount": 40},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperror.CodeValidation,
+		},
+		{
+			name:       "missing siswa",
+			method:     http.MethodPost,
+			path:       "/tagihan",
+			body:       map[string]string{"siswa_id": primitive.NewObjectID().Hex(), "course_id": course.ID.Hex()},
+			wantStatus: http.StatusNotFound,
+			wantCode:   apperror.CodeNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, router, tt.method, tt.path, tt.body)
+			expectError(t, rec, tt.wantStatus, tt.wantCode, tt.name)
+		})
+	}
+}
@@ -2,101 +2,71 @@ package controllers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"time"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/organisasi/tubesbackend/models"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type TransaksiGuruController struct {
-	DB *mongo.Database
+	Transaksi repository.TransaksiGuruRepository
+	Payroll   *service.PayrollService
 }
 
 func (ctrl *TransaksiGuruController) CreateTransaksiGuru(c *gin.Context) {
-	var transaksiInput struct {
-		GuruID string  `json:"guru_id"`
-		Amount float64 `json:"amount"`
-		Notes  string  `json:"notes"`
-	}
-
-	if err := c.ShouldBindJSON(&transaksiInput); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var req dto.CreateTransaksiGuruRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	guruID, err := primitive.ObjectIDFromHex(transaksiInput.GuruID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Guru ID"})
-		return
-	}
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
-	loc, _ := time.LoadLocation("Asia/Jakarta")
-	now := time.Now().In(loc)
-	createdAt := now.Format("02-01-2006 15:04:05 WIB")
-	monthYear := now.Format("01-2006") // Format untuk bulan dan tahun
-
-	var existingTransaksi models.TransaksiGuru
-	err = ctrl.DB.Collection("transaksi_guru").FindOne(
-		context.TODO(),
-		bson.M{
-			"guru_id": guruID,
-			"created_at": bson.M{"$regex": monthYear}, // Cari transaksi dalam bulan & tahun yang sama
-		},
-	).Decode(&existingTransaksi)
-
-	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Guru ini sudah memiliki transaksi di bulan ini"})
-		return
-	}
-
-	var guru struct {
-		FullName string `bson:"fullname"`
-	}
-	err = ctrl.DB.Collection("gurus").FindOne(context.TODO(), bson.M{"_id": guruID}).Decode(&guru)
+	// Service memastikan guru hanya dibayar sekali per bulan
+	transaksi, err := ctrl.Payroll.CreatePayout(ctx, req.GuruObjectID(), req.Amount, req.Notes)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch guru data"})
-		return
-	}
-
-	transaksi := models.TransaksiGuru{
-		ID:        primitive.NewObjectID(),
-		GuruID:    guruID,
-		GuruName:  guru.FullName,
-		Amount:    transaksiInput.Amount,
-		CreatedAt: createdAt,
-		Notes:     transaksiInput.Notes,
-	}
-
-	_, err = ctrl.DB.Collection("transaksi_guru").InsertOne(context.TODO(), transaksi)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
+		respondServiceError(c, err, "Failed to create transaction")
 		return
 	}
 
 	c.JSON(http.StatusCreated, transaksi)
 }
 
-// GetAllTransaksiGuru - Mengambil semua transaksi guru
+// transaksiGuruListSpec adalah field yang boleh dipakai di parameter sort GET /transaksi-guru.
+// created_at disimpan sebagai teks DD-MM-YYYY sehingga urutan waktu memakai id.
+var transaksiGuruListSpec = listSpec{
+	sorts: map[string]string{
+		"id":     "_id",
+		"amount": "amount",
+	},
+	defaultSort: "-id",
+}
+
+// GetAllTransaksiGuru - Mengambil transaksi guru per halaman, filter ?guru_id= dan ?month=YYYY-MM
 func (ctrl *TransaksiGuruController) GetAllTransaksiGuru(c *gin.Context) {
-	cursor, err := ctrl.DB.Collection("transaksi_guru").Find(context.TODO(), bson.M{})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+	var query dto.TransaksiGuruListQuery
+	opts, ok := bindList(c, transaksiGuruListSpec, &query)
+	if !ok {
 		return
 	}
 
-	var results []models.TransaksiGuru
-	if err = cursor.All(context.TODO(), &results); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transactions"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := ctrl.Transaksi.ListPage(ctx, query.ToFilter(), opts)
+	if err != nil {
+		respondListError(c, err, "Failed to fetch transactions")
 		return
 	}
 
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
 }
 
 // GetTransaksiGuruByID - Mengambil transaksi berdasarkan ID
@@ -104,14 +74,20 @@ func (ctrl *TransaksiGuruController) GetTransaksiGuruByID(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	var transaksi models.TransaksiGuru
-	err = ctrl.DB.Collection("transaksi_guru").FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&transaksi)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	transaksi, err := ctrl.Transaksi.Get(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Transaction not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		c.Error(apperror.Internal("Failed to fetch transactions", nil))
 		return
 	}
 
@@ -123,84 +99,87 @@ func (ctrl *TransaksiGuruController) UpdateTransaksiGuru(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	var updateData struct {
-		Amount float64 `json:"amount"`
-		Notes  string  `json:"notes"`
-	}
-
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	var req dto.UpdateTransaksiGuruRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	update := bson.M{
-		"amount": updateData.Amount,
-		"notes":  updateData.Notes,
-	}
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
 
-	_, err = ctrl.DB.Collection("transaksi_guru").UpdateOne(context.TODO(), bson.M{"_id": objID}, bson.M{"$set": update})
+	err = ctrl.Transaksi.Update(ctx, objID, req.Amount, req.Notes)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Transaction not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transaction"})
+		c.Error(apperror.Internal("Failed to update transaction", nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transaction updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Transaction updated successfully")})
 }
 
-// DeleteTransaksiGuru - Menghapus transaksi berdasarkan ID
+// DeleteTransaksiGuru - Memindahkan transaksi ke trash; bisa dipulihkan lewat RestoreTransaksiGuru
 func (ctrl *TransaksiGuruController) DeleteTransaksiGuru(c *gin.Context) {
 	id := c.Param("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(apperror.BadRequest("Invalid ID"))
 		return
 	}
 
-	_, err = ctrl.DB.Collection("transaksi_guru").DeleteOne(context.TODO(), bson.M{"_id": objID})
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.Transaksi.Delete(ctx, objID, actorID(c))
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound("Transaction not found"))
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete transaction"})
+		c.Error(apperror.Internal("Failed to delete transaction", nil))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Transaction deleted successfully")})
+}
+
+// GetTransaksiGuruTrash - Menampilkan transaksi yang sudah dihapus
+func (ctrl *TransaksiGuruController) GetTransaksiGuruTrash(c *gin.Context) {
+	listTrash(c, ctrl.Transaksi, nil)
+}
+
+// RestoreTransaksiGuru - Memulihkan transaksi dari trash
+func (ctrl *TransaksiGuruController) RestoreTransaksiGuru(c *gin.Context) {
+	restoreFromTrash(c, ctrl.Transaksi, "Deleted transaction not found", "Transaction restored successfully")
 }
 
 func (ctrl *TransaksiGuruController) GetLaporanGajiGuru(c *gin.Context) {
     month := c.Query("month") // Format dari frontend: "YYYY-MM"
     if month == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Month parameter is required"})
+        c.Error(apperror.Validation("Month parameter is required"))
         return
     }
 
     // Ekstrak bulan dan tahun dari format "YYYY-MM"
-    parts := strings.Split(month, "-")
-    if len(parts) != 2 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format"})
+    period, err := time.Parse("2006-01", month)
+    if err != nil {
+        c.Error(apperror.BadRequest("Invalid month format"))
         return
     }
-    year, monthNum := parts[0], parts[1]
 
-    // Query untuk mengambil transaksi sesuai bulan dan tahun
-    filter := bson.M{
-        "created_at": bson.M{
-            "$regex": primitive.Regex{Pattern: fmt.Sprintf("^\\d{2}-%s-%s", monthNum, year), Options: "i"},
-        },
-    }
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
 
-    var transaksi []models.TransaksiGuru
-    cursor, err := ctrl.DB.Collection("transaksi_guru").Find(context.TODO(), filter)
+    // Query untuk mengambil transaksi sesuai bulan dan tahun
+    transaksi, err := ctrl.Payroll.MonthlyReport(ctx, period.Year(), period.Month())
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
-        return
-    }
-    defer cursor.Close(context.TODO())
-
-    if err = cursor.All(context.TODO(), &transaksi); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode transactions"})
+        c.Error(apperror.Internal("Failed to fetch transactions", nil))
         return
     }
 
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// trashListSpec adalah field yang boleh dipakai di parameter sort semua endpoint trash
+var trashListSpec = listSpec{
+	sorts: map[string]string{
+		"id":         "_id",
+		"deleted_at": "deleted_at",
+	},
+	defaultSort: "-deleted_at",
+}
+
+// listTrash menjawab GET .../trash: daftar data yang sudah dihapus dengan
+// paging yang sama seperti endpoint daftar biasa. label boleh nil.
+func listTrash[T any](c *gin.Context, trash repository.Trash[T], label func(*gin.Context, []T)) {
+	opts, ok := bindList(c, trashListSpec, nil)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	page, err := trash.ListDeleted(ctx, opts)
+	if err != nil {
+		respondListError(c, err, "Failed to fetch trash")
+		return
+	}
+
+	if label != nil {
+		label(c, page.Items)
+	}
+	c.JSON(http.StatusOK, newListResponse(c, page, opts))
+}
+
+// restoreFromTrash menjawab POST .../:id/restore: mengeluarkan data dari trash
+func restoreFromTrash[T any](c *gin.Context, trash repository.Trash[T], notFound, success string) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid ID"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = trash.Restore(ctx, objID)
+	if errors.Is(err, repository.ErrNotFound) {
+		c.Error(apperror.NotFound(notFound))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to restore data", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, success)})
+}
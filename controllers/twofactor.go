@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/utils"
+	"github.com/organisasi/tubesbackend/validation"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Tujuan token tantangan 2FA
+const (
+	challengePurposeLogin = "2fa_login" // Password benar, tinggal verifikasi kode TOTP
+	challengePurposeSetup = "2fa_setup" // Role wajib 2FA tetapi user belum mendaftarkan authenticator
+)
+
+const recoveryCodeCount = 10
+
+// twoFactorRequired mengecek apakah role wajib memakai 2FA menurut konfigurasi
+func (ctrl *AuthController) twoFactorRequired(role string) bool {
+	for _, r := range ctrl.Config.Security.TOTPRequiredRoles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateRecoveryCodes membuat kode pemulihan sekali pakai beserta hash-nya untuk disimpan
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := utils.GenerateRandomToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// respondWithSession membuat sesi baru lalu mengirim token ke client
+func (ctrl *AuthController) respondWithSession(c *gin.Context, ctx context.Context, user models.User, extra gin.H) {
+	token, refreshToken, err := ctrl.createSession(ctx, c, user)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to generate token", nil))
+		return
+	}
+
+	// Simpan token di cookie
+	setAuthCookies(c, token, refreshToken)
+
+	response := gin.H{
+		"message":       i18n.T(c, "Login successful"),
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+		"role":          user.Role, // Pastikan ini ada!
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithChallenge mengirim token tantangan 2FA sebagai pengganti access token
+func respondWithChallenge(c *gin.Context, user models.User, purpose string) {
+	challenge, err := utils.GenerateChallengeJWT(user.ID.Hex(), purpose)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to generate token", nil))
+		return
+	}
+
+	response := gin.H{
+		"challenge_token": challenge,
+		"expires_in":      int(utils.ChallengeTokenTTL.Seconds()),
+	}
+	if purpose == challengePurposeSetup {
+		response["message"] = i18n.T(c, "Two-factor authentication must be set up for this account")
+		response["two_factor_setup_required"] = true
+	} else {
+		response["message"] = i18n.T(c, "Two-factor authentication required")
+		response["two_factor_required"] = true
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// verifySecondFactor memeriksa kode TOTP atau kode pemulihan dan langsung
+// menandainya sudah terpakai
+func (ctrl *AuthController) verifySecondFactor(ctx context.Context, user models.User, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		hash := utils.HashToken(strings.ToLower(strings.TrimSpace(recoveryCode)))
+		err := ctrl.updateUser(ctx, models.AuditActionUpdate,
+			bson.M{"_id": user.ID, "recovery_codes": hash},
+			bson.M{"$pull": bson.M{"recovery_codes": hash}},
+		)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return false, nil
+	}
+
+	// Filter langkah waktu mencegah kode yang sama dipakai dua kali secara paralel.
+	// Penanda anti-replay ini berubah setiap login sehingga tidak dicatat di audit log.
+	result, err := ctrl.DB.Collection("users").UpdateOne(ctx,
+		bson.M{"_id": user.ID, "$or": []bson.M{
+			{"totp_last_step": bson.M{"$lt": step}},
+			{"totp_last_step": bson.M{"$exists": false}},
+		}},
+		bson.M{"$set": bson.M{"totp_last_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// EnrollTwoFactor: Membuat secret TOTP baru (belum aktif sampai dikonfirmasi)
+func (ctrl *AuthController) EnrollTwoFactor(c *gin.Context) {
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.Error(apperror.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.Error(apperror.Internal("Failed to generate secret", nil))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"totp_secret": secret, "totp_enabled": false}},
+	)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to save secret", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": utils.TOTPProvisioningURI(secret, user.Username, ctrl.Config.Security.TOTPIssuer),
+	})
+}
+
+// ConfirmTwoFactor: Mengaktifkan 2FA setelah user memasukkan kode pertama dari authenticator
+func (ctrl *AuthController) ConfirmTwoFactor(c *gin.Context) {
+	var input dto.ConfirmTwoFactorRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.Error(apperror.Conflict("Two-factor authentication is already enabled"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.Error(apperror.BadRequest("Enroll two-factor authentication first"))
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TOTPSecret, input.Code, time.Now())
+	if !valid {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidTOTP, "Invalid code"))
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		c.Error(apperror.Internal("Failed to generate recovery codes", nil))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+			"recovery_codes": hashes,
+		}},
+	)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to enable two-factor authentication", nil))
+		return
+	}
+
+	extra := gin.H{"recovery_codes": codes}
+
+	// Jika dipanggil dari alur setup saat login, langsung buatkan sesi
+	if c.GetBool("two_factor_setup") {
+		ctrl.respondWithSession(c, ctx, user, extra)
+		return
+	}
+
+	extra["message"] = i18n.T(c, "Two-factor authentication enabled")
+	c.JSON(http.StatusOK, extra)
+}
+
+// VerifyTwoFactor: Menukar token tantangan + kode TOTP (atau kode pemulihan) dengan sesi login
+func (ctrl *AuthController) VerifyTwoFactor(c *gin.Context) {
+	var input dto.VerifyTwoFactorRequest
+	if !bindJSON(c, &input) || !requireCodeOrRecovery(c, input.Code, input.RecoveryCode) {
+		return
+	}
+
+	claims, err := utils.VerifyJWT(input.ChallengeToken)
+	if err != nil || claims["purpose"] != challengePurposeLogin {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid or expired challenge token"))
+		return
+	}
+	userID, _ := claims["user_id"].(string)
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	user, err := findUserByHex(ctx, ctrl.DB, userID)
+	if err != nil {
+		c.Error(apperror.Unauthorized("User not found"))
+		return
+	}
+
+	// Kode 6 digit mudah ditebak, jadi kegagalan ikut dihitung seperti password salah
+	if remaining := lockRemaining(user.LockedUntil); remaining > 0 {
+		respondLocked(c, remaining)
+		return
+	}
+	if user.Status != "active" || !user.TOTPEnabled {
+		c.Error(apperror.New(http.StatusForbidden, apperror.CodeAccountInactive, "User is not active"))
+		return
+	}
+
+	valid, err := ctrl.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to verify code", nil))
+		return
+	}
+	if !valid {
+		if err := ctrl.recordUserFailure(ctx, user.ID, c.ClientIP()); err != nil {
+			log.Printf("Failed to record 2FA failure for user %s: %v", user.ID.Hex(), err)
+		}
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidTOTP, "Invalid code"))
+		return
+	}
+
+	if user.FailedLogins > 0 {
+		if _, err := resetUserFailures(ctx, ctrl.DB, user.ID); err != nil {
+			log.Printf("Failed to reset login failures for user %s: %v", user.ID.Hex(), err)
+		}
+	}
+
+	ctrl.respondWithSession(c, ctx, user, nil)
+}
+
+// DisableTwoFactor: Mematikan 2FA, membutuhkan password dan kode yang valid
+func (ctrl *AuthController) DisableTwoFactor(c *gin.Context) {
+	var input dto.DisableTwoFactorRequest
+	if !bindJSON(c, &input) || !requireCodeOrRecovery(c, input.Code, input.RecoveryCode) {
+		return
+	}
+
+	user, ok := c.MustGet("user").(models.User)
+	if !ok {
+		c.Error(apperror.Unauthorized("Invalid user data"))
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.Error(apperror.BadRequest("Two-factor authentication is not enabled"))
+		return
+	}
+	if ctrl.twoFactorRequired(user.Role) {
+		c.Error(apperror.Forbidden("Two-factor authentication is required for this role"))
+		return
+	}
+	if !utils.CheckPasswordHash(input.Password, user.Password) {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidCredentials, "Invalid password"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	valid, err := ctrl.verifySecondFactor(ctx, user, input.Code, input.RecoveryCode)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to verify code", nil))
+		return
+	}
+	if !valid {
+		c.Error(apperror.New(http.StatusUnauthorized, apperror.CodeInvalidTOTP, "Invalid code"))
+		return
+	}
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": user.ID},
+		bson.M{
+			"$set":   bson.M{"totp_enabled": false},
+			"$unset": bson.M{"totp_secret": "", "totp_last_step": "", "recovery_codes": ""},
+		},
+	)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to disable two-factor authentication", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Two-factor authentication disabled")})
+}
+
+// requireCodeOrRecovery memastikan salah satu dari kode TOTP atau kode pemulihan diisi
+func requireCodeOrRecovery(c *gin.Context, code, recoveryCode string) bool {
+	if code != "" || recoveryCode != "" {
+		return true
+	}
+	respondInvalidFields(c,
+		validation.FieldError{Field: "code", Rule: "required_without", Param: "recovery_code", Message: "validation.required_without"},
+		validation.FieldError{Field: "recovery_code", Rule: "required_without", Param: "code", Message: "validation.required_without"},
+	)
+	return false
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/middlewares"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// updateUser menjalankan update pada satu user lalu mencatat keadaan sebelum
+// dan sesudahnya di audit log. mongo.ErrNoDocuments dikembalikan jika tidak
+// ada user yang cocok dengan filter.
+func (ctrl *AuthController) updateUser(ctx context.Context, action string, filter, update bson.M) error {
+	_, err := ctrl.updateUserBefore(ctx, action, filter, update)
+	return err
+}
+
+// updateUserBefore sama dengan updateUser tetapi juga mengembalikan keadaan
+// user sebelum diubah
+func (ctrl *AuthController) updateUserBefore(ctx context.Context, action string, filter, update bson.M) (models.User, error) {
+	users := ctrl.DB.Collection("users")
+
+	var before models.User
+	if err := users.FindOneAndUpdate(ctx, filter, update).Decode(&before); err != nil {
+		return models.User{}, err
+	}
+
+	// Update sudah tersimpan; kegagalan membaca ulang hanya membuat entri audit tidak lengkap
+	var after models.User
+	if err := users.FindOne(ctx, bson.M{"_id": before.ID}).Decode(&after); err != nil {
+		log.Printf("Failed to read user %s after %s: %v", before.ID.Hex(), action, err)
+		repository.RecordAudit(ctx, ctrl.Audit, action, "user", before.ID, before, nil)
+		return before, nil
+	}
+	repository.RecordAudit(ctx, ctrl.Audit, action, "user", before.ID, before, after)
+	return before, nil
+}
+
+// UpdateUserRole: Admin mengganti role user. Role harus terdaftar di tabel policy.
+func (ctrl *AuthController) UpdateUserRole(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	var input dto.UpdateUserRoleRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	if _, ok := middlewares.Policies[input.Role]; !ok {
+		c.Error(apperror.BadRequest("Invalid role value"))
+		return
+	}
+
+	// Admin tidak boleh menurunkan role dirinya sendiri agar tidak terkunci dari panel admin
+	if c.GetString("user_id") == objID.Hex() {
+		c.Error(apperror.BadRequest("You cannot change your own role"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": objID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"role": input.Role}},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to update user role", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User role updated successfully")})
+}
+
+// DeleteUser: Admin menghapus akun secara soft delete. Data tetap ada dan bisa dipulihkan.
+func (ctrl *AuthController) DeleteUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	if c.GetString("user_id") == objID.Hex() {
+		c.Error(apperror.BadRequest("You cannot delete your own account"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{"deleted_at": time.Now()}
+	if actorID, err := primitive.ObjectIDFromHex(c.GetString("user_id")); err == nil {
+		set["deleted_by"] = actorID
+	}
+
+	err = ctrl.updateUser(ctx, models.AuditActionDelete,
+		bson.M{"_id": objID, "deleted_at": nil},
+		bson.M{"$set": set},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to delete user", nil))
+		return
+	}
+
+	// Akun yang dihapus langsung kehilangan semua sesinya
+	if err := revokeUserSessions(ctx, ctrl.DB, objID); err != nil {
+		c.Error(apperror.Internal("Failed to revoke user sessions", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User deleted successfully")})
+}
+
+// RestoreUser: Admin memulihkan akun yang sudah di-soft delete
+func (ctrl *AuthController) RestoreUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.updateUser(ctx, models.AuditActionRestore,
+		bson.M{"_id": objID, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("Deleted user not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to restore user", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User restored successfully")})
+}
+
+// BulkReviewUsers: Admin menyetujui atau menolak banyak pendaftaran sekaligus.
+// Hanya user dengan status "inactive" (menunggu persetujuan) yang diproses.
+func (ctrl *AuthController) BulkReviewUsers(c *gin.Context) {
+	var input dto.BulkReviewUsersRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	status := models.UserStatusActive
+	if input.Action == "reject" {
+		status = models.UserStatusRejected
+	}
+
+	// Semua ID sudah divalidasi sebagai ObjectID
+	objIDs := make([]primitive.ObjectID, 0, len(input.IDs))
+	for _, id := range input.IDs {
+		objID, _ := primitive.ObjectIDFromHex(id)
+		objIDs = append(objIDs, objID)
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	// Setiap user diubah satu per satu agar perubahannya tercatat di audit log
+	users := ctrl.DB.Collection("users")
+	filter := bson.M{"_id": bson.M{"$in": objIDs}, "status": "inactive", "deleted_at": nil}
+	pending, err := users.Distinct(ctx, "_id", filter)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to update users", nil))
+		return
+	}
+
+	var updated int
+	for _, id := range pending {
+		err := ctrl.updateUser(ctx, models.AuditActionUpdate,
+			bson.M{"_id": id, "status": "inactive", "deleted_at": nil},
+			bson.M{"$set": bson.M{"status": status}},
+		)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue // Sudah diproses request lain
+		}
+		if err != nil {
+			c.Error(apperror.Internal("Failed to update users", nil))
+			return
+		}
+		updated++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   i18n.T(c, "Users updated successfully"),
+		"status":    status,
+		"requested": len(objIDs),
+		"updated":   updated,
+	})
+}
+
+// linkTargets memetakan jenis link ke field di users dan koleksi datanya
+var linkTargets = map[string]struct {
+	Field      string
+	Collection string
+}{
+	"siswa": {Field: "siswa_id", Collection: "siswa"},
+	"guru":  {Field: "guru_id", Collection: "gurus"},
+}
+
+// LinkUser: Admin menghubungkan akun user dengan data siswa atau guru.
+// Satu data siswa/guru hanya boleh terhubung ke satu akun.
+func (ctrl *AuthController) LinkUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	var input dto.LinkUserRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	// type dan target_id sudah divalidasi oleh tag binding
+	target := linkTargets[input.Type]
+	targetID, _ := primitive.ObjectIDFromHex(input.TargetID)
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	// Siswa atau guru yang ada di trash tidak bisa ditautkan
+	count, err := ctrl.DB.Collection(target.Collection).CountDocuments(ctx, bson.M{"_id": targetID, "deleted_at": nil})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to link user", nil))
+		return
+	}
+	if count == 0 {
+		c.Error(apperror.NotFound("Linked " + input.Type + " not found"))
+		return
+	}
+
+	users := ctrl.DB.Collection("users")
+	count, err = users.CountDocuments(ctx, bson.M{target.Field: targetID, "_id": bson.M{"$ne": objID}, "deleted_at": nil})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to link user", nil))
+		return
+	}
+	if count > 0 {
+		c.Error(apperror.Conflict("This " + input.Type + " is already linked to another user"))
+		return
+	}
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": objID, "deleted_at": nil},
+		bson.M{"$set": bson.M{target.Field: targetID}},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to link user", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User linked successfully")})
+}
+
+// UnlinkUser: Admin memutus hubungan akun dengan data siswa atau guru (?type=siswa|guru)
+func (ctrl *AuthController) UnlinkUser(c *gin.Context) {
+	objID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(apperror.BadRequest("Invalid user ID format"))
+		return
+	}
+
+	target, ok := linkTargets[c.Query("type")]
+	if !ok {
+		c.Error(apperror.Validation("Type must be 'siswa' or 'guru'"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate,
+		bson.M{"_id": objID, "deleted_at": nil},
+		bson.M{"$unset": bson.M{target.Field: ""}},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to unlink user", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "User unlinked successfully")})
+}
@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/dto"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Masa berlaku token sekali pakai
+const (
+	verifyEmailTokenTTL   = 48 * time.Hour
+	resetPasswordTokenTTL = time.Hour
+)
+
+var errTokenInvalid = errors.New("invalid or expired token")
+
+// issueAuthToken membuat token sekali pakai baru dan membatalkan token lain dengan tujuan yang sama
+func issueAuthToken(ctx context.Context, db *mongo.Database, userID primitive.ObjectID, purpose, email string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := db.Collection("auth_tokens")
+	now := time.Now()
+
+	// Token lama untuk tujuan yang sama tidak boleh dipakai lagi
+	_, err = tokens.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": primitive.NewDateTimeFromTime(now)}},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tokens.InsertOne(ctx, models.AuthToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     email,
+		ExpiresAt: primitive.NewDateTimeFromTime(now.Add(ttl)),
+		CreatedAt: primitive.NewDateTimeFromTime(now),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeAuthToken menandai token sudah dipakai secara atomik dan mengembalikan datanya
+func consumeAuthToken(ctx context.Context, db *mongo.Database, token, purpose string) (models.AuthToken, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+
+	var authToken models.AuthToken
+	err := db.Collection("auth_tokens").FindOneAndUpdate(ctx,
+		bson.M{
+			"token_hash": utils.HashToken(token),
+			"purpose":    purpose,
+			"used_at":    nil,
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used_at": now}},
+	).Decode(&authToken)
+	if err == mongo.ErrNoDocuments {
+		return models.AuthToken{}, errTokenInvalid
+	}
+	return authToken, err
+}
+
+// sendVerificationEmail membuat token verifikasi dan mengirim link-nya ke alamat email
+func (ctrl *AuthController) sendVerificationEmail(ctx context.Context, userID primitive.ObjectID, username, email string) error {
+	token, err := issueAuthToken(ctx, ctrl.DB, userID, models.TokenPurposeVerifyEmail, email, verifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := ctrl.tokenLink(ctrl.Config.Server.VerifyURL, "/auth/verify-email", token)
+	return ctrl.Mailer.Send(ctx, utils.Mail{
+		To:      email,
+		Subject: "Verifikasi email akun Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nKlik link berikut untuk memverifikasi email Anda:\n%s\n\nLink berlaku selama %d jam.",
+			username, link, int(verifyEmailTokenTTL.Hours())),
+	})
+}
+
+// tokenLink membuat link berisi token untuk email. Link mengarah ke halaman
+// frontend jika dikonfigurasi; tanpa frontend, GET {BaseURL}{path} hanya
+// mengecek token.
+func (ctrl *AuthController) tokenLink(page, path, token string) string {
+	if page == "" {
+		page = strings.TrimRight(ctrl.Config.Server.BaseURL, "/") + path
+	}
+	sep := "?"
+	if strings.Contains(page, "?") {
+		sep = "&"
+	}
+	return page + sep + "token=" + url.QueryEscape(token)
+}
+
+// checkAuthToken menjawab GET dari link di email: token hanya dicek, tidak
+// dipakai, agar pemindai email atau prefetch link tidak menghabiskannya
+func (ctrl *AuthController) checkAuthToken(c *gin.Context, purpose, message string) {
+	token := c.Query("token")
+	if token == "" {
+		c.Error(apperror.Validation("Token required"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := ctrl.DB.Collection("auth_tokens").CountDocuments(ctx, bson.M{
+		"token_hash": utils.HashToken(token),
+		"purpose":    purpose,
+		"used_at":    nil,
+		"expires_at": bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to process request", nil))
+		return
+	}
+	if count == 0 {
+		c.Error(apperror.BadRequest("Invalid or expired token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":   true,
+		"message": i18n.T(c, message),
+	})
+}
+
+// CheckVerifyEmailToken: Mengecek token verifikasi email dari link di email
+// tanpa memakainya. Verifikasi dilakukan lewat POST /auth/verify-email.
+func (ctrl *AuthController) CheckVerifyEmailToken(c *gin.Context) {
+	ctrl.checkAuthToken(c, models.TokenPurposeVerifyEmail, "Verification token is valid")
+}
+
+// VerifyEmail: Menandai email user sebagai terverifikasi menggunakan token dari email
+func (ctrl *AuthController) VerifyEmail(c *gin.Context) {
+	var input dto.VerifyEmailRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	authToken, err := consumeAuthToken(ctx, ctrl.DB, input.Token, models.TokenPurposeVerifyEmail)
+	if err == errTokenInvalid {
+		c.Error(apperror.BadRequest("Invalid or expired token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to verify email", nil))
+		return
+	}
+
+	// Untuk perubahan email, pastikan alamat baru belum dipakai user lain sejak permintaan dibuat
+	users := ctrl.DB.Collection("users")
+	count, err := users.CountDocuments(ctx, bson.M{"email": authToken.Email, "_id": bson.M{"$ne": authToken.UserID}})
+	if err != nil {
+		c.Error(apperror.Internal("Failed to verify email", nil))
+		return
+	}
+	if count > 0 {
+		c.Error(apperror.Conflict("Email is already used by another account"))
+		return
+	}
+
+	// Email yang diverifikasi adalah email yang tercatat di token
+	update := bson.M{
+		"$set":   bson.M{"email": authToken.Email, "email_verified": true},
+		"$unset": bson.M{"pending_email": ""},
+	}
+	err = ctrl.updateUser(ctx, models.AuditActionUpdate, bson.M{"_id": authToken.UserID}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		c.Error(apperror.Conflict("Email is already used by another account"))
+		return
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to verify email", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Email verified successfully")})
+}
+
+// ForgotPassword: Mengirim link reset password ke email user.
+// Respons selalu sama agar tidak membocorkan email mana yang terdaftar.
+func (ctrl *AuthController) ForgotPassword(c *gin.Context) {
+	var input dto.ForgotPasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	response := gin.H{"message": i18n.T(c, "If the email is registered, a password reset link has been sent")}
+
+	var user models.User
+	err := ctrl.DB.Collection("users").FindOne(ctx, bson.M{"email": input.Email, "deleted_at": nil}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to process request", nil))
+		return
+	}
+
+	token, err := issueAuthToken(ctx, ctrl.DB, user.ID, models.TokenPurposeResetPassword, user.Email, resetPasswordTokenTTL)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to process request", nil))
+		return
+	}
+
+	link := ctrl.tokenLink(ctrl.Config.Server.ResetURL, "/auth/reset-password", token)
+
+	err = ctrl.Mailer.Send(ctx, utils.Mail{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf("Halo %s,\n\nGunakan token berikut untuk mengatur ulang password Anda:\n%s\n\nAtau buka link ini:\n%s\n\nToken berlaku selama 1 jam. Abaikan email ini jika Anda tidak meminta reset password.",
+			user.Username, token, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send reset password email to %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CheckResetToken: Mengecek token reset password dari link di email tanpa
+// memakainya. Password baru tetap dikirim lewat POST /auth/reset-password.
+func (ctrl *AuthController) CheckResetToken(c *gin.Context) {
+	ctrl.checkAuthToken(c, models.TokenPurposeResetPassword, "Reset token is valid")
+}
+
+// ResetPassword: Mengganti password menggunakan token reset, lalu mencabut semua sesi user
+func (ctrl *AuthController) ResetPassword(c *gin.Context) {
+	var input dto.ResetPasswordRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(auditContext(c), 10*time.Second)
+	defer cancel()
+
+	authToken, err := consumeAuthToken(ctx, ctrl.DB, input.Token, models.TokenPurposeResetPassword)
+	if err == errTokenInvalid {
+		c.Error(apperror.BadRequest("Invalid or expired token"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to reset password", nil))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		c.Error(apperror.Internal("Failed to hash password", nil))
+		return
+	}
+
+	// Pemilik akun sudah membuktikan akses ke emailnya, jadi kunci login ikut dibuka
+	before, err := ctrl.updateUserBefore(ctx, models.AuditActionUpdate,
+		bson.M{"_id": authToken.UserID},
+		bson.M{
+			"$set":   bson.M{"password": hashedPassword, "failed_logins": 0},
+			"$unset": resetFailuresUpdate["$unset"],
+		},
+	)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.Error(apperror.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apperror.Internal("Failed to reset password", nil))
+		return
+	}
+	if err := resetIPFailures(ctx, ctrl.DB, before.FailedIPs); err != nil {
+		log.Printf("Failed to reset login failures for user %s: %v", before.ID.Hex(), err)
+	}
+
+	// Semua sesi lama dicabut karena password sudah berubah
+	if err := revokeUserSessions(ctx, ctrl.DB, authToken.UserID); err != nil {
+		c.Error(apperror.Internal("Failed to revoke user sessions", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c, "Password has been reset successfully")})
+}
@@ -0,0 +1,99 @@
+// Package dto berisi struct request per endpoint beserta aturan validasinya.
+// Aturan ditulis di tag binding dan dicek oleh Gin saat ShouldBindJSON, sehingga
+// handler tidak perlu memvalidasi field satu per satu.
+package dto
+
+// RegisterRequest adalah body POST /auth/register
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"` // bcrypt hanya memakai 72 byte pertama
+}
+
+// LoginRequest adalah body POST /auth/login
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest adalah body POST /auth/refresh. Token boleh dikirim lewat cookie.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// UpdateUserStatusRequest adalah body PUT /auth/users/:id/status
+type UpdateUserStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active inactive"`
+}
+
+// UpdateUserRoleRequest adalah body PUT /auth/users/:id/role.
+// Nilai role dicek terhadap tabel policy di handler.
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// BulkReviewUsersRequest adalah body POST /auth/users/bulk-review
+type BulkReviewUsersRequest struct {
+	IDs    []string `json:"ids" binding:"required,min=1,dive,objectid"`
+	Action string   `json:"action" binding:"required,oneof=approve reject"`
+}
+
+// LinkUserRequest adalah body PUT /auth/users/:id/link
+type LinkUserRequest struct {
+	Type     string `json:"type" binding:"required,oneof=siswa guru"`
+	TargetID string `json:"target_id" binding:"required,objectid"`
+}
+
+// UpdateProfileRequest adalah body PUT /auth/me
+type UpdateProfileRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+}
+
+// ChangePasswordRequest adalah body PUT /auth/me/password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// UpdateEmailRequest adalah body PUT /auth/me/email
+type UpdateEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ForgotPasswordRequest adalah body POST /auth/forgot-password
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyEmailRequest adalah body POST /auth/verify-email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResetPasswordRequest adalah body POST /auth/reset-password
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// ConfirmTwoFactorRequest adalah body POST /auth/2fa/confirm
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// VerifyTwoFactorRequest adalah body POST /auth/2fa/verify.
+// Salah satu dari code atau recovery_code wajib diisi.
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// DisableTwoFactorRequest adalah body POST /auth/2fa/disable.
+// Salah satu dari code atau recovery_code wajib diisi.
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code" binding:"omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code"`
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/validation"
+)
+
+// CourseRequest adalah body POST /courses dan PUT /courses/:id
+type CourseRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Duration    int     `json:"duration" binding:"gte=0"`
+	Cost        float64 `json:"cost" binding:"required,gt=0"`
+	Description string  `json:"description" binding:"max=1000"`
+	Schedule    string  `json:"schedule" binding:"required,max=255"`
+	// BillingPlan kosong berarti satu kali bayar
+	BillingPlan BillingPlanRequest `json:"billing_plan"`
+}
+
+// BillingPlanRequest adalah rencana penagihan kursus. cost selalu harga total
+// kursus: installments (wajib untuk type installments) dan type monthly membaginya
+// rata, masing-masing menjadi installments cicilan atau duration tagihan bulanan.
+type BillingPlanRequest struct {
+	Type         string `json:"type" binding:"omitempty,oneof=one_time monthly installments"`
+	Installments int    `json:"installments" binding:"required_if=Type installments,omitempty,min=2,max=36"`
+	DueDays      int    `json:"due_days" binding:"gte=0,lte=90"`
+}
+
+// ToModel mengubah request menjadi models.Course
+func (r CourseRequest) ToModel() models.Course {
+	return models.Course{
+		Name:        r.Name,
+		Duration:    r.Duration,
+		Cost:        r.Cost,
+		Description: r.Description,
+		Schedule:    r.Schedule,
+		BillingPlan: models.BillingPlan{
+			Type:         r.BillingPlan.Type,
+			Installments: r.BillingPlan.Installments,
+			DueDays:      r.BillingPlan.DueDays,
+		},
+	}
+}
+
+// ScheduleRequest adalah body POST /schedules. Tanggal ditulis dengan format YYYY-MM-DD.
+type ScheduleRequest struct {
+	CourseID string   `json:"courseId" binding:"required,objectid"`
+	Name     string   `json:"name" binding:"max=100"`
+	Time     []string `json:"time" binding:"required,min=1,dive,required"`
+	Dates    []string `json:"dates" binding:"required,min=1,dive,date"`
+}
+
+// ToModel mengubah request menjadi models.CourseSchedule
+func (r ScheduleRequest) ToModel() models.CourseSchedule {
+	return models.CourseSchedule{CourseId: r.CourseID, Name: r.Name, Time: r.Time, Dates: r.Dates}
+}
+
+// UpdateScheduleRequest adalah body PUT /schedules/:courseId
+type UpdateScheduleRequest struct {
+	Time  []string `json:"time" binding:"required,min=1,dive,required"`
+	Dates []string `json:"dates" binding:"required,min=1,dive,date"`
+}
+
+// CourseRegistrationRequest adalah body POST /courses/register (formulir publik)
+type CourseRegistrationRequest struct {
+	CourseID    string   `json:"courseId" binding:"required"`
+	StudentName string   `json:"studentName" binding:"required,max=100"`
+	Email       string   `json:"email" binding:"required,email"`
+	PhoneNumber string   `json:"phonenumber" binding:"required,phone_id"`
+	Status      string   `json:"status" binding:"required"`
+	Courses     []string `json:"courses" binding:"required,min=1,dive,required"`
+}
+
+// ToModel mengubah request menjadi models.Registration
+func (r CourseRegistrationRequest) ToModel() models.Registration {
+	return models.Registration{
+		CourseId:    r.CourseID,
+		StudentName: r.StudentName,
+		Email:       r.Email,
+		Phonenumber: validation.NormalizePhone(r.PhoneNumber),
+		Status:      r.Status,
+		Courses:     r.Courses,
+	}
+}
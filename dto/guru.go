@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GuruRequest adalah body POST /gurus dan PUT /gurus/:id.
+// Status lama "aktif"/"nonaktif" masih diterima dan diubah ke kode baru.
+type GuruRequest struct {
+	FullName      string `json:"fullname" binding:"required,max=100"`
+	Address       string `json:"address" binding:"required,max=255"`
+	PhoneNumber   string `json:"phonenumber" binding:"required,phone_id"`
+	Email         string `json:"email" binding:"required,email"`
+	SchoolSubject string `json:"school_subject" binding:"required,max=100"`
+	Status        string `json:"status" binding:"omitempty,oneof=active inactive aktif nonaktif"`
+}
+
+// ToModel mengubah request menjadi models.Guru. Guru baru tanpa status dianggap aktif.
+func (r GuruRequest) ToModel() models.Guru {
+	status := models.NormalizeStatus(r.Status)
+	if status == "" {
+		status = models.GuruStatusActive
+	}
+	return models.Guru{
+		FullName:      r.FullName,
+		Address:       r.Address,
+		PhoneNumber:   validation.NormalizePhone(r.PhoneNumber),
+		Email:         r.Email,
+		SchoolSubject: r.SchoolSubject,
+		Status:        status,
+	}
+}
+
+// CreateTransaksiGuruRequest adalah body POST /transaksi-guru
+type CreateTransaksiGuruRequest struct {
+	GuruID string  `json:"guru_id" binding:"required,objectid"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Notes  string  `json:"notes" binding:"max=500"`
+}
+
+// GuruObjectID mengembalikan guru_id sebagai ObjectID (sudah divalidasi tag objectid)
+func (r CreateTransaksiGuruRequest) GuruObjectID() primitive.ObjectID {
+	id, _ := primitive.ObjectIDFromHex(r.GuruID)
+	return id
+}
+
+// UpdateTransaksiGuruRequest adalah body PUT /transaksi-guru/:id
+type UpdateTransaksiGuruRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Notes  string  `json:"notes" binding:"max=500"`
+}
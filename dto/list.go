@@ -0,0 +1,182 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Query filter untuk endpoint daftar. Parameter paging (limit, offset, cursor,
+// sort) dibaca terpisah oleh controller karena sama untuk semua endpoint.
+
+// SiswaListQuery adalah filter GET /siswa. q mencari di nama, email dan telepon.
+type SiswaListQuery struct {
+	Status string `form:"status" json:"status" binding:"omitempty,oneof=active inactive aktif nonaktif"`
+	Q      string `form:"q" json:"q" binding:"max=100"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q SiswaListQuery) ToFilter() repository.SiswaFilter {
+	return repository.SiswaFilter{Status: models.NormalizeStatus(q.Status), Q: strings.TrimSpace(q.Q)}
+}
+
+// TransaksiSiswaListQuery adalah filter GET /siswa/all/transaksi
+type TransaksiSiswaListQuery struct {
+	SiswaID string `form:"siswa_id" json:"siswa_id" binding:"omitempty,objectid"`
+	Status  string `form:"status" json:"status" binding:"omitempty,oneof=pending paid"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q TransaksiSiswaListQuery) ToFilter() repository.TransaksiSiswaFilter {
+	return repository.TransaksiSiswaFilter{SiswaID: objectIDPtr(q.SiswaID), Status: q.Status}
+}
+
+// GuruListQuery adalah filter GET /gurus. q mencari di nama dan mata pelajaran.
+type GuruListQuery struct {
+	Status        string `form:"status" json:"status" binding:"omitempty,oneof=active inactive aktif nonaktif"`
+	SchoolSubject string `form:"school_subject" json:"school_subject" binding:"max=100"`
+	Q             string `form:"q" json:"q" binding:"max=100"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q GuruListQuery) ToFilter() repository.GuruFilter {
+	return repository.GuruFilter{
+		Status:        models.NormalizeStatus(q.Status),
+		SchoolSubject: q.SchoolSubject,
+		Q:             strings.TrimSpace(q.Q),
+	}
+}
+
+// CourseListQuery adalah filter GET /courses. q mencari di nama dan deskripsi.
+type CourseListQuery struct {
+	Q string `form:"q" json:"q" binding:"max=100"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q CourseListQuery) ToFilter() repository.CourseFilter {
+	return repository.CourseFilter{Q: strings.TrimSpace(q.Q)}
+}
+
+// TagihanListQuery adalah filter GET /tagihan. created_to inklusif sampai akhir hari.
+type TagihanListQuery struct {
+	SiswaID     string `form:"siswa_id" json:"siswa_id" binding:"omitempty,objectid"`
+	CourseID    string `form:"course_id" json:"course_id" binding:"omitempty,objectid"`
+	Status      string `form:"status" json:"status" binding:"omitempty,oneof=unpaid partial paid overpaid overdue"`
+	CreatedFrom string `form:"created_from" json:"created_from" binding:"omitempty,date"`
+	CreatedTo   string `form:"created_to" json:"created_to" binding:"omitempty,date"`
+}
+
+// ToFilter mengubah query menjadi filter repository. Status lama ikut dicari
+// karena dokumen yang belum dinormalisasi masih bisa ada.
+func (q TagihanListQuery) ToFilter() repository.TagihanFilter {
+	filter := repository.TagihanFilter{SiswaID: objectIDPtr(q.SiswaID), CourseID: objectIDPtr(q.CourseID)}
+	if q.Status != "" {
+		filter.Statuses = append([]string{q.Status}, models.LegacyStatuses(q.Status)...)
+	}
+	if q.CreatedFrom != "" {
+		filter.CreatedFrom, _ = time.Parse("2006-01-02", q.CreatedFrom)
+	}
+	if q.CreatedTo != "" {
+		to, _ := time.Parse("2006-01-02", q.CreatedTo)
+		filter.CreatedTo = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return filter
+}
+
+// TransaksiGuruListQuery adalah filter GET /transaksi-guru
+type TransaksiGuruListQuery struct {
+	GuruID string `form:"guru_id" json:"guru_id" binding:"omitempty,objectid"`
+	Month  string `form:"month" json:"month" binding:"omitempty,month"`
+}
+
+// ToFilter mengubah query menjadi filter repository; month YYYY-MM menjadi periode MM-YYYY
+func (q TransaksiGuruListQuery) ToFilter() repository.TransaksiGuruFilter {
+	filter := repository.TransaksiGuruFilter{GuruID: objectIDPtr(q.GuruID)}
+	if q.Month != "" {
+		month, _ := time.Parse("2006-01", q.Month)
+		filter.Period = month.Format("01-2006")
+	}
+	return filter
+}
+
+// RegistrationListQuery adalah filter GET /courses/registrations
+type RegistrationListQuery struct {
+	Course string `form:"course" json:"course" binding:"max=100"`
+	Status string `form:"status" json:"status" binding:"max=50"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q RegistrationListQuery) ToFilter() repository.RegistrationFilter {
+	return repository.RegistrationFilter{Course: q.Course, Status: q.Status}
+}
+
+// UserListQuery adalah filter GET /auth/users. q mencari di username dan email.
+type UserListQuery struct {
+	Role        string `form:"role" json:"role" binding:"max=50"`
+	Status      string `form:"status" json:"status" binding:"omitempty,oneof=active inactive rejected"`
+	Deleted     string `form:"deleted" json:"deleted" binding:"omitempty,oneof=true false"`
+	Locked      string `form:"locked" json:"locked" binding:"omitempty,oneof=true false"`
+	CreatedFrom string `form:"created_from" json:"created_from" binding:"omitempty,date"`
+	CreatedTo   string `form:"created_to" json:"created_to" binding:"omitempty,date"`
+	Q           string `form:"q" json:"q" binding:"max=100"`
+}
+
+// BillingScheduleListQuery adalah filter GET /tagihan/schedules
+type BillingScheduleListQuery struct {
+	SiswaID  string `form:"siswa_id" json:"siswa_id" binding:"omitempty,objectid"`
+	CourseID string `form:"course_id" json:"course_id" binding:"omitempty,objectid"`
+	Status   string `form:"status" json:"status" binding:"omitempty,oneof=active completed cancelled"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q BillingScheduleListQuery) ToFilter() repository.BillingScheduleFilter {
+	filter := repository.BillingScheduleFilter{Status: q.Status}
+	if q.SiswaID != "" {
+		id, _ := primitive.ObjectIDFromHex(q.SiswaID)
+		filter.SiswaID = &id
+	}
+	if q.CourseID != "" {
+		id, _ := primitive.ObjectIDFromHex(q.CourseID)
+		filter.CourseID = &id
+	}
+	return filter
+}
+
+// AuditListQuery adalah filter GET /audit. from dan to inklusif per hari.
+type AuditListQuery struct {
+	ActorID  string `form:"actor_id" json:"actor_id" binding:"omitempty,objectid"`
+	Entity   string `form:"entity" json:"entity" binding:"omitempty,oneof=user siswa transaksi_siswa guru transaksi_guru tagihan payment billing_schedule course"`
+	EntityID string `form:"entity_id" json:"entity_id" binding:"omitempty,objectid"`
+	Action   string `form:"action" json:"action" binding:"omitempty,oneof=create update delete restore"`
+	From     string `form:"from" json:"from" binding:"omitempty,date"`
+	To       string `form:"to" json:"to" binding:"omitempty,date"`
+}
+
+// ToFilter mengubah query menjadi filter repository
+func (q AuditListQuery) ToFilter() repository.AuditFilter {
+	filter := repository.AuditFilter{
+		ActorID:  objectIDPtr(q.ActorID),
+		Entity:   q.Entity,
+		EntityID: objectIDPtr(q.EntityID),
+		Action:   q.Action,
+	}
+	if q.From != "" {
+		filter.From, _ = time.Parse("2006-01-02", q.From)
+	}
+	if q.To != "" {
+		to, _ := time.Parse("2006-01-02", q.To)
+		filter.To = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return filter
+}
+
+func objectIDPtr(hex string) *primitive.ObjectID {
+	if hex == "" {
+		return nil
+	}
+	id, _ := primitive.ObjectIDFromHex(hex)
+	return &id
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "strings"
+
+// SearchTypes adalah jenis data yang bisa dicari lewat GET /search
+var SearchTypes = []string{"siswa", "guru", "course"}
+
+// SearchQuery adalah query string GET /search. types berisi daftar jenis data
+// dipisah koma; kosong berarti semua jenis yang boleh dibaca user.
+type SearchQuery struct {
+	Q     string `form:"q" json:"q" binding:"required,min=2,max=100"`
+	Types string `form:"types" json:"types"`
+	Limit int    `form:"limit" json:"limit" binding:"omitempty,gte=1,lte=20"`
+}
+
+// TypeList memecah parameter types menjadi daftar tanpa spasi dan duplikat
+func (q SearchQuery) TypeList() []string {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, t := range strings.Split(q.Types, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types
+}
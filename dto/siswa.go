@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SiswaRequest adalah body POST /siswa dan PUT /siswa/:id.
+// Status tidak bisa diisi dari sini karena diatur lewat pembayaran transaksi.
+type SiswaRequest struct {
+	FullName    string `json:"fullname" binding:"required,max=100"`
+	Address     string `json:"address" binding:"required,max=255"`
+	PhoneNumber string `json:"phonenumber" binding:"required,phone_id"`
+	Email       string `json:"email" binding:"required,email"`
+}
+
+// ToModel mengubah request menjadi models.Siswa
+func (r SiswaRequest) ToModel() models.Siswa {
+	return models.Siswa{
+		FullName:    r.FullName,
+		Address:     r.Address,
+		PhoneNumber: validation.NormalizePhone(r.PhoneNumber),
+		Email:       r.Email,
+	}
+}
+
+// CreateTransaksiSiswaRequest adalah body POST /siswa/create/transaksi
+type CreateTransaksiSiswaRequest struct {
+	SiswaID string  `json:"siswa_id" binding:"required,objectid"`
+	UserID  string  `json:"user_id" binding:"omitempty,objectid"`
+	Item    string  `json:"item" binding:"required,max=255"`
+	Harga   float64 `json:"harga" binding:"required,gt=0"`
+}
+
+// ToModel mengubah request menjadi models.TransaksiSiswa. ID sudah divalidasi
+// oleh tag objectid sehingga error konversi bisa diabaikan.
+func (r CreateTransaksiSiswaRequest) ToModel() models.TransaksiSiswa {
+	siswaID, _ := primitive.ObjectIDFromHex(r.SiswaID)
+	transaksi := models.TransaksiSiswa{SiswaID: siswaID, Item: r.Item, Harga: r.Harga}
+	if r.UserID != "" {
+		transaksi.UserID, _ = primitive.ObjectIDFromHex(r.UserID)
+	}
+	return transaksi
+}
+
+// PayTransaksiSiswaRequest adalah body PUT /siswa/update/transaksi
+type PayTransaksiSiswaRequest struct {
+	TransaksiID string `json:"transaksi_id" binding:"required,objectid"`
+}
@@ -0,0 +1,117 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateTagihanRequest adalah body POST /tagihan. due_date kosong berarti 7 hari dari sekarang.
+type CreateTagihanRequest struct {
+	SiswaID  string `json:"siswa_id" binding:"required,objectid"`
+	CourseID string `json:"course_id" binding:"required,objectid"`
+	DueDate  string `json:"due_date" binding:"omitempty,date"`
+}
+
+// ToInput mengubah request menjadi input BillingService. Semua nilai sudah divalidasi tag binding.
+func (r CreateTagihanRequest) ToInput() service.CreateTagihanInput {
+	siswaID, _ := primitive.ObjectIDFromHex(r.SiswaID)
+	courseID, _ := primitive.ObjectIDFromHex(r.CourseID)
+	input := service.CreateTagihanInput{SiswaID: siswaID, CourseID: courseID}
+	if r.DueDate != "" {
+		input.DueDate, _ = time.Parse("2006-01-02", r.DueDate)
+	}
+	return input
+}
+
+// UpdateTagihanRequest adalah body PUT /tagihan/:id. Hanya field yang dikirim yang diubah.
+type UpdateTagihanRequest struct {
+	SiswaID  *string `json:"siswa_id" binding:"omitempty,objectid"`
+	CourseID *string `json:"course_id" binding:"omitempty,objectid"`
+	DueDate  *string `json:"due_date" binding:"omitempty,date"`
+}
+
+// ToInput mengubah request menjadi input BillingService
+func (r UpdateTagihanRequest) ToInput() service.UpdateTagihanInput {
+	var input service.UpdateTagihanInput
+	if r.SiswaID != nil {
+		id, _ := primitive.ObjectIDFromHex(*r.SiswaID)
+		input.SiswaID = &id
+	}
+	if r.CourseID != nil {
+		id, _ := primitive.ObjectIDFromHex(*r.CourseID)
+		input.CourseID = &id
+	}
+	if r.DueDate != nil {
+		dueDate, _ := time.Parse("2006-01-02", *r.DueDate)
+		input.DueDate = &dueDate
+	}
+	return input
+}
+
+// PaymentRequest adalah body POST /tagihan/:id/payments dan POST /tagihan/:id/refunds.
+// received_at kosong berarti hari ini.
+type PaymentRequest struct {
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	Method     string  `json:"method" binding:"required,oneof=cash transfer e-wallet"`
+	Reference  string  `json:"reference" binding:"max=100"`
+	Notes      string  `json:"notes" binding:"max=500"`
+	ReceivedAt string  `json:"received_at" binding:"omitempty,date"`
+}
+
+// ToInput mengubah request menjadi input BillingService
+func (r PaymentRequest) ToInput(receivedBy *primitive.ObjectID) service.PaymentInput {
+	input := service.PaymentInput{
+		Amount:     r.Amount,
+		Method:     r.Method,
+		Reference:  r.Reference,
+		Notes:      r.Notes,
+		ReceivedBy: receivedBy,
+	}
+	if r.ReceivedAt != "" {
+		input.ReceivedAt, _ = time.Parse("2006-01-02", r.ReceivedAt)
+	}
+	return input
+}
+
+// PayTagihanRequest adalah body opsional PUT /tagihan/:id/bayar yang melunasi sisa tagihan.
+// method kosong berarti cash.
+type PayTagihanRequest struct {
+	Method    string `json:"method" binding:"omitempty,oneof=cash transfer e-wallet"`
+	Reference string `json:"reference" binding:"max=100"`
+	Notes     string `json:"notes" binding:"max=500"`
+}
+
+// ToInput mengubah request menjadi input BillingService untuk pelunasan penuh
+func (r PayTagihanRequest) ToInput(receivedBy *primitive.ObjectID) service.PaymentInput {
+	method := r.Method
+	if method == "" {
+		method = models.PaymentMethodCash
+	}
+	return service.PaymentInput{Method: method, Reference: r.Reference, Notes: r.Notes, ReceivedBy: receivedBy}
+}
+
+// VoidPaymentRequest adalah body POST /tagihan/:id/payments/:paymentId/void
+type VoidPaymentRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// EnrollRequest adalah body POST /tagihan/schedules. start_date kosong berarti hari ini.
+type EnrollRequest struct {
+	SiswaID   string `json:"siswa_id" binding:"required,objectid"`
+	CourseID  string `json:"course_id" binding:"required,objectid"`
+	StartDate string `json:"start_date" binding:"omitempty,date"`
+}
+
+// ToInput mengubah request menjadi input BillingService
+func (r EnrollRequest) ToInput() service.EnrollInput {
+	siswaID, _ := primitive.ObjectIDFromHex(r.SiswaID)
+	courseID, _ := primitive.ObjectIDFromHex(r.CourseID)
+	input := service.EnrollInput{SiswaID: siswaID, CourseID: courseID}
+	if r.StartDate != "" {
+		input.StartDate, _ = time.Parse("2006-01-02", r.StartDate)
+	}
+	return input
+}
@@ -0,0 +1,52 @@
+package i18n
+
+// catalogEN hanya berisi key yang teksnya berbeda dari key itu sendiri,
+// karena pesan di kode sudah ditulis dalam bahasa Inggris.
+var catalogEN = map[string]string{
+	// Label status
+	"status.siswa.active":      "Active",
+	"status.siswa.inactive":    "Inactive",
+	"status.guru.active":       "Active",
+	"status.guru.inactive":     "Inactive",
+	"status.tagihan.unpaid":    "Unpaid",
+	"status.tagihan.partial":   "Partially paid",
+	"status.tagihan.paid":      "Paid",
+	"status.tagihan.overpaid":  "Overpaid",
+	"status.tagihan.overdue":   "Overdue",
+	"status.transaksi.pending": "Pending",
+	"status.transaksi.paid":    "Paid",
+	"status.user.active":       "Active",
+	"status.user.inactive":     "Waiting for approval",
+	"status.user.rejected":     "Rejected",
+
+	"status.billing_schedule.active":    "Active",
+	"status.billing_schedule.completed": "Completed",
+	"status.billing_schedule.cancelled": "Cancelled",
+
+	// Pesan validasi per field. {param} diganti parameter aturan.
+	"validation.required":         "This field is required",
+	"validation.required_if":      "This field is required for the selected option",
+	"validation.required_without": "Fill in this field or {param}",
+	"validation.email":            "Must be a valid email address",
+	"validation.phone_id":         "Must be a valid Indonesian phone number, e.g. 081234567890",
+	"validation.objectid":         "Must be a valid ID",
+	"validation.date":             "Must be a date in YYYY-MM-DD format",
+	"validation.month":            "Must be a month in YYYY-MM format",
+	"validation.gt":               "Must be greater than {param}",
+	"validation.gte":              "Must be at least {param}",
+	"validation.lt":               "Must be less than {param}",
+	"validation.lte":              "Must be at most {param}",
+	"validation.min":              "Must be at least {param}",
+	"validation.min.string":       "Must be at least {param} characters",
+	"validation.min.list":         "Must contain at least {param} items",
+	"validation.max":              "Must be at most {param}",
+	"validation.max.string":       "Must be at most {param} characters",
+	"validation.max.list":         "Must contain at most {param} items",
+	"validation.len":              "Must be exactly {param}",
+	"validation.len.string":       "Must be exactly {param} characters",
+	"validation.len.list":         "Must contain exactly {param} items",
+	"validation.numeric":          "Must contain digits only",
+	"validation.oneof":            "Must be one of: {param}",
+	"validation.type":             "Has the wrong type, expected {param}",
+	"validation.cursor":           "Invalid cursor, use next_cursor from the previous page with the same sort",
+}
@@ -0,0 +1,320 @@
+package i18n
+
+// catalogID berisi terjemahan bahasa Indonesia. Key adalah pesan bahasa Inggris
+// yang dipakai di kode.
+var catalogID = map[string]string{
+	// Label status
+	"status.siswa.active":      "Aktif",
+	"status.siswa.inactive":    "Nonaktif",
+	"status.guru.active":       "Aktif",
+	"status.guru.inactive":     "Nonaktif",
+	"status.tagihan.unpaid":    "Belum Bayar",
+	"status.tagihan.partial":   "Sebagian",
+	"status.tagihan.paid":      "Lunas",
+	"status.tagihan.overpaid":  "Lebih Bayar",
+	"status.tagihan.overdue":   "Terlambat",
+	"status.transaksi.pending": "Menunggu Pembayaran",
+	"status.transaksi.paid":    "Dibayar",
+	"status.user.active":       "Aktif",
+	"status.user.inactive":     "Menunggu Persetujuan",
+	"status.user.rejected":     "Ditolak",
+
+	"status.billing_schedule.active":    "Aktif",
+	"status.billing_schedule.completed": "Selesai",
+	"status.billing_schedule.cancelled": "Dibatalkan",
+
+	// Umum
+	"Internal server error":     "Terjadi kesalahan pada server",
+	"Resource not found":        "Data tidak ditemukan",
+	"Invalid input":             "Input tidak valid",
+	"Invalid ID":                "ID tidak valid",
+	"Invalid ID format":         "Format ID tidak valid",
+	"Unauthorized":              "Tidak terautentikasi",
+	"Access denied":             "Akses ditolak",
+	"Failed to fetch data":      "Gagal mengambil data",
+	"Failed to process request": "Gagal memproses permintaan",
+
+	// Autentikasi dan sesi
+	"Token required":                                                  "Token diperlukan",
+	"Invalid token":                                                   "Token tidak valid",
+	"Invalid token format":                                            "Format token tidak valid",
+	"Invalid token claims":                                            "Isi token tidak valid",
+	"Invalid or expired token":                                        "Token tidak valid atau sudah kedaluwarsa",
+	"Invalid or expired refresh token":                                "Refresh token tidak valid atau sudah kedaluwarsa",
+	"Invalid or expired challenge token":                              "Challenge token tidak valid atau sudah kedaluwarsa",
+	"Session has been revoked":                                        "Sesi sudah dicabut",
+	"Refresh token required":                                          "Refresh token diperlukan",
+	"Invalid username or password":                                    "Username atau password salah",
+	"Invalid password":                                                "Password salah",
+	"Current password is incorrect":                                   "Password saat ini salah",
+	"User is not active":                                              "User tidak aktif",
+	"User not found":                                                  "User tidak ditemukan",
+	"Deleted user not found":                                          "User yang dihapus tidak ditemukan",
+	"Invalid user data":                                               "Data user tidak valid",
+	"Username is already used":                                        "Username sudah digunakan",
+	"Email is already used":                                           "Email sudah digunakan",
+	"Username or email is already used":                               "Username atau email sudah digunakan",
+	"Email is already used by another account":                        "Email sudah digunakan akun lain",
+	"New email is the same as the current email":                      "Email baru sama dengan email saat ini",
+	"Failed to check username":                                        "Terjadi kesalahan saat memeriksa username",
+	"Failed to check email":                                           "Terjadi kesalahan saat memeriksa email",
+	"Failed to check username or email":                               "Terjadi kesalahan saat memeriksa username atau email",
+	"Failed to hash password":                                         "Gagal memproses password",
+	"Failed to generate token":                                        "Gagal membuat token",
+	"Failed to process login":                                         "Gagal memproses login",
+	"Failed to register":                                              "Gagal mendaftar",
+	"Failed to refresh token":                                         "Gagal memperbarui token",
+	"Failed to logout":                                                "Gagal logout",
+	"Failed to change password":                                       "Gagal mengganti password",
+	"Failed to reset password":                                        "Gagal mereset password",
+	"Failed to send verification email":                               "Gagal mengirim email verifikasi",
+	"Failed to verify email":                                          "Gagal memverifikasi email",
+	"Failed to update email":                                          "Gagal memperbarui email",
+	"Failed to update profile":                                        "Gagal memperbarui profil",
+	"Failed to revoke other sessions":                                 "Gagal mencabut sesi lain",
+	"Login successful":                                                "Login berhasil",
+	"Logout successful":                                               "Logout berhasil",
+	"User registered successfully, waiting for admin approval":        "Pendaftaran berhasil, menunggu persetujuan admin",
+	"Password changed successfully":                                   "Password berhasil diganti",
+	"Password has been reset successfully":                            "Password berhasil direset",
+	"If the email is registered, a password reset link has been sent": "Jika email terdaftar, link reset password sudah dikirim",
+	"Reset token is valid":                                            "Token reset valid, kirim password baru lewat POST /auth/reset-password",
+	"Verification token is valid":                                     "Token verifikasi valid, konfirmasi lewat POST /auth/verify-email",
+	"Email verified successfully":                                     "Email berhasil diverifikasi",
+	"Verification link sent to the new email address":                 "Link verifikasi sudah dikirim ke email baru",
+	"Profile updated successfully":                                    "Profil berhasil diperbarui",
+
+	// Two-factor authentication
+	"Invalid code":                                        "Kode tidak valid",
+	"Enroll two-factor authentication first":              "Aktifkan autentikasi dua faktor terlebih dahulu",
+	"Two-factor authentication is already enabled":        "Autentikasi dua faktor sudah aktif",
+	"Two-factor authentication is not enabled":            "Autentikasi dua faktor belum aktif",
+	"Two-factor authentication is required for this role": "Role ini wajib memakai autentikasi dua faktor",
+	"Two-factor authentication disabled":                  "Autentikasi dua faktor dinonaktifkan",
+	"Failed to generate secret":                           "Gagal membuat secret",
+	"Failed to save secret":                               "Gagal menyimpan secret",
+	"Failed to verify code":                               "Gagal memverifikasi kode",
+	"Failed to generate recovery codes":                   "Gagal membuat kode pemulihan",
+	"Failed to enable two-factor authentication":          "Gagal mengaktifkan autentikasi dua faktor",
+	"Failed to disable two-factor authentication":         "Gagal menonaktifkan autentikasi dua faktor",
+
+	"Two-factor authentication required":                        "Autentikasi dua faktor diperlukan",
+	"Two-factor authentication enabled":                         "Autentikasi dua faktor diaktifkan",
+	"Two-factor authentication must be set up for this account": "Akun ini wajib mengaktifkan autentikasi dua faktor terlebih dahulu",
+
+	// Manajemen user oleh admin
+	"Invalid user ID format":                                 "Format ID user tidak valid",
+	"Invalid role value":                                     "Role tidak valid",
+	"Type must be 'siswa' or 'guru'":                         "Type harus 'siswa' atau 'guru'",
+	"You cannot change your own role":                        "Anda tidak bisa mengganti role sendiri",
+	"You cannot delete your own account":                     "Anda tidak bisa menghapus akun sendiri",
+	"Linked siswa not found":                                 "Data siswa yang di-link tidak ditemukan",
+	"Linked guru not found":                                  "Data guru yang di-link tidak ditemukan",
+	"This siswa is already linked to another user":           "Siswa ini sudah terhubung dengan user lain",
+	"This guru is already linked to another user":            "Guru ini sudah terhubung dengan user lain",
+	"Account is not linked to a siswa":                       "Akun belum terhubung dengan data siswa",
+	"Account is not linked to a guru":                        "Akun belum terhubung dengan data guru",
+	"Failed to fetch users":                                  "Gagal mengambil data user",
+	"Failed to update user role":                             "Gagal memperbarui role user",
+	"Failed to update user status":                           "Gagal memperbarui status user",
+	"Failed to update users":                                 "Gagal memperbarui data user",
+	"Failed to delete user":                                  "Gagal menghapus user",
+	"Failed to restore user":                                 "Gagal memulihkan user",
+	"Failed to revoke user sessions":                         "Gagal mencabut sesi user",
+	"Failed to link user":                                    "Gagal menghubungkan user",
+	"Failed to unlink user":                                  "Gagal memutus hubungan user",
+	"Failed to unlock user":                                  "Gagal membuka kunci user",
+	"Too many failed login attempts, please try again later": "Terlalu banyak percobaan login gagal, silakan coba lagi nanti",
+	"User status updated successfully":                       "Status user berhasil diperbarui",
+	"User role updated successfully":                         "Role user berhasil diperbarui",
+	"User deleted successfully":                              "User berhasil dihapus",
+	"User restored successfully":                             "User berhasil dipulihkan",
+	"User linked successfully":                               "User berhasil dihubungkan",
+	"User unlinked successfully":                             "Hubungan user berhasil diputus",
+	"User unlocked successfully":                             "Kunci user berhasil dibuka",
+	"Users updated successfully":                             "Data user berhasil diperbarui",
+
+	// Siswa dan transaksi siswa
+	"Siswa not found":                              "Siswa tidak ditemukan",
+	"Failed to create siswa":                       "Gagal menyimpan siswa",
+	"Failed to fetch siswa":                        "Gagal mengambil data siswa",
+	"Failed to update siswa":                       "Gagal memperbarui siswa",
+	"Failed to delete siswa":                       "Gagal menghapus siswa",
+	"Siswa updated successfully":                   "Siswa berhasil diperbarui",
+	"Siswa deleted successfully":                   "Siswa berhasil dihapus",
+	"Deleted siswa not found":                      "Siswa yang dihapus tidak ditemukan",
+	"Siswa restored successfully":                  "Siswa berhasil dipulihkan",
+	"Transaksi not found":                          "Transaksi tidak ditemukan",
+	"Transaction not found":                        "Transaksi tidak ditemukan",
+	"Invalid transaction ID":                       "ID transaksi tidak valid",
+	"Item is required":                             "Item harus diisi",
+	"Harga must be greater than 0":                 "Harga harus lebih dari 0",
+	"Transaksi is already paid":                    "Transaksi sudah dibayar",
+	"Failed to create transaksi":                   "Gagal menyimpan transaksi",
+	"Failed to create transaction":                 "Gagal menyimpan transaksi",
+	"Failed to fetch transaksi":                    "Gagal mengambil transaksi",
+	"Failed to fetch transactions":                 "Gagal mengambil transaksi",
+	"Failed to update transaksi":                   "Gagal memperbarui transaksi",
+	"Failed to update transaction":                 "Gagal memperbarui transaksi",
+	"Failed to delete transaction":                 "Gagal menghapus transaksi",
+	"Transaksi created successfully":               "Transaksi berhasil dibuat",
+	"Transaksi marked as paid and siswa activated": "Transaksi berhasil diperbarui menjadi dibayar dan siswa diaktifkan",
+	"Transaction updated successfully":             "Transaksi berhasil diperbarui",
+	"Transaction deleted successfully":             "Transaksi berhasil dihapus",
+	"Deleted transaction not found":                "Transaksi yang dihapus tidak ditemukan",
+	"Transaction restored successfully":            "Transaksi berhasil dipulihkan",
+
+	// Guru dan gaji guru
+	"Guru not found":                                "Guru tidak ditemukan",
+	"Status parameter is required":                  "Parameter status harus diisi",
+	"No gurus found with the given status":          "Tidak ada guru dengan status tersebut",
+	"Failed to create Guru":                         "Gagal menyimpan guru",
+	"Failed to update Guru":                         "Gagal memperbarui guru",
+	"Failed to delete Guru":                         "Gagal menghapus guru",
+	"Guru updated successfully":                     "Guru berhasil diperbarui",
+	"Guru deleted successfully":                     "Guru berhasil dihapus",
+	"Deleted guru not found":                        "Guru yang dihapus tidak ditemukan",
+	"Guru restored successfully":                    "Guru berhasil dipulihkan",
+	"This guru already has a payout for this month": "Guru ini sudah memiliki transaksi di bulan ini",
+	"Month parameter is required":                   "Parameter month harus diisi",
+	"Invalid month format":                          "Format bulan tidak valid",
+	"Failed to fetch report":                        "Gagal mengambil laporan",
+
+	// Tagihan
+	"Tagihan not found":             "Tagihan tidak ditemukan",
+	"Tagihan is already paid":       "Tagihan sudah lunas",
+	"Failed to create Tagihan":      "Gagal membuat tagihan",
+	"Failed to fetch tagihan":       "Gagal mengambil tagihan",
+	"Failed to fetch tagihans":      "Gagal mengambil tagihan",
+	"Failed to update tagihan":      "Gagal memperbarui tagihan",
+	"Failed to delete tagihan":      "Gagal menghapus tagihan",
+	"Tagihan created successfully":  "Tagihan berhasil dibuat",
+	"Tagihan updated successfully":  "Tagihan berhasil diperbarui",
+	"Tagihan deleted successfully":  "Tagihan berhasil dihapus",
+	"Deleted tagihan not found":     "Tagihan yang dihapus tidak ditemukan",
+	"Tagihan restored successfully": "Tagihan berhasil dipulihkan",
+	"Tagihan marked as paid":        "Tagihan berhasil dilunasi",
+
+	// Ledger pembayaran
+	"Payment not found":                                 "Pembayaran tidak ditemukan",
+	"Invalid payment ID":                                "ID pembayaran tidak valid",
+	"Failed to fetch payments":                          "Gagal mengambil data pembayaran",
+	"Failed to record payment":                          "Gagal mencatat pembayaran",
+	"Failed to record refund":                           "Gagal mencatat refund",
+	"Failed to void payment":                            "Gagal membatalkan pembayaran",
+	"Payment recorded successfully":                     "Pembayaran berhasil dicatat",
+	"Refund recorded successfully":                      "Refund berhasil dicatat",
+	"Payment voided successfully":                       "Pembayaran berhasil dibatalkan",
+	"A payment with this reference is already recorded": "Pembayaran dengan referensi ini sudah dicatat",
+	"This ledger entry is already voided":               "Entri ledger ini sudah dibatalkan",
+	"The ledger changed in the meantime, please retry":  "Ledger berubah saat entri dicatat, silakan coba lagi",
+	"The tagihan changed in the meantime, please retry": "Tagihan berubah saat sedang disimpan, silakan coba lagi",
+	"A void entry cannot be voided":                     "Entri void tidak bisa dibatalkan",
+	"Void the refunds of this tagihan first":            "Batalkan dulu refund tagihan ini",
+	"Refund exceeds the amount paid":                    "Refund melebihi jumlah yang sudah dibayar",
+	"Amount must be greater than zero":                  "Nominal harus lebih dari nol",
+	"Received date cannot be in the future":             "Tanggal diterima tidak boleh di masa depan",
+
+	// Jadwal tagihan
+	"Billing schedule not found":                                        "Jadwal tagihan tidak ditemukan",
+	"Invalid billing schedule ID":                                       "ID jadwal tagihan tidak valid",
+	"Failed to enroll siswa":                                            "Gagal mendaftarkan siswa",
+	"Failed to fetch billing schedules":                                 "Gagal mengambil jadwal tagihan",
+	"Failed to cancel billing schedule":                                 "Gagal membatalkan jadwal tagihan",
+	"Siswa enrolled successfully":                                       "Siswa berhasil didaftarkan dan jadwal tagihan dibuat",
+	"Billing schedule cancelled successfully":                           "Jadwal tagihan berhasil dibatalkan",
+	"This siswa already has an active billing schedule for this course": "Siswa ini sudah memiliki jadwal tagihan aktif untuk kursus ini",
+	"This billing schedule is no longer active":                         "Jadwal tagihan ini sudah tidak aktif",
+	"Monthly billing needs a course duration of at least one month":     "Penagihan bulanan membutuhkan durasi kursus minimal satu bulan",
+	"Installment billing needs at least two installments":               "Penagihan cicilan membutuhkan minimal dua cicilan",
+	"Unknown billing plan":                                              "Rencana penagihan tidak dikenal",
+
+	// Invoice dan kuitansi PDF
+	"Document not found":                                "Dokumen tidak ditemukan",
+	"Failed to generate document":                       "Gagal membuat dokumen",
+	"Failed to verify document":                         "Gagal memverifikasi dokumen",
+	"Receipts are only issued for payments":             "Kuitansi hanya diterbitkan untuk pembayaran",
+	"This document is still being issued, please retry": "Dokumen ini masih sedang diterbitkan, silakan coba lagi",
+	"Transaksi has not been paid yet":                   "Transaksi belum dibayar",
+	"Invoice":                                           "Invoice",
+	"Receipt":                                           "Kuitansi",
+	"Billed to":                                         "Ditagihkan kepada",
+	"Received from":                                     "Diterima dari",
+	"Number":                                            "Nomor",
+	"Date":                                              "Tanggal",
+	"Due date":                                          "Jatuh tempo",
+	"Status":                                            "Status",
+	"Description":                                       "Keterangan",
+	"Amount":                                            "Jumlah",
+	"Total":                                             "Total",
+	"Paid":                                              "Dibayar",
+	"Outstanding":                                       "Sisa tagihan",
+	"Payment history":                                   "Riwayat pembayaran",
+	"No payments recorded":                              "Belum ada pembayaran",
+	"Type":                                              "Jenis",
+	"Method":                                            "Metode",
+	"Reference":                                         "Referensi",
+	"Payment":                                           "Pembayaran",
+	"Refund":                                            "Pengembalian",
+	"Void":                                              "Pembatalan",
+	"Scan to verify this document":                      "Pindai untuk memverifikasi dokumen ini",
+	"Page":                                              "Halaman",
+
+	// Kursus dan jadwal
+	"Course not found":                   "Kursus tidak ditemukan",
+	"Failed to create course":            "Gagal membuat kursus",
+	"Failed to fetch course":             "Gagal mengambil kursus",
+	"Failed to fetch courses":            "Gagal mengambil kursus",
+	"Failed to count courses":            "Gagal menghitung kursus",
+	"Failed to update course":            "Gagal memperbarui kursus",
+	"Failed to delete course":            "Gagal menghapus kursus",
+	"Course created successfully":        "Kursus berhasil dibuat",
+	"Course updated successfully":        "Kursus berhasil diperbarui",
+	"Course deleted successfully":        "Kursus berhasil dihapus",
+	"Deleted course not found":           "Kursus yang dihapus tidak ditemukan",
+	"Course restored successfully":       "Kursus berhasil dipulihkan",
+	"Course registration successful":     "Pendaftaran kursus berhasil",
+	"Schedule not found":                 "Jadwal tidak ditemukan",
+	"Failed to fetch schedules":          "Gagal mengambil jadwal",
+	"Course schedule added successfully": "Jadwal kursus berhasil ditambahkan",
+	"Schedule updated successfully":      "Jadwal berhasil diperbarui",
+	"Schedule deleted successfully":      "Jadwal berhasil dihapus",
+
+	// Pencarian
+	"Failed to search": "Gagal melakukan pencarian",
+
+	// Trash
+	"Failed to fetch trash":  "Gagal mengambil data di trash",
+	"Failed to restore data": "Gagal memulihkan data",
+
+	// Audit log
+	"Failed to fetch audit log": "Gagal mengambil audit log",
+
+	// Pesan validasi per field. {param} diganti parameter aturan.
+	"Some fields are invalid":     "Beberapa field tidak valid",
+	"validation.required":         "Wajib diisi",
+	"validation.required_if":      "Wajib diisi untuk pilihan ini",
+	"validation.required_without": "Isi field ini atau {param}",
+	"validation.email":            "Harus berupa alamat email yang valid",
+	"validation.phone_id":         "Harus berupa nomor telepon Indonesia yang valid, contoh 081234567890",
+	"validation.objectid":         "Harus berupa ID yang valid",
+	"validation.date":             "Harus berupa tanggal dengan format YYYY-MM-DD",
+	"validation.month":            "Harus berupa bulan dengan format YYYY-MM",
+	"validation.gt":               "Harus lebih dari {param}",
+	"validation.gte":              "Minimal {param}",
+	"validation.lt":               "Harus kurang dari {param}",
+	"validation.lte":              "Maksimal {param}",
+	"validation.min":              "Minimal {param}",
+	"validation.min.string":       "Minimal {param} karakter",
+	"validation.min.list":         "Minimal berisi {param} item",
+	"validation.max":              "Maksimal {param}",
+	"validation.max.string":       "Maksimal {param} karakter",
+	"validation.max.list":         "Maksimal berisi {param} item",
+	"validation.len":              "Harus tepat {param}",
+	"validation.len.string":       "Harus tepat {param} karakter",
+	"validation.len.list":         "Harus berisi tepat {param} item",
+	"validation.numeric":          "Hanya boleh berisi angka",
+	"validation.oneof":            "Harus salah satu dari: {param}",
+	"validation.type":             "Tipe data salah, seharusnya {param}",
+	"validation.cursor":           "Cursor tidak valid, gunakan next_cursor dari halaman sebelumnya dengan sort yang sama",
+}
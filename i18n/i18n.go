@@ -0,0 +1,128 @@
+// Package i18n menyediakan katalog pesan id/en yang dipilih dari header Accept-Language.
+// Pesan di kode ditulis dalam bahasa Inggris dan dipakai langsung sebagai key katalog,
+// sehingga pesan yang belum diterjemahkan tetap tampil apa adanya.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale yang didukung
+const (
+	Indonesian = "id"
+	English    = "en"
+)
+
+// contextKey adalah key gin.Context tempat locale request disimpan
+const contextKey = "locale"
+
+var catalogs = map[string]map[string]string{
+	Indonesian: catalogID,
+	English:    catalogEN,
+}
+
+// defaultLocale dipakai jika client tidak mengirim Accept-Language yang dikenali
+var defaultLocale = Indonesian
+
+// SetDefault mengganti locale default. Locale yang tidak didukung diabaikan.
+func SetDefault(locale string) {
+	if Supported(locale) {
+		defaultLocale = locale
+	}
+}
+
+// Supported mengecek apakah locale punya katalog
+func Supported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate memilih locale terbaik dari nilai header Accept-Language,
+// contoh "en-US,en;q=0.9,id;q=0.8". Hanya bahasa utama yang dibandingkan.
+func Negotiate(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.TrimSpace(fields[0]))
+		if lang == "" {
+			continue
+		}
+		if i := strings.IndexByte(lang, '-'); i > 0 {
+			lang = lang[:i]
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		candidates = append(candidates, candidate{lang, q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.q > 0 && Supported(c.lang) {
+			return c.lang
+		}
+	}
+	return defaultLocale
+}
+
+// Middleware menyimpan locale hasil negosiasi di context dan header Content-Language
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(contextKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// Locale mengambil locale request, atau locale default jika middleware belum dipasang
+func Locale(c *gin.Context) string {
+	if locale := c.GetString(contextKey); locale != "" {
+		return locale
+	}
+	return defaultLocale
+}
+
+// Translate menerjemahkan key ke locale tertentu. Jika tidak ada di katalog
+// locale tersebut, dicoba katalog bahasa Inggris, lalu key itu sendiri.
+func Translate(locale, key string) string {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogEN[key]; ok {
+		return msg
+	}
+	return key
+}
+
+// T menerjemahkan key ke locale request
+func T(c *gin.Context, key string) string {
+	return Translate(Locale(c), key)
+}
+
+// StatusLabel mengembalikan label status yang bisa dibaca manusia, contoh
+// StatusLabel(c, "tagihan", "unpaid") -> "Belum Bayar". Kode yang tidak
+// dikenal dikembalikan apa adanya.
+func StatusLabel(c *gin.Context, kind, code string) string {
+	if code == "" {
+		return ""
+	}
+	key := "status." + kind + "." + code
+	if label := T(c, key); label != key {
+		return label
+	}
+	return code
+}
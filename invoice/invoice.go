@@ -0,0 +1,343 @@
+// Package invoice menyusun PDF invoice dan kuitansi. Semuanya dibuat di Go
+// (fpdf dan go-qrcode) tanpa program eksternal seperti wkhtmltopdf.
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// Jenis dokumen, sama dengan models.DocumentInvoice dan models.DocumentReceipt
+const (
+	KindInvoice = "invoice"
+	KindReceipt = "receipt"
+)
+
+// Header adalah kop sekolah di bagian atas dokumen
+type Header struct {
+	Name    string
+	Address string
+	Phone   string
+	Email   string
+	Website string
+}
+
+// Line adalah satu baris rincian biaya
+type Line struct {
+	Description string
+	Amount      float64
+}
+
+// Payment adalah satu baris riwayat pembayaran
+type Payment struct {
+	Date      time.Time
+	Kind      string // "payment", "refund" atau "void"
+	Method    string
+	Reference string
+	Amount    float64 // Negatif jika mengurangi jumlah yang sudah dibayar
+}
+
+// Document adalah isi invoice atau kuitansi. Semua waktu sudah dalam zona
+// waktu aplikasi; label tetap diterjemahkan saat Render.
+type Document struct {
+	Kind        string // KindInvoice atau KindReceipt
+	Number      string
+	IssuedAt    time.Time
+	DueDate     time.Time // Kosong berarti tidak ditampilkan
+	Status      string    // Label status yang ditampilkan
+	BillTo      []string  // Nama siswa, email, alamat
+	Lines       []Line
+	Total       float64
+	Paid        float64
+	Outstanding float64
+	Payments    []Payment
+	VerifyURL   string // Dikodekan ke QR code
+}
+
+// Ukuran halaman A4 dalam mm
+const (
+	margin    = 15.0
+	pageWidth = 210.0
+	bodyWidth = pageWidth - 2*margin
+	qrSize    = 32.0
+)
+
+// Render membuat PDF dari doc. t menerjemahkan label ke bahasa request,
+// contoh func(key string) string { return i18n.T(c, key) }.
+func Render(header Header, doc Document, t func(string) string) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin+5)
+	pdf.SetCreationDate(doc.IssuedAt)
+	pdf.SetModificationDate(doc.IssuedAt)
+	pdf.AliasNbPages("")
+
+	// Font inti PDF memakai cp1252; teks UTF-8 (nama siswa, alamat) diterjemahkan dulu
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	title := t("Invoice")
+	if doc.Kind == KindReceipt {
+		title = t("Receipt")
+	}
+	pdf.SetTitle(title+" "+doc.Number, true)
+	pdf.SetAuthor(header.Name, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(bodyWidth/2, 5, tr(doc.Number), "", 0, "L", false, 0, "")
+		pdf.CellFormat(bodyWidth/2, 5, tr(fmt.Sprintf("%s %d/{nb}", t("Page"), pdf.PageNo())), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	writeHeader(pdf, tr, header, title)
+	writeDetails(pdf, tr, t, doc)
+	writeLines(pdf, tr, t, doc)
+	writePayments(pdf, tr, t, doc.Payments)
+	if err := writeQRCode(pdf, tr, t, doc.VerifyURL); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader menulis kop sekolah di kiri dan judul dokumen di kanan
+func writeHeader(pdf *fpdf.Fpdf, tr func(string) string, header Header, title string) {
+	top := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(bodyWidth, 10, tr(strings.ToUpper(title)), "", 0, "R", false, 0, "")
+
+	pdf.SetXY(margin, top)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(bodyWidth*0.6, 8, tr(header.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	for _, line := range []string{header.Address, joinNonEmpty(" | ", header.Phone, header.Email, header.Website)} {
+		if line != "" {
+			pdf.MultiCell(bodyWidth*0.6, 4.5, tr(line), "", "L", false)
+		}
+	}
+
+	pdf.Ln(3)
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.Line(margin, pdf.GetY(), margin+bodyWidth, pdf.GetY())
+	pdf.Ln(5)
+}
+
+// writeDetails menulis penerima di kiri dan nomor, tanggal dan status di kanan
+func writeDetails(pdf *fpdf.Fpdf, tr func(string) string, t func(string) string, doc Document) {
+	top := pdf.GetY()
+	recipient := t("Billed to")
+	if doc.Kind == KindReceipt {
+		recipient = t("Received from")
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.CellFormat(bodyWidth/2, 5, tr(recipient), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(40, 40, 40)
+	for i, line := range doc.BillTo {
+		style := ""
+		if i == 0 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.MultiCell(bodyWidth/2, 5, tr(line), "", "L", false)
+	}
+	leftBottom := pdf.GetY()
+
+	rows := [][2]string{
+		{t("Number"), doc.Number},
+		{t("Date"), formatDate(doc.IssuedAt)},
+	}
+	if !doc.DueDate.IsZero() {
+		rows = append(rows, [2]string{t("Due date"), formatDate(doc.DueDate)})
+	}
+	if doc.Status != "" {
+		rows = append(rows, [2]string{t("Status"), doc.Status})
+	}
+	pdf.SetY(top)
+	for _, row := range rows {
+		pdf.SetX(margin + bodyWidth/2)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(bodyWidth/4, 5, tr(row[0]), "", 0, "R", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetTextColor(40, 40, 40)
+		pdf.CellFormat(bodyWidth/4, 5, tr(row[1]), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetY(math.Max(leftBottom, pdf.GetY()))
+	pdf.Ln(6)
+}
+
+// writeLines menulis tabel rincian biaya beserta total, jumlah dibayar dan sisa
+func writeLines(pdf *fpdf.Fpdf, tr func(string) string, t func(string) string, doc Document) {
+	amountWidth := 45.0
+	descWidth := bodyWidth - amountWidth
+
+	tableHeader(pdf, tr, []string{t("Description"), t("Amount")}, []float64{descWidth, amountWidth}, []string{"L", "R"})
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range doc.Lines {
+		pdf.CellFormat(descWidth, 7, fit(pdf, tr(line.Description), descWidth-2), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(amountWidth, 7, tr(formatRupiah(line.Amount)), "B", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	totals := [][2]string{
+		{t("Total"), formatRupiah(doc.Total)},
+		{t("Paid"), formatRupiah(doc.Paid)},
+		{t("Outstanding"), formatRupiah(doc.Outstanding)},
+	}
+	for i, row := range totals {
+		style := ""
+		if i == len(totals)-1 {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.SetX(margin + descWidth - amountWidth)
+		pdf.CellFormat(amountWidth, 6, tr(row[0]), "", 0, "R", false, 0, "")
+		pdf.CellFormat(amountWidth, 6, tr(row[1]), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+}
+
+// writePayments menulis riwayat pembayaran
+func writePayments(pdf *fpdf.Fpdf, tr func(string) string, t func(string) string, payments []Payment) {
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(bodyWidth, 7, tr(t("Payment history")), "", 1, "L", false, 0, "")
+	if len(payments) == 0 {
+		pdf.SetFont("Helvetica", "I", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(bodyWidth, 6, tr(t("No payments recorded")), "", 1, "L", false, 0, "")
+		pdf.Ln(6)
+		return
+	}
+
+	widths := []float64{30, 30, 30, bodyWidth - 130, 40}
+	aligns := []string{"L", "L", "L", "L", "R"}
+	tableHeader(pdf, tr, []string{t("Date"), t("Type"), t("Method"), t("Reference"), t("Amount")}, widths, aligns)
+	pdf.SetFont("Helvetica", "", 9)
+	kinds := map[string]string{"payment": t("Payment"), "refund": t("Refund"), "void": t("Void")}
+	for _, p := range payments {
+		kind := kinds[p.Kind]
+		if kind == "" {
+			kind = p.Kind
+		}
+		cells := []string{formatDate(p.Date), kind, p.Method, p.Reference, formatRupiah(p.Amount)}
+		for i, cell := range cells {
+			ln := 0
+			if i == len(cells)-1 {
+				ln = 1
+			}
+			pdf.CellFormat(widths[i], 6, fit(pdf, tr(cell), widths[i]-2), "B", ln, aligns[i], false, 0, "")
+		}
+	}
+	pdf.Ln(6)
+}
+
+// writeQRCode menulis QR code berisi link verifikasi beserta link-nya dalam teks
+func writeQRCode(pdf *fpdf.Fpdf, tr func(string) string, t func(string) string, url string) error {
+	if url == "" {
+		return nil
+	}
+	png, err := qrcode.Encode(url, qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("encode qr code: %w", err)
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+qrSize > pageHeight-margin-5 {
+		pdf.AddPage()
+	}
+	top := pdf.GetY()
+	pdf.RegisterImageOptionsReader("verify-qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+	pdf.ImageOptions("verify-qr", margin, top, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, url)
+
+	pdf.SetXY(margin+qrSize+4, top+8)
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetTextColor(40, 40, 40)
+	pdf.CellFormat(bodyWidth-qrSize-4, 5, tr(t("Scan to verify this document")), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(bodyWidth-qrSize-4, 4, tr(url), "", "L", false)
+	pdf.SetY(top + qrSize)
+	return pdf.Error()
+}
+
+// tableHeader menulis baris judul tabel dengan latar abu-abu
+func tableHeader(pdf *fpdf.Fpdf, tr func(string) string, titles []string, widths []float64, aligns []string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetTextColor(40, 40, 40)
+	for i, title := range titles {
+		ln := 0
+		if i == len(titles)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(widths[i], 7, tr(title), "", ln, aligns[i], true, 0, "")
+	}
+}
+
+// fit memotong teks dengan "..." agar muat di lebar w (mm) dengan font aktif
+func fit(pdf *fpdf.Fpdf, s string, w float64) string {
+	if pdf.GetStringWidth(s) <= w {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > w {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
+
+// formatDate memformat tanggal seperti di dokumen cetak, contoh 17/10/2026
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02/01/2006")
+}
+
+// formatRupiah memformat nominal dengan pemisah ribuan titik, contoh
+// Rp 1.250.000 atau -Rp 50.000,50
+func formatRupiah(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	digits := fmt.Sprint(cents / 100)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(d)
+	}
+
+	s := "Rp " + grouped.String()
+	if cents%100 != 0 {
+		s += fmt.Sprintf(",%02d", cents%100)
+	}
+	if amount < 0 && cents != 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// joinNonEmpty menggabungkan bagian yang tidak kosong
+func joinNonEmpty(sep string, parts ...string) string {
+	nonEmpty := parts[:0:0]
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
@@ -2,42 +2,287 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/config"
+	"github.com/organisasi/tubesbackend/migrations"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/routes"
+	"github.com/organisasi/tubesbackend/service"
+	"github.com/organisasi/tubesbackend/utils"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"github.com/organisasi/tubesbackend/routes"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-var DB *mongo.Database
+const usage = `Usage:
+  tubesbackend [serve]          Menjalankan HTTP server
+  tubesbackend migrate [up]     Menjalankan migration yang tertunda
+  tubesbackend migrate status   Menampilkan status semua migration`
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found")
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
 	}
+}
+
+// run memilih perintah berdasarkan argumen; tanpa argumen server dijalankan
+func run(args []string) error {
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "serve":
+		return serve()
+	case "migrate":
+		action := "up"
+		if len(args) > 0 {
+			action = args[0]
+		}
+		if action != "up" && action != "status" {
+			return fmt.Errorf("unknown migrate action %q\n%s", action, usage)
+		}
+		return migrate(action)
+	}
+	return fmt.Errorf("unknown command %q\n%s", command, usage)
+}
 
-	mongoURI := os.Getenv("MONGOSTRING")
-	client, err := mongo.NewClient(options.Client().ApplyURI(mongoURI))
+// serve menjalankan seluruh siklus hidup server: konfigurasi, koneksi database,
+// migration, HTTP server, lalu shutdown yang rapi saat menerima SIGINT/SIGTERM
+func serve() error {
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	log.Printf("Loaded %s configuration", cfg.Env)
+
+	// Terapkan konfigurasi JWT sebelum ada token yang dibuat
+	keySet, err := utils.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.Secret, cfg.JWT.ActiveKID)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+	utils.SetKeySet(keySet)
+	utils.AccessTokenTTL = cfg.JWT.AccessTokenTTL.Duration
+	utils.RefreshTokenTTL = cfg.JWT.RefreshTokenTTL.Duration
+
+	if cfg.Env != config.EnvDevelopment {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := connectMongo(ctx, cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+		defer cancel()
+		if err := client.Disconnect(disconnectCtx); err != nil {
+			log.Printf("Failed to disconnect MongoDB: %v", err)
+		}
+		log.Println("Disconnected from MongoDB")
+	}()
+
+	// Job berkala dihentikan dan ditunggu selesai sebelum koneksi MongoDB ditutup
+	// (defer dijalankan terbalik), agar tidak ada job yang masih menulis
+	var jobs sync.WaitGroup
+	startJob := func(name string, interval time.Duration, fn func(ctx context.Context) error) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			service.Every(ctx, name, interval, fn)
+		}()
+	}
+	defer func() {
+		stop()
+		if !waitTimeout(&jobs, cfg.Server.ShutdownTimeout.Duration) {
+			log.Println("Background jobs did not stop before the shutdown timeout")
+		}
+	}()
+
+	db := client.Database(cfg.Mongo.Database)
+
+	// Dengan auto_migrate=false migration dijalankan terpisah lewat "migrate up",
+	// misalnya sebagai langkah deploy sebelum server baru dinyalakan
+	if cfg.Mongo.AutoMigrate {
+		if err := applyMigrations(ctx, db); err != nil {
+			return err
+		}
+	} else if pending, err := migrations.Pending(ctx, db); err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	} else if len(pending) > 0 {
+		log.Printf("Warning: %d pending migrations, run \"migrate up\"", len(pending))
+	}
+
+	// Data di trash dihapus permanen setelah masa retensi; retention 0 mematikan job ini
+	if cfg.Trash.Retention.Duration > 0 {
+		repos := repository.NewMongoRepositories(db)
+		retention := cfg.Trash.Retention.Duration
+		startJob("purge_trash", cfg.Trash.PurgeInterval.Duration, func(ctx context.Context) error {
+			return service.PurgeTrash(ctx, repos, retention)
+		})
+	}
+
+	billing := service.NewBillingService(repository.NewMongoRepositories(db))
+	// Tagihan cicilan dan bulanan diterbitkan saat tanggal terbit siklusnya tiba
+	if cfg.Billing.IssueInterval.Duration > 0 {
+		startJob("issue_scheduled_tagihan", cfg.Billing.IssueInterval.Duration, billing.IssueDueTagihan)
+	}
+	// Tagihan yang lewat jatuh tempo menjadi Terlambat dan dikenakan denda sesuai konfigurasi
+	if cfg.Billing.OverdueInterval.Duration > 0 {
+		fee := cfg.Billing.LateFee
+		policy := service.LateFeePolicy{
+			Flat:       fee.Flat,
+			Percent:    fee.Percent,
+			GraceDays:  fee.GraceDays,
+			RepeatDays: fee.RepeatDays,
+			MaxFees:    fee.MaxFees,
+		}
+		startJob("mark_overdue_tagihan", cfg.Billing.OverdueInterval.Duration, func(ctx context.Context) error {
+			return billing.MarkOverdue(ctx, policy)
+		})
+	}
+
+	srv := &http.Server{
+		Addr:         cfg.Server.ListenAddr,
+		Handler:      routes.SetupRoutes(db, cfg, newMailer(cfg.Mail)),
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration,
+		WriteTimeout: cfg.Server.WriteTimeout.Duration,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", cfg.Server.ListenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+		log.Println("Shutting down server...")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Beri waktu request yang sedang berjalan untuk selesai
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
 	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+	log.Println("Server stopped")
+	return nil
+}
 
-	if err := client.Connect(ctx); err != nil {
-		log.Fatal(err)
+// newMailer memilih implementasi mailer sesuai konfigurasi ("smtp" atau "log")
+func newMailer(cfg config.MailConfig) utils.Mailer {
+	if cfg.Driver == "smtp" {
+		return &utils.SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}
 	}
+	return &utils.LogMailer{Path: cfg.LogFile}
+}
 
-	DB = client.Database("tubesbackend")
-	log.Println("Connected to MongoDB")
+// waitTimeout menunggu wg paling lama timeout; false jika waktunya habis
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
 
-func main() {
-	router := routes.SetupRoutes(DB)
-	log.Fatal(router.Run(":8080")) // Run server on port 8080
+// migrate menjalankan perintah "migrate up" atau "migrate status" lalu keluar
+func migrate(action string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := connectMongo(ctx, cfg.Mongo)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+	db := client.Database(cfg.Mongo.Database)
+
+	if action == "up" {
+		return applyMigrations(ctx, db)
+	}
+
+	statuses, err := migrations.StatusOf(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.Applied != nil {
+			applied = s.Applied.AppliedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
+
+// applyMigrations menjalankan migration yang tertunda dan mencatat hasilnya di log
+func applyMigrations(ctx context.Context, db *mongo.Database) error {
+	n, err := migrations.Up(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	if n > 0 {
+		log.Printf("Applied %d migrations", n)
+	} else {
+		log.Println("Database schema is up to date")
+	}
+	return nil
 }
 
+// connectMongo membuka koneksi MongoDB dan memastikan server bisa dijangkau
+func connectMongo(ctx context.Context, cfg config.MongoConfig) (*mongo.Client, error) {
+	connectCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout.Duration)
+	defer cancel()
+
+	client, err := mongo.Connect(connectCtx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(connectCtx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	log.Println("Connected to MongoDB")
+	return client, nil
+}
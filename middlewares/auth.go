@@ -7,11 +7,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
 	"github.com/organisasi/tubesbackend/models"
 	"github.com/organisasi/tubesbackend/utils"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func AuthMiddleware(db *mongo.Database) gin.HandlerFunc {
@@ -19,16 +20,14 @@ func AuthMiddleware(db *mongo.Database) gin.HandlerFunc {
 		// Ambil token dari header Authorization
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token required"})
-			c.Abort()
+			abortWithError(c, apperror.Unauthorized("Token required"))
 			return
 		}
 
 		// Format Authorization: "Bearer <token>", kita ambil bagian setelah "Bearer "
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-			c.Abort()
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid token format"))
 			return
 		}
 
@@ -37,43 +36,96 @@ func AuthMiddleware(db *mongo.Database) gin.HandlerFunc {
 		// Verifikasi token JWT
 		claims, err := utils.VerifyJWT(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid token"))
 			return
 		}
 
 		// Ambil UserID dari token
 		userID, ok := claims["user_id"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid token claims"))
+			return
+		}
+
+		// Ambil jti sesi dari token
+		jti, ok := claims["jti"].(string)
+		if !ok || jti == "" {
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Invalid token claims"))
 			return
 		}
 
-		// Ambil user dari database
-		userCollection := db.Collection("users")
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		// Pastikan sesi masih ada dan belum dicabut (logout / dinonaktifkan admin)
+		var session models.Session
+		err = db.Collection("sessions").FindOne(ctx, bson.M{"jti": jti}).Decode(&session)
+		if err != nil || session.RevokedAt != nil {
+			abortWithError(c, apperror.New(http.StatusUnauthorized, apperror.CodeInvalidToken, "Session has been revoked"))
+			return
+		}
+
+		// Ambil user dari database
+		userCollection := db.Collection("users")
+
 		var user models.User
 		objID, _ := primitive.ObjectIDFromHex(userID)
-		err = userCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&user)
+		err = userCollection.FindOne(ctx, bson.M{"_id": objID, "deleted_at": nil}).Decode(&user)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+			abortWithError(c, apperror.Unauthorized("User not found"))
 			return
 		}
 
 		// Cek apakah user aktif
 		if strings.ToLower(user.Status) != "active" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "User is not active"})
-			c.Abort()
+			abortWithError(c, apperror.New(http.StatusForbidden, apperror.CodeAccountInactive, "User is not active"))
 			return
 		}
 
 		// Simpan user_id di context agar bisa dipakai di controller
 		c.Set("user_id", userID)
+		c.Set("jti", jti)
 		c.Set("user", user)
 		c.Next()
 	}
 }
+
+// TwoFactorSetupMiddleware dipakai untuk route pendaftaran 2FA. Selain access
+// token biasa, route ini juga menerima token tantangan "2fa_setup" yang
+// diberikan Login kepada user yang role-nya wajib 2FA tetapi belum mendaftar.
+func TwoFactorSetupMiddleware(db *mongo.Database) gin.HandlerFunc {
+	authMiddleware := AuthMiddleware(db)
+
+	return func(c *gin.Context) {
+		tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(tokenParts) == 2 && tokenParts[0] == "Bearer" {
+			claims, err := utils.VerifyJWT(tokenParts[1])
+			if err == nil && claims["purpose"] == "2fa_setup" {
+				userID, _ := claims["user_id"].(string)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+
+				var user models.User
+				objID, _ := primitive.ObjectIDFromHex(userID)
+				if err := db.Collection("users").FindOne(ctx, bson.M{"_id": objID, "deleted_at": nil}).Decode(&user); err != nil {
+					abortWithError(c, apperror.Unauthorized("User not found"))
+					return
+				}
+				if strings.ToLower(user.Status) != "active" {
+					abortWithError(c, apperror.New(http.StatusForbidden, apperror.CodeAccountInactive, "User is not active"))
+					return
+				}
+
+				c.Set("user_id", userID)
+				c.Set("user", user)
+				c.Set("two_factor_setup", true)
+				c.Next()
+				return
+			}
+		}
+
+		// Selain token setup, perlakukan seperti route terproteksi biasa
+		authMiddleware(c)
+	}
+}
@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/service"
+	"github.com/organisasi/tubesbackend/utils"
+	"github.com/organisasi/tubesbackend/validation"
+)
+
+// RequestIDHeader adalah header yang membawa ID request dari client/proxy dan kembali ke client
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID memberi setiap request ID unik (atau memakai ID dari header) untuk korelasi log
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 64 {
+			id, _ = utils.GenerateRandomToken(8)
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// errorBody adalah bentuk standar response error
+type errorBody struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// ErrorHandler mengubah error yang dicatat handler lewat c.Error menjadi response
+// {"error": {code, message, details, request_id}}. Panic juga ditangkap di sini.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[%s] panic: %v", c.GetString("request_id"), r)
+				writeError(c, apperror.Internal("Internal server error", nil))
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeError(c, toAppError(c, c.Errors.Last().Err))
+	}
+}
+
+// toAppError memetakan error dari handler, service dan repository ke apperror.Error
+func toAppError(c *gin.Context, err error) *apperror.Error {
+	var appErr *apperror.Error
+	var notFound *service.NotFoundError
+	var invalid *service.ValidationError
+	var conflict *service.ConflictError
+
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.As(err, &notFound):
+		return apperror.NotFound(notFound.Error())
+	case errors.As(err, &invalid):
+		e := apperror.Validation(invalid.Error())
+		if invalid.Field != "" {
+			// Bentuk details sama dengan error validasi request agar form bisa menandai field-nya
+			e = e.WithDetails(gin.H{"fields": []validation.FieldError{{
+				Field:   invalid.Field,
+				Rule:    "invalid",
+				Message: i18n.T(c, invalid.Error()),
+			}}})
+		}
+		return e
+	case errors.As(err, &conflict):
+		return apperror.Conflict(conflict.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return apperror.NotFound("Resource not found")
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return apperror.Validation("Some fields are invalid").WithDetails(gin.H{"fields": []validation.FieldError{{
+			Field:   "cursor",
+			Rule:    "cursor",
+			Message: i18n.T(c, "validation.cursor"),
+		}}})
+	default:
+		return apperror.Internal("Internal server error", err)
+	}
+}
+
+func writeError(c *gin.Context, e *apperror.Error) {
+	requestID := c.GetString("request_id")
+	if e.Status >= http.StatusInternalServerError {
+		log.Printf("[%s] %s %s: %v", requestID, c.Request.Method, c.Request.URL.Path, e)
+	}
+	c.AbortWithStatusJSON(e.Status, gin.H{"error": errorBody{
+		Code:      e.Code,
+		Message:   i18n.T(c, e.Message),
+		Details:   e.Details,
+		RequestID: requestID,
+	}})
+}
+
+// abortWithError mencatat error untuk ErrorHandler dan menghentikan rantai handler
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/apperror"
+	"github.com/organisasi/tubesbackend/models"
+)
+
+// Policies adalah tabel role -> daftar permission yang diizinkan.
+// Permission ditulis "resource:action", contoh "siswa:create", dan wildcard
+// "*" boleh dipakai di resource maupun action. Action "read_own" berarti hanya
+// boleh membaca data milik sendiri. Role yang tidak ada di tabel ini tidak
+// punya akses apa pun.
+var Policies = map[string][]string{
+	// Admin memegang semua permission, termasuk pengelolaan akun:
+	// users:update (status, role, unlock, review), users:delete,
+	// users:restore dan users:link (tautan ke siswa/guru)
+	"admin": {"*:*"},
+	"staff": {
+		"users:read",
+		"siswa:*",
+		"guru:*",
+		"tagihan:*",
+		"transaksi_siswa:*",
+		"transaksi_guru:read",
+		"course:*",
+		"schedule:*",
+		"registration:*",
+	},
+	"guru": {
+		"siswa:read",
+		"course:read",
+		"schedule:read",
+		"schedule:update",
+		"transaksi_guru:read_own",
+	},
+	"siswa": {
+		"course:read",
+		"schedule:read",
+		"tagihan:read_own",
+	},
+	"user": {
+		"course:read",
+		"schedule:read",
+		"tagihan:read_own",
+	},
+}
+
+// HasPermission mengecek apakah role boleh melakukan action pada resource
+func HasPermission(role, resource, action string) bool {
+	for _, perm := range Policies[strings.ToLower(role)] {
+		parts := strings.SplitN(perm, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if (parts[0] == "*" || parts[0] == resource) && (parts[1] == "*" || parts[1] == action) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentUser mengambil user yang sudah disimpan AuthMiddleware di context
+func currentUser(c *gin.Context) (models.User, bool) {
+	authUser, exists := c.Get("user")
+	if !exists {
+		return models.User{}, false
+	}
+	user, ok := authUser.(models.User)
+	return user, ok
+}
+
+// RequireRole hanya meloloskan user dengan salah satu role yang disebutkan.
+// Harus dipasang setelah AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c)
+		if !ok {
+			abortWithError(c, apperror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		for _, role := range roles {
+			if strings.EqualFold(user.Role, role) {
+				c.Next()
+				return
+			}
+		}
+
+		abortWithError(c, apperror.Forbidden("Access denied"))
+	}
+}
+
+// RequirePermission meloloskan user jika role-nya punya permission
+// resource:action menurut tabel Policies. Harus dipasang setelah AuthMiddleware.
+func RequirePermission(resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c)
+		if !ok {
+			abortWithError(c, apperror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		if !HasPermission(user.Role, resource, action) {
+			abortWithError(c, apperror.Forbidden("Access denied"))
+			return
+		}
+
+		c.Next()
+	}
+}
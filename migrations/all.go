@@ -0,0 +1,368 @@
+package migrations
+
+import (
+	"context"
+	"log"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// All adalah daftar semua migration, urut berdasarkan versi
+var All = []Migration{
+	{Version: 1, Name: "normalize_status_values", Up: normalizeStatusValues},
+	{Version: 2, Name: "user_indexes", Up: userIndexes},
+	{Version: 3, Name: "auth_indexes", Up: authIndexes},
+	{Version: 4, Name: "domain_indexes", Up: domainIndexes},
+	{Version: 5, Name: "search_text_indexes", Up: repository.EnsureSearchIndexes},
+	{Version: 6, Name: "backfill_user_links", Up: backfillUserLinks},
+	{Version: 7, Name: "trash_indexes", Up: trashIndexes},
+	{Version: 8, Name: "audit_log_indexes", Up: auditLogIndexes},
+	{Version: 9, Name: "payment_ledger", Up: paymentLedger},
+	{Version: 10, Name: "billing_schedule_indexes", Up: billingScheduleIndexes},
+	{Version: 11, Name: "document_indexes", Up: documentIndexes},
+	{Version: 12, Name: "unique_schedule_cycle", Up: uniqueScheduleCycle},
+	{Version: 13, Name: "payment_seq_index", Up: paymentSeqIndex},
+	{Version: 14, Name: "partial_document_number_index", Up: partialDocumentNumberIndex},
+}
+
+// normalizeStatusValues mengubah status berupa teks bahasa Indonesia di data lama menjadi kode status
+func normalizeStatusValues(ctx context.Context, db *mongo.Database) error {
+	n, err := repository.NormalizeStatuses(ctx, db)
+	if n > 0 {
+		log.Printf("Normalized %d legacy status values", n)
+	}
+	return err
+}
+
+// userIndexes menjaga username dan email tetap unik di level database, sehingga
+// dua registrasi bersamaan tidak bisa lolos pengecekan CountDocuments
+func userIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "users",
+		index{name: "username_unique", keys: bson.D{{Key: "username", Value: 1}}, unique: true},
+		// Akun lama mungkin belum punya email
+		index{
+			name:    "email_unique",
+			keys:    bson.D{{Key: "email", Value: 1}},
+			unique:  true,
+			partial: bson.M{"email": bson.M{"$type": "string"}},
+		},
+		index{name: "role_status", keys: bson.D{{Key: "role", Value: 1}, {Key: "status", Value: 1}}},
+		index{name: "created_at", keys: bson.D{{Key: "created_at", Value: -1}}},
+		index{name: "siswa_id", keys: bson.D{{Key: "siswa_id", Value: 1}}, partial: bson.M{"siswa_id": bson.M{"$exists": true}}},
+		index{name: "guru_id", keys: bson.D{{Key: "guru_id", Value: 1}}, partial: bson.M{"guru_id": bson.M{"$exists": true}}},
+	)
+}
+
+// authIndexes mempercepat pencarian sesi dan token, dan membiarkan MongoDB
+// menghapus sesi serta token yang sudah kedaluwarsa
+func authIndexes(ctx context.Context, db *mongo.Database) error {
+	err := createIndexes(ctx, db, "sessions",
+		index{name: "jti_unique", keys: bson.D{{Key: "jti", Value: 1}}, unique: true},
+		index{name: "refresh_token_hash", keys: bson.D{{Key: "refresh_token_hash", Value: 1}}},
+		index{name: "user_id", keys: bson.D{{Key: "user_id", Value: 1}}},
+		index{name: "expires_at_ttl", keys: bson.D{{Key: "expires_at", Value: 1}}, ttl: true},
+	)
+	if err != nil {
+		return err
+	}
+	return createIndexes(ctx, db, "auth_tokens",
+		index{name: "token_hash_unique", keys: bson.D{{Key: "token_hash", Value: 1}}, unique: true},
+		index{name: "user_id_purpose", keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}},
+		index{name: "expires_at_ttl", keys: bson.D{{Key: "expires_at", Value: 1}}, ttl: true},
+	)
+}
+
+// domainIndexes membuat index untuk filter dan urutan yang dipakai endpoint daftar
+func domainIndexes(ctx context.Context, db *mongo.Database) error {
+	collections := map[string][]index{
+		"tagihans": {
+			{name: "siswa_id_created_at", keys: bson.D{{Key: "siswa_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{name: "siswa_email", keys: bson.D{{Key: "siswa_email", Value: 1}}},
+			{name: "course_id", keys: bson.D{{Key: "course_id", Value: 1}}},
+			{name: "status_due_date", keys: bson.D{{Key: "status", Value: 1}, {Key: "due_date", Value: 1}}},
+		},
+		"transaksi_siswa": {
+			{name: "siswa_id_tanggal", keys: bson.D{{Key: "siswa_id", Value: 1}, {Key: "tanggal", Value: -1}}},
+		},
+		"transaksi_guru": {
+			{name: "guru_id", keys: bson.D{{Key: "guru_id", Value: 1}}},
+		},
+		"course_schedules": {
+			{name: "courseId_unique", keys: bson.D{{Key: "courseId", Value: 1}}, unique: true},
+		},
+		"siswa": {
+			{name: "email", keys: bson.D{{Key: "email", Value: 1}}},
+			{name: "status_fullname", keys: bson.D{{Key: "status", Value: 1}, {Key: "fullname", Value: 1}}},
+		},
+		"gurus": {
+			{name: "email", keys: bson.D{{Key: "email", Value: 1}}},
+			{name: "status_fullname", keys: bson.D{{Key: "status", Value: 1}, {Key: "fullname", Value: 1}}},
+		},
+		"courses": {
+			{name: "name", keys: bson.D{{Key: "name", Value: 1}}},
+		},
+		"course_registrations": {
+			{name: "courseId_status", keys: bson.D{{Key: "courseId", Value: 1}, {Key: "status", Value: 1}}},
+			{name: "email", keys: bson.D{{Key: "email", Value: 1}}},
+		},
+	}
+	for name, indexes := range collections {
+		if err := createIndexes(ctx, db, name, indexes...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillUserLinks menghubungkan akun user ke data siswa/guru dengan email
+// yang sama, jika akun belum terhubung. Email yang dipakai lebih dari satu
+// data siswa/guru dilewati karena tidak jelas mana yang benar; admin bisa
+// menghubungkannya manual lewat endpoint link.
+func backfillUserLinks(ctx context.Context, db *mongo.Database) error {
+	targets := []struct{ field, collection string }{
+		{"siswa_id", "siswa"},
+		{"guru_id", "gurus"},
+	}
+	users := db.Collection("users")
+	for _, t := range targets {
+		cursor, err := db.Collection(t.collection).Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"email": bson.M{"$type": "string", "$ne": ""}}}},
+			{{Key: "$group", Value: bson.M{"_id": "$email", "ids": bson.M{"$push": "$_id"}}}},
+			{{Key: "$match", Value: bson.M{"ids": bson.M{"$size": 1}}}},
+		})
+		if err != nil {
+			return err
+		}
+		var candidates []struct {
+			Email string        `bson:"_id"`
+			IDs   []interface{} `bson:"ids"`
+		}
+		if err := cursor.All(ctx, &candidates); err != nil {
+			return err
+		}
+
+		var linked int64
+		for _, cand := range candidates {
+			targetID := cand.IDs[0]
+			// Satu data siswa/guru hanya boleh terhubung ke satu akun
+			taken, err := users.CountDocuments(ctx, bson.M{t.field: targetID, "deleted_at": nil})
+			if err != nil {
+				return err
+			}
+			if taken > 0 {
+				continue
+			}
+			result, err := users.UpdateOne(ctx,
+				bson.M{"email": cand.Email, t.field: bson.M{"$exists": false}, "deleted_at": nil},
+				bson.M{"$set": bson.M{t.field: targetID}},
+			)
+			if err != nil {
+				return err
+			}
+			linked += result.ModifiedCount
+		}
+		if linked > 0 {
+			log.Printf("Linked %d users to %s by email", linked, t.collection)
+		}
+	}
+	return nil
+}
+
+// trashIndexes mempercepat endpoint trash dan job purge; hanya dokumen yang
+// sudah dihapus yang masuk index sehingga ukurannya tetap kecil
+func trashIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, name := range []string{"siswa", "transaksi_siswa", "gurus", "transaksi_guru", "tagihans", "courses"} {
+		err := createIndexes(ctx, db, name, index{
+			name:    "deleted_at",
+			keys:    bson.D{{Key: "deleted_at", Value: -1}},
+			partial: bson.M{"deleted_at": bson.M{"$exists": true}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditLogIndexes mendukung filter GET /audit yang paling sering dipakai:
+// riwayat satu data dan aktivitas satu user, terbaru lebih dulu
+func auditLogIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "audit_log",
+		index{name: "created_at", keys: bson.D{{Key: "created_at", Value: -1}}},
+		index{name: "entity_entity_id_created_at", keys: bson.D{{Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		index{name: "actor_id_created_at", keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	)
+}
+
+// paymentLedger membuat index koleksi payments lalu mengisi ledger untuk
+// tagihan lama. Tagihan yang sudah lunas mendapat satu entri payment dengan
+// method legacy sebesar nominal tagihan, sehingga saldonya tetap lunas saat
+// dihitung ulang dari ledger.
+func paymentLedger(ctx context.Context, db *mongo.Database) error {
+	err := createIndexes(ctx, db, "payments",
+		index{name: "tagihan_id_created_at", keys: bson.D{{Key: "tagihan_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		// Bukti transfer yang sama tidak boleh dicatat dua kali di satu tagihan
+		index{
+			name:    "tagihan_id_reference_unique",
+			keys:    bson.D{{Key: "tagihan_id", Value: 1}, {Key: "reference", Value: 1}},
+			unique:  true,
+			partial: bson.M{"kind": models.PaymentKindPayment, "reference": bson.M{"$gt": ""}},
+		},
+		// Satu entri hanya bisa di-void sekali
+		index{
+			name:    "void_of_unique",
+			keys:    bson.D{{Key: "void_of", Value: 1}},
+			unique:  true,
+			partial: bson.M{"void_of": bson.M{"$exists": true}},
+		},
+		index{name: "received_by", keys: bson.D{{Key: "received_by", Value: 1}}, partial: bson.M{"received_by": bson.M{"$exists": true}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	tagihans := db.Collection("tagihans")
+	payments := db.Collection("payments")
+	cursor, err := tagihans.Find(ctx, bson.M{"ledger_count": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var backfilled int64
+	for cursor.Next(ctx) {
+		var tagihan models.Tagihan
+		if err := cursor.Decode(&tagihan); err != nil {
+			return err
+		}
+
+		set := bson.M{"paid_amount": 0.0, "outstanding": tagihan.Amount, "ledger_count": 0}
+		if tagihan.Status == models.TagihanStatusPaid {
+			// Entri mungkin sudah dibuat jika migration sebelumnya berhenti di tengah jalan
+			n, err := payments.CountDocuments(ctx, bson.M{"tagihan_id": tagihan.ID})
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				receivedAt := tagihan.UpdatedAt
+				if tagihan.PaidAt != nil {
+					receivedAt = *tagihan.PaidAt
+				}
+				_, err := payments.InsertOne(ctx, models.Payment{
+					ID:         primitive.NewObjectID(),
+					TagihanID:  tagihan.ID,
+					Kind:       models.PaymentKindPayment,
+					Amount:     tagihan.Amount,
+					Method:     models.PaymentMethodLegacy,
+					Notes:      "Dicatat otomatis dari status lunas sebelum ledger pembayaran ada",
+					ReceivedAt: receivedAt,
+					CreatedAt:  receivedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			set = bson.M{"paid_amount": tagihan.Amount, "outstanding": 0.0, "ledger_count": 1}
+		}
+		if _, err := tagihans.UpdateOne(ctx, bson.M{"_id": tagihan.ID}, bson.M{"$set": set}); err != nil {
+			return err
+		}
+		backfilled++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if backfilled > 0 {
+		log.Printf("Backfilled payment ledger for %d tagihan", backfilled)
+	}
+	return nil
+}
+
+// billingScheduleIndexes mendukung scheduler penagihan dan mencegah siswa
+// punya dua jadwal aktif untuk kursus yang sama
+func billingScheduleIndexes(ctx context.Context, db *mongo.Database) error {
+	err := createIndexes(ctx, db, "billing_schedules",
+		index{
+			name:    "status_next_issue_at",
+			keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_issue_at", Value: 1}},
+			partial: bson.M{"status": models.BillingScheduleActive},
+		},
+		index{
+			name:    "siswa_id_course_id_active_unique",
+			keys:    bson.D{{Key: "siswa_id", Value: 1}, {Key: "course_id", Value: 1}},
+			unique:  true,
+			partial: bson.M{"status": models.BillingScheduleActive},
+		},
+		index{name: "siswa_id_created_at", keys: bson.D{{Key: "siswa_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	)
+	if err != nil {
+		return err
+	}
+	return createIndexes(ctx, db, "tagihans", index{
+		name:    "schedule_id_cycle",
+		keys:    bson.D{{Key: "schedule_id", Value: 1}, {Key: "cycle", Value: 1}},
+		partial: bson.M{"schedule_id": bson.M{"$exists": true}},
+	})
+}
+
+// documentIndexes menjaga satu nomor per jenis dokumen per entitas dan nomor
+// serta kode verifikasi tetap unik, sehingga dua cetak bersamaan tidak
+// menghasilkan dua nomor untuk tagihan yang sama
+func documentIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "documents",
+		index{
+			name:   "kind_entity_entity_id_unique",
+			keys:   bson.D{{Key: "kind", Value: 1}, {Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}},
+			unique: true,
+		},
+		index{name: "number_unique", keys: bson.D{{Key: "number", Value: 1}}, unique: true},
+		index{name: "code_unique", keys: bson.D{{Key: "code", Value: 1}}, unique: true},
+	)
+}
+
+// uniqueScheduleCycle mengganti index schedule_id_cycle di tagihans dengan
+// index unik, sehingga satu siklus jadwal tidak bisa punya dua tagihan walaupun
+// klaim siklusnya terlewati
+func uniqueScheduleCycle(ctx context.Context, db *mongo.Database) error {
+	err := createIndexes(ctx, db, "tagihans", index{
+		name:    "schedule_id_cycle_unique",
+		keys:    bson.D{{Key: "schedule_id", Value: 1}, {Key: "cycle", Value: 1}},
+		unique:  true,
+		partial: bson.M{"schedule_id": bson.M{"$exists": true}},
+	})
+	if err != nil {
+		return err
+	}
+	return dropIndex(ctx, db, "tagihans", "schedule_id_cycle")
+}
+
+// paymentSeqIndex menjaga nomor urut entri ledger unik per tagihan, sehingga
+// dua pembayaran atau refund bersamaan tidak bisa lolos pengecekan saldo yang
+// sama. Entri lama tanpa seq tidak ikut index.
+func paymentSeqIndex(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db, "payments", index{
+		name:    repository.PaymentSeqIndex,
+		keys:    bson.D{{Key: "tagihan_id", Value: 1}, {Key: "seq", Value: 1}},
+		unique:  true,
+		partial: bson.M{"seq": bson.M{"$exists": true}},
+	})
+}
+
+// partialDocumentNumberIndex membatasi index unik nomor dokumen pada dokumen
+// yang sudah bernomor. Dokumen disimpan tanpa nomor lebih dulu dan baru diberi
+// nomor oleh request yang berhasil menyimpannya.
+func partialDocumentNumberIndex(ctx context.Context, db *mongo.Database) error {
+	if err := dropIndex(ctx, db, "documents", "number_unique"); err != nil {
+		return err
+	}
+	return createIndexes(ctx, db, "documents", index{
+		name:    "number_unique",
+		keys:    bson.D{{Key: "number", Value: 1}},
+		unique:  true,
+		partial: bson.M{"number": bson.M{"$exists": true}},
+	})
+}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// index adalah satu index yang dibuat oleh migration. Nama ditulis eksplisit
+// supaya index bisa dikenali dan dihapus di migration berikutnya.
+type index struct {
+	name    string
+	keys    bson.D
+	unique  bool
+	ttl     bool   // Dokumen dihapus MongoDB saat waktu di field pertama terlewati
+	partial bson.M // Hanya dokumen yang cocok yang masuk index
+}
+
+// createIndexes membuat index di satu koleksi. Sebelum membuat index unik,
+// data dicek lebih dulu agar duplikat dilaporkan dengan jelas.
+func createIndexes(ctx context.Context, db *mongo.Database, collection string, indexes ...index) error {
+	coll := db.Collection(collection)
+	models := make([]mongo.IndexModel, 0, len(indexes))
+	for _, idx := range indexes {
+		opts := options.Index().SetName(idx.name)
+		if idx.unique {
+			if err := checkDuplicates(ctx, coll, idx); err != nil {
+				return err
+			}
+			opts.SetUnique(true)
+		}
+		if idx.ttl {
+			opts.SetExpireAfterSeconds(0)
+		}
+		if idx.partial != nil {
+			opts.SetPartialFilterExpression(idx.partial)
+		}
+		models = append(models, mongo.IndexModel{Keys: idx.keys, Options: opts})
+	}
+	if _, err := coll.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("create indexes on %s: %w", collection, err)
+	}
+	return nil
+}
+
+// checkDuplicates mengembalikan error berisi beberapa contoh nilai yang
+// dipakai lebih dari satu dokumen, supaya admin tahu data mana yang harus
+// dirapikan sebelum migration dijalankan ulang.
+func checkDuplicates(ctx context.Context, coll *mongo.Collection, idx index) error {
+	group := bson.M{}
+	for _, key := range idx.keys {
+		group[key.Key] = "$" + key.Key
+	}
+	pipeline := mongo.Pipeline{}
+	if idx.partial != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: idx.partial}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{"_id": group, "count": bson.M{"$sum": 1}}}},
+		bson.D{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+		bson.D{{Key: "$limit", Value: 5}},
+	)
+
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var dups []struct {
+		Key   bson.M `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &dups); err != nil {
+		return err
+	}
+	if len(dups) == 0 {
+		return nil
+	}
+	examples := make([]string, 0, len(dups))
+	for _, d := range dups {
+		examples = append(examples, fmt.Sprintf("%v (%d documents)", d.Key, d.Count))
+	}
+	return fmt.Errorf("cannot create unique index %s on %s, duplicate values: %s",
+		idx.name, coll.Name(), strings.Join(examples, ", "))
+}
+
+// dropIndex menghapus index bernama name. Index atau koleksi yang belum ada
+// tidak dianggap error, sehingga migration aman dijalankan di database baru.
+func dropIndex(ctx context.Context, db *mongo.Database, collection, name string) error {
+	_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27) {
+		// 26 NamespaceNotFound, 27 IndexNotFound
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("drop index %s on %s: %w", name, collection, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+// Package migrations menjalankan perubahan skema dan data MongoDB secara
+// berurutan. Setiap migration punya nomor versi tetap dan dicatat di koleksi
+// schema_migrations setelah berhasil, sehingga hanya dijalankan sekali.
+//
+// Migration baru ditambahkan di akhir daftar All dengan versi berikutnya.
+// Migration yang sudah dirilis tidak boleh diubah atau diurutkan ulang; buat
+// migration baru untuk memperbaikinya. Up sebaiknya aman diulang karena
+// proses bisa berhenti setelah Up selesai tetapi sebelum tercatat.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nama koleksi yang dipakai untuk mencatat migration dan lock-nya
+const (
+	historyCollection = "schema_migrations"
+	lockCollection    = "schema_migrations_lock"
+)
+
+// Lock dianggap kedaluwarsa setelah lockTTL, misalnya jika proses mati di tengah migration
+const (
+	lockTTL       = 10 * time.Minute
+	lockRetry     = 2 * time.Second
+	lockWaitLimit = time.Minute
+)
+
+// ErrLocked dikembalikan jika instance lain masih menjalankan migration
+var ErrLocked = errors.New("migrations: another instance is running migrations")
+
+// Migration adalah satu langkah perubahan skema atau data
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record adalah catatan migration yang sudah dijalankan di koleksi schema_migrations
+type Record struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	AppliedAt  time.Time `bson:"applied_at"`
+	DurationMS int64     `bson:"duration_ms"`
+}
+
+// Status adalah keadaan satu migration untuk perintah "migrate status"
+type Status struct {
+	Migration
+	Applied *Record
+}
+
+// Applied mengembalikan catatan migration yang sudah dijalankan, per versi
+func Applied(ctx context.Context, db *mongo.Database) (map[int]Record, error) {
+	cursor, err := db.Collection(historyCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]Record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// Pending mengembalikan migration di All yang belum dijalankan, sesuai urutan
+func Pending(ctx context.Context, db *mongo.Database) ([]Migration, error) {
+	applied, err := Applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range All {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// StatusOf mengembalikan semua migration beserta waktu dijalankannya
+func StatusOf(ctx context.Context, db *mongo.Database) ([]Status, error) {
+	applied, err := Applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(All))
+	for _, m := range All {
+		s := Status{Migration: m}
+		if r, ok := applied[m.Version]; ok {
+			s.Applied = &r
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan secara berurutan dan
+// mengembalikan jumlahnya. Hanya satu instance yang bisa menjalankan migration
+// pada satu waktu; instance lain menunggu sampai lock dilepas.
+func Up(ctx context.Context, db *mongo.Database) (int, error) {
+	release, err := acquireLock(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	// Dibaca setelah lock didapat karena instance lain mungkin baru saja selesai
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, m := range pending {
+		log.Printf("Applying migration %04d %s", m.Version, m.Name)
+		start := time.Now()
+		if err := m.Up(ctx, db); err != nil {
+			return i, fmt.Errorf("migration %04d %s: %w", m.Version, m.Name, err)
+		}
+		_, err := db.Collection(historyCollection).InsertOne(ctx, Record{
+			Version:    m.Version,
+			Name:       m.Name,
+			AppliedAt:  time.Now(),
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			return i, fmt.Errorf("record migration %04d: %w", m.Version, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// acquireLock mengambil lock migration dan mengembalikan fungsi untuk melepasnya.
+// Lock berupa satu dokumen; upsert gagal dengan duplicate key jika dokumen itu
+// sedang dipegang instance lain dan belum kedaluwarsa.
+func acquireLock(ctx context.Context, db *mongo.Database) (func(), error) {
+	coll := db.Collection(lockCollection)
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+	deadline := time.Now().Add(lockWaitLimit)
+
+	for {
+		now := time.Now()
+		_, err := coll.UpdateOne(ctx,
+			bson.M{"_id": "migrations", "$or": bson.A{
+				bson.M{"locked_until": bson.M{"$lt": now}},
+				bson.M{"locked_until": nil},
+			}},
+			bson.M{"$set": bson.M{"owner": owner, "locked_until": now.Add(lockTTL)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		if now.After(deadline) {
+			return nil, ErrLocked
+		}
+		log.Println("Waiting for another instance to finish migrations...")
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetry):
+		}
+	}
+
+	return func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := coll.UpdateOne(releaseCtx,
+			bson.M{"_id": "migrations", "owner": owner},
+			bson.M{"$set": bson.M{"locked_until": nil}},
+		)
+		if err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+)
+
+// AuditEntry adalah satu baris audit log (koleksi audit_log). Entri hanya
+// ditambahkan, tidak pernah diubah atau dihapus oleh aplikasi.
+type AuditEntry struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ActorID   *primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"` // Kosong untuk aksi sistem atau tanpa login
+	Action    string              `bson:"action" json:"action"`                         // Lihat AuditAction*
+	Entity    string              `bson:"entity" json:"entity"`                         // Nama resource, sama dengan nama di policy RBAC
+	EntityID  primitive.ObjectID  `bson:"entity_id" json:"entity_id"`
+	Changes   []AuditChange       `bson:"changes" json:"changes"`
+	IP        string              `bson:"ip,omitempty" json:"ip,omitempty"`
+	RequestID string              `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// AuditChange adalah perubahan satu field. Before kosong pada create, After
+// kosong pada delete.
+type AuditChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis rencana penagihan kursus
+const (
+	BillingPlanOneTime      = "one_time"     // Satu tagihan sebesar Course.Cost
+	BillingPlanMonthly      = "monthly"      // Course.Cost dibagi rata menjadi tagihan bulanan selama Course.Duration bulan
+	BillingPlanInstallments = "installments" // Course.Cost dibagi rata menjadi Installments cicilan bulanan
+)
+
+// Status jadwal tagihan
+const (
+	BillingScheduleActive    = "active"    // Masih ada siklus yang belum ditagihkan
+	BillingScheduleCompleted = "completed" // Semua siklus sudah ditagihkan
+	BillingScheduleCancelled = "cancelled" // Siklus yang tersisa tidak akan ditagihkan
+)
+
+// BillingPlan adalah cara penagihan sebuah kursus. Type kosong (kursus lama)
+// diperlakukan sebagai one_time.
+type BillingPlan struct {
+	Type         string `bson:"type,omitempty" json:"type,omitempty"`                 // Lihat BillingPlan*
+	Installments int    `bson:"installments,omitempty" json:"installments,omitempty"` // Jumlah cicilan, hanya untuk installments
+	DueDays      int    `bson:"due_days,omitempty" json:"due_days,omitempty"`         // Jatuh tempo setelah tagihan terbit; 0 berarti 7 hari
+}
+
+// BillingCycle adalah satu siklus di jadwal tagihan
+type BillingCycle struct {
+	Seq       int                 `bson:"seq" json:"seq"` // Dimulai dari 1
+	Amount    float64             `bson:"amount" json:"amount"`
+	IssueDate primitive.DateTime  `bson:"issue_date" json:"issue_date"` // Tagihan diterbitkan scheduler mulai tanggal ini
+	DueDate   primitive.DateTime  `bson:"due_date" json:"due_date"`
+	TagihanID *primitive.ObjectID `bson:"tagihan_id,omitempty" json:"tagihan_id,omitempty"` // Terisi setelah tagihan terbit
+}
+
+// BillingSchedule adalah jadwal tagihan satu siswa untuk satu kursus (koleksi
+// billing_schedules). Seluruh siklus dibuat saat siswa didaftarkan; tagihannya
+// diterbitkan satu per satu saat IssueDate tiba.
+type BillingSchedule struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SiswaID     primitive.ObjectID  `bson:"siswa_id" json:"siswa_id"`
+	CourseID    primitive.ObjectID  `bson:"course_id" json:"course_id"`
+	Plan        BillingPlan         `bson:"plan" json:"plan"` // Salinan rencana kursus saat pendaftaran
+	Total       float64             `bson:"total" json:"total"`
+	Cycles      []BillingCycle      `bson:"cycles" json:"cycles"`
+	Status      string              `bson:"status" json:"status"` // Lihat BillingSchedule*
+	StatusLabel string              `bson:"-" json:"status_label,omitempty"`
+	NextIssueAt *primitive.DateTime `bson:"next_issue_at,omitempty" json:"next_issue_at,omitempty"` // IssueDate siklus berikutnya yang belum terbit
+	CreatedAt   primitive.DateTime  `bson:"created_at" json:"created_at"`
+	UpdatedAt   primitive.DateTime  `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis dokumen cetak
+const (
+	DocumentInvoice = "invoice" // Rincian yang harus dibayar
+	DocumentReceipt = "receipt" // Kuitansi atas pembayaran yang sudah diterima
+)
+
+// Entitas yang bisa dicetakkan dokumen
+const (
+	DocumentEntityTagihan        = "tagihan"
+	DocumentEntityTransaksiSiswa = "transaksi_siswa"
+	DocumentEntityPayment        = "payment" // Satu pembayaran di ledger tagihan, untuk kuitansi
+)
+
+// Document mencatat nomor invoice atau kuitansi yang sudah diterbitkan untuk
+// sebuah tagihan, pembayaran tagihan atau transaksi siswa (koleksi documents).
+// Setiap entitas paling banyak punya satu dokumen per jenis, sehingga PDF yang
+// dicetak ulang tetap memakai nomor yang sama.
+type Document struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind     string             `bson:"kind" json:"kind"`               // Lihat DocumentInvoice dan DocumentReceipt
+	Number   string             `bson:"number,omitempty" json:"number"` // Nomor urut, contoh INV/2026/000042; kosong selama nomornya belum diberikan
+	Entity   string             `bson:"entity" json:"entity"`           // Lihat DocumentEntity*
+	EntityID primitive.ObjectID `bson:"entity_id" json:"entity_id"`
+	Code     string             `bson:"code" json:"-"` // Token acak di link verifikasi pada QR code
+	IssuedAt primitive.DateTime `bson:"issued_at" json:"issued_at"`
+}
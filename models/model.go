@@ -7,24 +7,40 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Username  string             `bson:"username"`
-	Email     string             `bson:"email"`
-	Password  string             `bson:"password"`
-	Role      string             `bson:"role"`   // Contoh: "admin", "user"
-	Status    string             `bson:"status"` // Contoh: "active", "inactive"
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	Schedule  string             `json:"schedule"`
+	ID            primitive.ObjectID  `bson:"_id,omitempty"`
+	Username      string              `bson:"username"`
+	Email         string              `bson:"email"`
+	Password      string              `json:"-" bson:"password"`                            // Hash bcrypt, tidak pernah dikirim ke client
+	Role          string              `bson:"role"`                                         // Contoh: "admin", "user"
+	Status        string              `bson:"status"`                                       // Contoh: "active", "inactive"
+	SiswaID       *primitive.ObjectID `json:"siswa_id,omitempty" bson:"siswa_id,omitempty"` // Data siswa milik akun ini
+	GuruID        *primitive.ObjectID `json:"guru_id,omitempty" bson:"guru_id,omitempty"`   // Data guru milik akun ini
+	EmailVerified bool                `json:"email_verified" bson:"email_verified"`
+	PendingEmail  string              `json:"pending_email,omitempty" bson:"pending_email,omitempty"` // Email baru yang menunggu verifikasi
+	FailedLogins  int                 `json:"failed_logins" bson:"failed_logins"`
+	LockedUntil   *time.Time          `json:"locked_until,omitempty" bson:"locked_until,omitempty"` // Terisi jika akun dikunci sementara
+	LastFailedAt  *time.Time          `json:"-" bson:"last_failed_at,omitempty"`
+	FailedIPs     []string            `json:"-" bson:"failed_ips,omitempty"` // IP asal login gagal terakhir, untuk membuka kunci IP-nya
+	TOTPEnabled   bool                `json:"totp_enabled" bson:"totp_enabled"`
+	TOTPSecret    string              `json:"-" bson:"totp_secret,omitempty"`
+	TOTPLastStep  int64               `json:"-" bson:"totp_last_step,omitempty"` // Mencegah kode TOTP dipakai ulang
+	RecoveryCodes []string            `json:"-" bson:"recovery_codes,omitempty"` // Hash SHA-256 kode pemulihan
+	CreatedAt     time.Time           `json:"created_at" bson:"created_at"`
+	DeletedAt     *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"` // Soft delete oleh admin
+	DeletedBy     *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	Schedule      string              `json:"schedule"`
 }
 
 type Course struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name        string             `bson:"name" json:"name"`
 	Duration    int                `bson:"duration" json:"duration"`
-	Cost        float64            `bson:"cost" json:"cost"`
+	Cost        float64            `bson:"cost" json:"cost"` // Harga total kursus, apa pun rencana penagihannya
 	Description string             `bson:"description" json:"description"`
 	CreatedAt   primitive.DateTime `bson:"createdAt" json:"createdAt"`
 	Schedule    string             `bson:"schedule" json:"schedule"` // Tambahkan ini
+	BillingPlan BillingPlan        `bson:"billing_plan" json:"billing_plan"`
+	SoftDelete  `bson:",inline"`
 }
 
 // Struct untuk mapping data Schedule dengan nama kursus dan multiple dates
@@ -33,6 +49,15 @@ type Schedule struct {
 	Dates []string `json:"dates" bson:"dates"`
 }
 
+// CourseSchedule adalah dokumen jadwal di koleksi course_schedules.
+// courseId disimpan sebagai string hex ObjectID kursus.
+type CourseSchedule struct {
+	CourseId string   `json:"courseId" bson:"courseId"`
+	Name     string   `json:"name" bson:"name"` // Nama kursus
+	Time     []string `json:"time" bson:"time"`
+	Dates    []string `json:"dates" bson:"dates"`
+}
+
 // CourseRegistration merepresentasikan data pendaftaran kursus
 type Registration struct {
 	CourseId    string   `json:"courseId" bson:"courseId"`
@@ -49,16 +74,21 @@ type Siswa struct {
 	Address     string             `bson:"address,omitempty" json:"address,omitempty"`
 	PhoneNumber string             `bson:"phonenumber,omitempty" json:"phonenumber,omitempty"`
 	Email       string             `bson:"email,omitempty" json:"email,omitempty"`
-	Status      string             `bson:"status,omitempty" json:"status,omitempty"` // "aktif" or "nonaktif"
+	Status      string             `bson:"status,omitempty" json:"status,omitempty"` // Lihat SiswaStatus*
+	StatusLabel string             `bson:"-" json:"status_label,omitempty"`          // Label status sesuai bahasa request
+	SoftDelete  `bson:",inline"`
 }
 type TransaksiSiswa struct {
-	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	SiswaID primitive.ObjectID `bson:"siswa_id" json:"siswa_id"`
-	UserID  primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Item    string             `bson:"item" json:"item"`
-	Harga   float64            `bson:"harga" json:"harga"`
-	Tanggal primitive.DateTime `bson:"tanggal" json:"tanggal"`
-	Status  string             `bson:"status" json:"status"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	SiswaID     primitive.ObjectID  `bson:"siswa_id" json:"siswa_id"`
+	UserID      primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Item        string              `bson:"item" json:"item"`
+	Harga       float64             `bson:"harga" json:"harga"`
+	Tanggal     primitive.DateTime  `bson:"tanggal" json:"tanggal"`
+	Status      string              `bson:"status" json:"status"` // Lihat TransaksiStatus*
+	StatusLabel string              `bson:"-" json:"status_label,omitempty"`
+	PaidAt      *primitive.DateTime `bson:"paid_at,omitempty" json:"paid_at,omitempty"` // Saat transaksi ditandai dibayar
+	SoftDelete  `bson:",inline"`
 }
 
 type Guru struct {
@@ -68,21 +98,48 @@ type Guru struct {
 	PhoneNumber   string             `bson:"phonenumber,omitempty" json:"phonenumber,omitempty"`
 	Email         string             `bson:"email,omitempty" json:"email,omitempty"`
 	SchoolSubject string             `bson:"school_subject,omitempty" json:"school_subject,omitempty"`
-	Status        string             `bson:"status,omitempty" json:"status,omitempty"` // "aktif" atau "nonaktif"
+	Status        string             `bson:"status,omitempty" json:"status,omitempty"` // Lihat GuruStatus*
+	StatusLabel   string             `bson:"-" json:"status_label,omitempty"`
+	SoftDelete    `bson:",inline"`
+}
+
+// Jenis baris tagihan
+const (
+	TagihanItemCourse  = "course"   // Biaya kursus atau cicilannya
+	TagihanItemLateFee = "late_fee" // Denda keterlambatan
+)
+
+// TagihanItem adalah satu baris rincian tagihan. Tagihan lama yang dibuat
+// sebelum ada rincian tidak punya Items; seluruh Amount-nya adalah biaya kursus.
+type TagihanItem struct {
+	Kind        string             `bson:"kind" json:"kind"` // Lihat TagihanItem*
+	Description string             `bson:"description" json:"description"`
+	Amount      float64            `bson:"amount" json:"amount"`
+	CreatedAt   primitive.DateTime `bson:"created_at" json:"created_at"`
 }
 
 type Tagihan struct {
-	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
-	SiswaID    primitive.ObjectID  `bson:"siswa_id" json:"siswa_id"`
-	SiswaNama  string              `bson:"siswa_nama" json:"siswa_nama"`
-	SiswaEmail string              `bson:"siswa_email" json:"siswa_email"`
-	CourseID   primitive.ObjectID  `bson:"course_id" json:"course_id"`
-	CourseName string              `bson:"course_name" json:"course_name"`
-	Amount     float64             `bson:"amount" json:"amount"`
-	DueDate    primitive.DateTime  `bson:"due_date" json:"due_date"`
-	Paid       bool                `bson:"paid" json:"paid"`
-	Status     string              `bson:"status" json:"status"` // Tambahkan status di database
-	PaidAt     *primitive.DateTime `bson:"paid_at,omitempty" json:"paid_at,omitempty"`
-	CreatedAt  primitive.DateTime  `bson:"created_at" json:"created_at"`
-	UpdatedAt  primitive.DateTime  `bson:"updated_at" json:"updated_at"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	SiswaID     primitive.ObjectID  `bson:"siswa_id" json:"siswa_id"`
+	SiswaNama   string              `bson:"siswa_nama" json:"siswa_nama"`
+	SiswaEmail  string              `bson:"siswa_email" json:"siswa_email"`
+	CourseID    primitive.ObjectID  `bson:"course_id" json:"course_id"`
+	CourseName  string              `bson:"course_name" json:"course_name"`
+	Amount      float64             `bson:"amount" json:"amount"` // Jumlah semua Items
+	Items       []TagihanItem       `bson:"items,omitempty" json:"items,omitempty"`
+	PaidAmount  float64             `bson:"paid_amount" json:"paid_amount"` // Total bersih dari ledger pembayaran
+	Outstanding float64             `bson:"outstanding" json:"outstanding"` // Amount - PaidAmount, negatif jika lebih bayar
+	DueDate     primitive.DateTime  `bson:"due_date" json:"due_date"`
+	Paid        bool                `bson:"paid" json:"paid"`     // true jika lunas atau lebih bayar
+	Status      string              `bson:"status" json:"status"` // Lihat TagihanStatus*
+	StatusLabel string              `bson:"-" json:"status_label,omitempty"`
+	PaidAt      *primitive.DateTime `bson:"paid_at,omitempty" json:"paid_at,omitempty"`               // Saat tagihan menjadi lunas
+	LedgerCount int                 `bson:"ledger_count" json:"-"`                                    // Jumlah entri ledger yang sudah dihitung ke PaidAmount
+	ScheduleID  *primitive.ObjectID `bson:"schedule_id,omitempty" json:"schedule_id,omitempty"`       // Jadwal tagihan asal, kosong untuk tagihan manual
+	Cycle       int                 `bson:"cycle,omitempty" json:"cycle,omitempty"`                   // Nomor siklus di jadwal tagihan
+	LateFees    int                 `bson:"late_fee_count,omitempty" json:"late_fee_count,omitempty"` // Jumlah denda keterlambatan yang sudah dikenakan
+	OverdueAt   *primitive.DateTime `bson:"overdue_at,omitempty" json:"overdue_at,omitempty"`         // Saat tagihan ditandai terlambat
+	CreatedAt   primitive.DateTime  `bson:"created_at" json:"created_at"`
+	UpdatedAt   primitive.DateTime  `bson:"updated_at" json:"updated_at"`
+	SoftDelete  `bson:",inline"`
 }
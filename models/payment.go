@@ -0,0 +1,40 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Jenis entri di ledger pembayaran. Entri tidak pernah diubah atau dihapus;
+// koreksi dicatat sebagai entri baru (refund atau void).
+const (
+	PaymentKindPayment = "payment" // Uang diterima dari siswa
+	PaymentKindRefund  = "refund"  // Uang dikembalikan ke siswa
+	PaymentKindVoid    = "void"    // Membatalkan payment atau refund yang salah catat
+)
+
+// Metode pembayaran
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodTransfer = "transfer"
+	PaymentMethodEWallet  = "e-wallet"
+	// PaymentMethodLegacy hanya dipakai untuk entri hasil migrasi tagihan yang
+	// sudah lunas sebelum ledger ada
+	PaymentMethodLegacy = "legacy"
+)
+
+// Payment adalah satu entri ledger pembayaran tagihan (koleksi payments).
+// Amount selalu positif; arah uangnya ditentukan oleh Kind.
+type Payment struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TagihanID  primitive.ObjectID  `bson:"tagihan_id" json:"tagihan_id"`
+	Kind       string              `bson:"kind" json:"kind"`       // Lihat PaymentKind*
+	Seq        int                 `bson:"seq,omitempty" json:"-"` // Nomor urut entri di ledger tagihan; kosong untuk entri lama
+	Amount     float64             `bson:"amount" json:"amount"`
+	Method     string              `bson:"method,omitempty" json:"method,omitempty"`       // Lihat PaymentMethod*; kosong untuk void
+	Reference  string              `bson:"reference,omitempty" json:"reference,omitempty"` // Nomor bukti transfer, ID transaksi e-wallet, dsb.
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	VoidOf     *primitive.ObjectID `bson:"void_of,omitempty" json:"void_of,omitempty"` // Entri yang dibatalkan, hanya untuk void
+	ReceivedBy *primitive.ObjectID `bson:"received_by,omitempty" json:"received_by,omitempty"`
+	ReceivedAt primitive.DateTime  `bson:"received_at" json:"received_at"`
+	CreatedAt  primitive.DateTime  `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session menyimpan satu sesi login. Access token membawa claim "jti" yang
+// menunjuk ke field JTI di sini, sehingga sesi bisa dicabut dari server.
+type Session struct {
+	ID                  primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID              primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	JTI                 string              `bson:"jti" json:"-"`
+	RefreshTokenHash    string              `bson:"refresh_token_hash" json:"-"`
+	PreviousRefreshHash string              `bson:"previous_refresh_hash,omitempty" json:"-"`
+	UserAgent           string              `bson:"user_agent" json:"user_agent"`
+	IP                  string              `bson:"ip" json:"ip"`
+	CreatedAt           primitive.DateTime  `bson:"created_at" json:"created_at"`
+	LastUsedAt          primitive.DateTime  `bson:"last_used_at" json:"last_used_at"`
+	ExpiresAt           primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	RevokedAt           *primitive.DateTime `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
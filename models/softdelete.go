@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SoftDelete disisipkan (bson inline) di data domain yang bisa dipindahkan ke
+// trash. Data dengan DeletedAt terisi tidak muncul di query biasa, bisa
+// dipulihkan, dan dihapus permanen oleh purge job setelah masa retensi.
+type SoftDelete struct {
+	DeletedAt *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy *primitive.ObjectID `bson:"deleted_by,omitempty" json:"deleted_by,omitempty"`
+}
+
+// IsDeleted mengecek apakah data sedang berada di trash
+func (s SoftDelete) IsDeleted() bool {
+	return s.DeletedAt != nil
+}
+
+// DeletedBefore mengecek apakah data masuk trash sebelum waktu t
+func (s SoftDelete) DeletedBefore(t time.Time) bool {
+	return s.DeletedAt != nil && s.DeletedAt.Before(t)
+}
+
+// Trash menandai data dihapus pada waktu at oleh user by (boleh nil)
+func (s *SoftDelete) Trash(at time.Time, by *primitive.ObjectID) {
+	s.DeletedAt = &at
+	s.DeletedBy = by
+}
+
+// Restore mengeluarkan data dari trash
+func (s *SoftDelete) Restore() {
+	s.DeletedAt = nil
+	s.DeletedBy = nil
+}
@@ -0,0 +1,56 @@
+package models
+
+// Kode status yang disimpan di database. Nilainya stabil dan tidak diterjemahkan;
+// label untuk ditampilkan ke user diambil dari katalog i18n saat membuat response.
+const (
+	// Status akun user
+	UserStatusActive   = "active"
+	UserStatusInactive = "inactive" // Menunggu persetujuan admin atau dinonaktifkan
+	UserStatusRejected = "rejected"
+
+	// Status siswa dan guru
+	SiswaStatusActive   = "active"
+	SiswaStatusInactive = "inactive"
+	GuruStatusActive    = "active"
+	GuruStatusInactive  = "inactive"
+
+	// Status tagihan, dihitung dari ledger pembayaran
+	TagihanStatusUnpaid   = "unpaid"
+	TagihanStatusPartial  = "partial" // Sudah dibayar sebagian
+	TagihanStatusPaid     = "paid"
+	TagihanStatusOverpaid = "overpaid"
+	TagihanStatusOverdue  = "overdue" // Lewat jatuh tempo dan belum lunas
+
+	// Status transaksi siswa
+	TransaksiStatusPending = "pending"
+	TransaksiStatusPaid    = "paid"
+)
+
+// legacyStatuses memetakan nilai status lama (teks bahasa Indonesia) ke kode baru
+var legacyStatuses = map[string]string{
+	"aktif":       "active",
+	"nonaktif":    "inactive",
+	"Belum Bayar": TagihanStatusUnpaid,
+	"Lunas":       TagihanStatusPaid,
+	"Terlambat":   TagihanStatusOverdue,
+}
+
+// NormalizeStatus mengubah nilai status lama ke kode baru. Kode yang sudah
+// benar dikembalikan apa adanya.
+func NormalizeStatus(status string) string {
+	if code, ok := legacyStatuses[status]; ok {
+		return code
+	}
+	return status
+}
+
+// LegacyStatuses mengembalikan nilai status lama yang setara dengan kode tertentu
+func LegacyStatuses(code string) []string {
+	var legacy []string
+	for old, c := range legacyStatuses {
+		if c == code {
+			legacy = append(legacy, old)
+		}
+	}
+	return legacy
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tujuan token sekali pakai
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// AuthToken adalah token sekali pakai yang punya masa berlaku, misalnya untuk
+// verifikasi email dan reset password. Yang disimpan hanya hash token.
+type AuthToken struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Purpose   string              `bson:"purpose" json:"purpose"`
+	TokenHash string              `bson:"token_hash" json:"-"`
+	Email     string              `bson:"email" json:"email"` // Alamat email yang sedang diverifikasi
+	ExpiresAt primitive.DateTime  `bson:"expires_at" json:"expires_at"`
+	UsedAt    *primitive.DateTime `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt primitive.DateTime  `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/organisasi/tubesbackend/audit"
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditFilter membatasi hasil AuditRepository.ListPage; field kosong berarti tidak difilter
+type AuditFilter struct {
+	ActorID  *primitive.ObjectID
+	Entity   string
+	EntityID *primitive.ObjectID
+	Action   string
+	From     time.Time
+	To       time.Time
+}
+
+func (f AuditFilter) query() bson.M {
+	query := bson.M{}
+	if f.ActorID != nil {
+		query["actor_id"] = *f.ActorID
+	}
+	if f.Entity != "" {
+		query["entity"] = f.Entity
+	}
+	if f.EntityID != nil {
+		query["entity_id"] = *f.EntityID
+	}
+	if f.Action != "" {
+		query["action"] = f.Action
+	}
+	created := bson.M{}
+	if !f.From.IsZero() {
+		created["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		created["$lte"] = f.To
+	}
+	if len(created) > 0 {
+		query["created_at"] = created
+	}
+	return query
+}
+
+func (f AuditFilter) match(e models.AuditEntry) bool {
+	if f.ActorID != nil && (e.ActorID == nil || *e.ActorID != *f.ActorID) {
+		return false
+	}
+	if f.EntityID != nil && e.EntityID != *f.EntityID {
+		return false
+	}
+	if !f.From.IsZero() && e.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && e.CreatedAt.After(f.To) {
+		return false
+	}
+	return (f.Entity == "" || e.Entity == f.Entity) && (f.Action == "" || e.Action == f.Action)
+}
+
+// AuditRepository menyimpan audit log. Sengaja tidak ada method untuk
+// mengubah atau menghapus entri.
+type AuditRepository interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	ListPage(ctx context.Context, filter AuditFilter, opts ListOptions) (Page[models.AuditEntry], error)
+}
+
+// RecordAudit mencatat perubahan satu data beserta actor dari ctx (lihat
+// audit.WithActor). before atau after boleh nil untuk create, delete dan
+// restore. Kegagalan hanya dicatat di log karena perubahan datanya sudah
+// tersimpan; update tanpa field yang berubah tidak dicatat.
+func RecordAudit(ctx context.Context, auditLog AuditRepository, action, entity string, id primitive.ObjectID, before, after interface{}) {
+	if auditLog == nil {
+		return
+	}
+	if err := recordAudit(ctx, auditLog, action, entity, id, before, after); err != nil {
+		log.Printf("Failed to write audit log for %s %s %s: %v", action, entity, id.Hex(), err)
+	}
+}
+
+func recordAudit(ctx context.Context, auditLog AuditRepository, action, entity string, id primitive.ObjectID, before, after interface{}) error {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	actor := audit.ActorFrom(ctx)
+	entry := models.AuditEntry{
+		ActorID:   actor.UserID,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  id,
+		Changes:   changes,
+		IP:        actor.IP,
+		RequestID: actor.RequestID,
+		CreatedAt: time.Now(),
+	}
+
+	// Tetap tercatat walaupun request dibatalkan setelah data tersimpan
+	appendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return auditLog.Append(appendCtx, &entry)
+}
+
+type mongoAuditRepository struct {
+	coll *mongo.Collection
+}
+
+func (r *mongoAuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, entry)
+}
+
+func (r *mongoAuditRepository) ListPage(ctx context.Context, filter AuditFilter, opts ListOptions) (Page[models.AuditEntry], error) {
+	return findPage[models.AuditEntry](ctx, r.coll, filter.query(), opts)
+}
+
+type memoryAuditRepository struct {
+	store *memoryStore[primitive.ObjectID, models.AuditEntry]
+}
+
+func newMemoryAuditRepository() *memoryAuditRepository {
+	return &memoryAuditRepository{store: newMemoryStore(func(e models.AuditEntry) primitive.ObjectID { return e.ID })}
+}
+
+func (r *memoryAuditRepository) Append(_ context.Context, entry *models.AuditEntry) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*entry)
+}
+
+func (r *memoryAuditRepository) ListPage(_ context.Context, filter AuditFilter, opts ListOptions) (Page[models.AuditEntry], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// withAudit membungkus repository data keuangan dan kepegawaian sehingga setiap
+// create, update, delete dan restore tercatat di r.Audit, termasuk perubahan
+// yang dilakukan lewat service.
+func withAudit(r *Repositories) *Repositories {
+	r.Siswa = auditedSiswaRepository{r.Siswa, r.Audit}
+	r.TransaksiSiswa = auditedTransaksiSiswaRepository{r.TransaksiSiswa, r.Audit}
+	r.Guru = auditedGuruRepository{r.Guru, r.Audit}
+	r.TransaksiGuru = auditedTransaksiGuruRepository{r.TransaksiGuru, r.Audit}
+	r.Tagihan = auditedTagihanRepository{r.Tagihan, r.Audit}
+	r.Course = auditedCourseRepository{r.Course, r.Audit}
+	r.Payment = auditedPaymentRepository{r.Payment, r.Audit}
+	r.Billing = auditedBillingScheduleRepository{r.Billing, r.Audit}
+	return r
+}
+
+// auditedWrite menjalankan write lalu mencatat keadaan data sebelum dan
+// sesudahnya. Keadaan sebelum tidak dibaca untuk restore (data masih di trash)
+// dan keadaan sesudah tidak dibaca untuk delete.
+func auditedWrite[T any](ctx context.Context, auditLog AuditRepository, action, entity string, id primitive.ObjectID, get func(context.Context, primitive.ObjectID) (T, error), write func() error) error {
+	var before, after interface{}
+	if action != models.AuditActionRestore {
+		item, err := get(ctx, id)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			before = item
+		}
+	}
+
+	if err := write(); err != nil {
+		return err
+	}
+
+	if action != models.AuditActionDelete {
+		if item, err := get(ctx, id); err == nil {
+			after = item
+		}
+	}
+	RecordAudit(ctx, auditLog, action, entity, id, before, after)
+	return nil
+}
+
+type auditedSiswaRepository struct {
+	SiswaRepository
+	audit AuditRepository
+}
+
+func (r auditedSiswaRepository) Create(ctx context.Context, siswa *models.Siswa) error {
+	if err := r.SiswaRepository.Create(ctx, siswa); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "siswa", siswa.ID, nil, *siswa)
+	return nil
+}
+
+func (r auditedSiswaRepository) Update(ctx context.Context, id primitive.ObjectID, siswa models.Siswa) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "siswa", id, r.Get, func() error {
+		return r.SiswaRepository.Update(ctx, id, siswa)
+	})
+}
+
+func (r auditedSiswaRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "siswa", id, r.Get, func() error {
+		return r.SiswaRepository.SetStatus(ctx, id, status)
+	})
+}
+
+func (r auditedSiswaRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "siswa", id, r.Get, func() error {
+		return r.SiswaRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedSiswaRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "siswa", id, r.Get, func() error {
+		return r.SiswaRepository.Restore(ctx, id)
+	})
+}
+
+type auditedTransaksiSiswaRepository struct {
+	TransaksiSiswaRepository
+	audit AuditRepository
+}
+
+func (r auditedTransaksiSiswaRepository) Create(ctx context.Context, transaksi *models.TransaksiSiswa) error {
+	if err := r.TransaksiSiswaRepository.Create(ctx, transaksi); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "transaksi_siswa", transaksi.ID, nil, *transaksi)
+	return nil
+}
+
+func (r auditedTransaksiSiswaRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "transaksi_siswa", id, r.Get, func() error {
+		return r.TransaksiSiswaRepository.SetStatus(ctx, id, status)
+	})
+}
+
+func (r auditedTransaksiSiswaRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "transaksi_siswa", id, r.Get, func() error {
+		return r.TransaksiSiswaRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedTransaksiSiswaRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "transaksi_siswa", id, r.Get, func() error {
+		return r.TransaksiSiswaRepository.Restore(ctx, id)
+	})
+}
+
+type auditedGuruRepository struct {
+	GuruRepository
+	audit AuditRepository
+}
+
+func (r auditedGuruRepository) Create(ctx context.Context, guru *models.Guru) error {
+	if err := r.GuruRepository.Create(ctx, guru); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "guru", guru.ID, nil, *guru)
+	return nil
+}
+
+func (r auditedGuruRepository) Update(ctx context.Context, id primitive.ObjectID, guru models.Guru) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "guru", id, r.Get, func() error {
+		return r.GuruRepository.Update(ctx, id, guru)
+	})
+}
+
+func (r auditedGuruRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "guru", id, r.Get, func() error {
+		return r.GuruRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedGuruRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "guru", id, r.Get, func() error {
+		return r.GuruRepository.Restore(ctx, id)
+	})
+}
+
+type auditedTransaksiGuruRepository struct {
+	TransaksiGuruRepository
+	audit AuditRepository
+}
+
+func (r auditedTransaksiGuruRepository) Create(ctx context.Context, transaksi *models.TransaksiGuru) error {
+	if err := r.TransaksiGuruRepository.Create(ctx, transaksi); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "transaksi_guru", transaksi.ID, nil, *transaksi)
+	return nil
+}
+
+func (r auditedTransaksiGuruRepository) Update(ctx context.Context, id primitive.ObjectID, amount float64, notes string) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "transaksi_guru", id, r.Get, func() error {
+		return r.TransaksiGuruRepository.Update(ctx, id, amount, notes)
+	})
+}
+
+func (r auditedTransaksiGuruRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "transaksi_guru", id, r.Get, func() error {
+		return r.TransaksiGuruRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedTransaksiGuruRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "transaksi_guru", id, r.Get, func() error {
+		return r.TransaksiGuruRepository.Restore(ctx, id)
+	})
+}
+
+type auditedTagihanRepository struct {
+	TagihanRepository
+	audit AuditRepository
+}
+
+func (r auditedTagihanRepository) Create(ctx context.Context, tagihan *models.Tagihan) error {
+	if err := r.TagihanRepository.Create(ctx, tagihan); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "tagihan", tagihan.ID, nil, *tagihan)
+	return nil
+}
+
+func (r auditedTagihanRepository) UpdateDetails(ctx context.Context, id primitive.ObjectID, details TagihanDetails) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.UpdateDetails(ctx, id, details)
+	})
+}
+
+func (r auditedTagihanRepository) SetBalance(ctx context.Context, id primitive.ObjectID, balance TagihanBalance) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.SetBalance(ctx, id, balance)
+	})
+}
+
+func (r auditedTagihanRepository) MarkOverdue(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.MarkOverdue(ctx, id, at)
+	})
+}
+
+func (r auditedTagihanRepository) AddLateFee(ctx context.Context, id primitive.ObjectID, fee TagihanLateFee) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.AddLateFee(ctx, id, fee)
+	})
+}
+
+func (r auditedTagihanRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedTagihanRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "tagihan", id, r.Get, func() error {
+		return r.TagihanRepository.Restore(ctx, id)
+	})
+}
+
+type auditedCourseRepository struct {
+	CourseRepository
+	audit AuditRepository
+}
+
+func (r auditedCourseRepository) Create(ctx context.Context, course *models.Course) error {
+	if err := r.CourseRepository.Create(ctx, course); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "course", course.ID, nil, *course)
+	return nil
+}
+
+func (r auditedCourseRepository) Update(ctx context.Context, id primitive.ObjectID, course models.Course) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "course", id, r.Get, func() error {
+		return r.CourseRepository.Update(ctx, id, course)
+	})
+}
+
+func (r auditedCourseRepository) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionDelete, "course", id, r.Get, func() error {
+		return r.CourseRepository.Delete(ctx, id, by)
+	})
+}
+
+func (r auditedCourseRepository) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionRestore, "course", id, r.Get, func() error {
+		return r.CourseRepository.Restore(ctx, id)
+	})
+}
+
+type auditedPaymentRepository struct {
+	PaymentRepository
+	audit AuditRepository
+}
+
+func (r auditedPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	if err := r.PaymentRepository.Create(ctx, payment); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "payment", payment.ID, nil, *payment)
+	return nil
+}
+
+type auditedBillingScheduleRepository struct {
+	BillingScheduleRepository
+	audit AuditRepository
+}
+
+func (r auditedBillingScheduleRepository) Create(ctx context.Context, schedule *models.BillingSchedule) error {
+	if err := r.BillingScheduleRepository.Create(ctx, schedule); err != nil {
+		return err
+	}
+	RecordAudit(ctx, r.audit, models.AuditActionCreate, "billing_schedule", schedule.ID, nil, *schedule)
+	return nil
+}
+
+func (r auditedBillingScheduleRepository) ClaimCycle(ctx context.Context, id primitive.ObjectID, seq int, tagihanID primitive.ObjectID) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "billing_schedule", id, r.Get, func() error {
+		return r.BillingScheduleRepository.ClaimCycle(ctx, id, seq, tagihanID)
+	})
+}
+
+func (r auditedBillingScheduleRepository) SetProgress(ctx context.Context, id primitive.ObjectID, status string, next *time.Time, at time.Time) error {
+	return auditedWrite(ctx, r.audit, models.AuditActionUpdate, "billing_schedule", id, r.Get, func() error {
+		return r.BillingScheduleRepository.SetProgress(ctx, id, status, next, at)
+	})
+}
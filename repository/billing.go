@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BillingScheduleFilter membatasi hasil BillingScheduleRepository.List
+type BillingScheduleFilter struct {
+	SiswaID  *primitive.ObjectID
+	CourseID *primitive.ObjectID
+	Status   string
+}
+
+func (f BillingScheduleFilter) query() bson.M {
+	query := bson.M{}
+	if f.SiswaID != nil {
+		query["siswa_id"] = *f.SiswaID
+	}
+	if f.CourseID != nil {
+		query["course_id"] = *f.CourseID
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (f BillingScheduleFilter) match(s models.BillingSchedule) bool {
+	if f.SiswaID != nil && s.SiswaID != *f.SiswaID {
+		return false
+	}
+	if f.CourseID != nil && s.CourseID != *f.CourseID {
+		return false
+	}
+	return f.Status == "" || s.Status == f.Status
+}
+
+// BillingScheduleRepository mengelola jadwal tagihan berulang dan cicilan
+type BillingScheduleRepository interface {
+	Create(ctx context.Context, schedule *models.BillingSchedule) error
+	Get(ctx context.Context, id primitive.ObjectID) (models.BillingSchedule, error)
+	// List mengembalikan jadwal yang cocok, terbaru lebih dulu
+	List(ctx context.Context, filter BillingScheduleFilter) ([]models.BillingSchedule, error)
+	// Due mengembalikan jadwal aktif yang punya siklus dengan IssueDate <= at
+	Due(ctx context.Context, at time.Time) ([]models.BillingSchedule, error)
+	// ClaimCycle mengisi TagihanID sebuah siklus pada jadwal aktif setelah
+	// tagihannya tersimpan. ErrConflict jika siklus itu sudah diklaim atau
+	// jadwalnya tidak lagi aktif, sehingga jadwal yang dibatalkan berhenti.
+	ClaimCycle(ctx context.Context, id primitive.ObjectID, seq int, tagihanID primitive.ObjectID) error
+	// SetProgress menyimpan status dan NextIssueAt (nil jika tidak ada siklus
+	// tersisa) pada jadwal aktif. ErrConflict jika jadwal sudah selesai atau
+	// dibatalkan, sehingga pembatalan tidak tertimpa scheduler yang berjalan.
+	SetProgress(ctx context.Context, id primitive.ObjectID, status string, next *time.Time, at time.Time) error
+}
+
+type mongoBillingScheduleRepository struct {
+	coll *mongo.Collection
+}
+
+func (r *mongoBillingScheduleRepository) Create(ctx context.Context, schedule *models.BillingSchedule) error {
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, schedule)
+}
+
+func (r *mongoBillingScheduleRepository) Get(ctx context.Context, id primitive.ObjectID) (models.BillingSchedule, error) {
+	return findOne[models.BillingSchedule](ctx, r.coll, bson.M{"_id": id})
+}
+
+func (r *mongoBillingScheduleRepository) List(ctx context.Context, filter BillingScheduleFilter) ([]models.BillingSchedule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return findAll[models.BillingSchedule](ctx, r.coll, filter.query(), opts)
+}
+
+func (r *mongoBillingScheduleRepository) Due(ctx context.Context, at time.Time) ([]models.BillingSchedule, error) {
+	return findAll[models.BillingSchedule](ctx, r.coll, bson.M{
+		"status":        models.BillingScheduleActive,
+		"next_issue_at": bson.M{"$lte": primitive.NewDateTimeFromTime(at)},
+	})
+}
+
+func (r *mongoBillingScheduleRepository) ClaimCycle(ctx context.Context, id primitive.ObjectID, seq int, tagihanID primitive.ObjectID) error {
+	err := updateOne(ctx, r.coll,
+		bson.M{
+			"_id":    id,
+			"status": models.BillingScheduleActive,
+			"cycles": bson.M{"$elemMatch": bson.M{"seq": seq, "tagihan_id": bson.M{"$exists": false}}},
+		},
+		bson.M{"$set": bson.M{"cycles.$.tagihan_id": tagihanID}},
+	)
+	if errors.Is(err, ErrNotFound) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *mongoBillingScheduleRepository) SetProgress(ctx context.Context, id primitive.ObjectID, status string, next *time.Time, at time.Time) error {
+	update := bson.M{"$set": bson.M{"status": status, "updated_at": primitive.NewDateTimeFromTime(at)}}
+	if next != nil {
+		update["$set"].(bson.M)["next_issue_at"] = primitive.NewDateTimeFromTime(*next)
+	} else {
+		update["$unset"] = bson.M{"next_issue_at": ""}
+	}
+	err := updateOne(ctx, r.coll, bson.M{"_id": id, "status": models.BillingScheduleActive}, update)
+	if errors.Is(err, ErrNotFound) {
+		if _, getErr := r.Get(ctx, id); getErr != nil {
+			return getErr
+		}
+		return ErrConflict
+	}
+	return err
+}
+
+type memoryBillingScheduleRepository struct {
+	store *memoryStore[primitive.ObjectID, models.BillingSchedule]
+}
+
+func newMemoryBillingScheduleRepository() *memoryBillingScheduleRepository {
+	return &memoryBillingScheduleRepository{store: newMemoryStore(func(s models.BillingSchedule) primitive.ObjectID { return s.ID })}
+}
+
+func (r *memoryBillingScheduleRepository) Create(_ context.Context, schedule *models.BillingSchedule) error {
+	if schedule.ID.IsZero() {
+		schedule.ID = primitive.NewObjectID()
+	}
+	// Salin siklus agar pemanggil tidak ikut mengubah isi store
+	stored := *schedule
+	stored.Cycles = append([]models.BillingCycle{}, schedule.Cycles...)
+	return r.store.insert(stored)
+}
+
+func (r *memoryBillingScheduleRepository) Get(_ context.Context, id primitive.ObjectID) (models.BillingSchedule, error) {
+	schedule, err := r.store.get(id)
+	if err != nil {
+		return models.BillingSchedule{}, err
+	}
+	schedule.Cycles = append([]models.BillingCycle{}, schedule.Cycles...)
+	return schedule, nil
+}
+
+func (r *memoryBillingScheduleRepository) List(_ context.Context, filter BillingScheduleFilter) ([]models.BillingSchedule, error) {
+	items := r.store.list(filter.match)
+	// Terbaru lebih dulu, sama dengan urutan di Mongo
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items, nil
+}
+
+func (r *memoryBillingScheduleRepository) Due(_ context.Context, at time.Time) ([]models.BillingSchedule, error) {
+	due := primitive.NewDateTimeFromTime(at)
+	return r.store.list(func(s models.BillingSchedule) bool {
+		return s.Status == models.BillingScheduleActive && s.NextIssueAt != nil && *s.NextIssueAt <= due
+	}), nil
+}
+
+func (r *memoryBillingScheduleRepository) ClaimCycle(_ context.Context, id primitive.ObjectID, seq int, tagihanID primitive.ObjectID) error {
+	claimed := false
+	err := r.store.update(id, func(s *models.BillingSchedule) {
+		if s.Status != models.BillingScheduleActive {
+			return
+		}
+		s.Cycles = append([]models.BillingCycle{}, s.Cycles...)
+		for i := range s.Cycles {
+			if s.Cycles[i].Seq == seq && s.Cycles[i].TagihanID == nil {
+				s.Cycles[i].TagihanID = &tagihanID
+				claimed = true
+			}
+		}
+	})
+	if err == nil && !claimed {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memoryBillingScheduleRepository) SetProgress(_ context.Context, id primitive.ObjectID, status string, next *time.Time, at time.Time) error {
+	active := true
+	err := r.store.update(id, func(s *models.BillingSchedule) {
+		if s.Status != models.BillingScheduleActive {
+			active = false
+			return
+		}
+		s.Status = status
+		s.NextIssueAt = nil
+		if next != nil {
+			nextIssueAt := primitive.NewDateTimeFromTime(*next)
+			s.NextIssueAt = &nextIssueAt
+		}
+		s.UpdatedAt = primitive.NewDateTimeFromTime(at)
+	})
+	if err == nil && !active {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CourseFilter membatasi hasil CourseRepository.ListPage
+type CourseFilter struct {
+	// Q mencari di nama dan deskripsi kursus
+	Q string
+}
+
+func (f CourseFilter) match(c models.Course) bool {
+	return f.Q == "" || textScore(c, "courses", f.Q) > 0
+}
+
+// CourseRepository mengelola katalog kursus
+type CourseRepository interface {
+	Create(ctx context.Context, course *models.Course) error
+	List(ctx context.Context) ([]models.Course, error)
+	ListPage(ctx context.Context, filter CourseFilter, opts ListOptions) (Page[models.Course], error)
+	// Search mencari kursus berdasarkan nama atau deskripsi, paling relevan lebih dulu
+	Search(ctx context.Context, q string, limit int) ([]Scored[models.Course], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Course, error)
+	// FindByRef mencari kursus berdasarkan field "id" lama (string) lalu ObjectID hex
+	FindByRef(ctx context.Context, ref string) (models.Course, error)
+	FindByName(ctx context.Context, name string) (models.Course, error)
+	GetMany(ctx context.Context, ids []primitive.ObjectID) ([]models.Course, error)
+	// Update mengganti nama, durasi, biaya, deskripsi, jadwal dan rencana penagihan kursus
+	Update(ctx context.Context, id primitive.ObjectID, course models.Course) error
+	// Count menghitung semua kursus, termasuk yang ada di trash
+	Count(ctx context.Context) (int64, error)
+	Trash[models.Course]
+}
+
+// ScheduleRepository mengelola jadwal kursus, satu dokumen per courseId
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule models.CourseSchedule) error
+	List(ctx context.Context) ([]models.CourseSchedule, error)
+	ListPage(ctx context.Context, opts ListOptions) (Page[models.CourseSchedule], error)
+	ListByCourseIDs(ctx context.Context, courseIDs []string) ([]models.CourseSchedule, error)
+	FindByCourseID(ctx context.Context, courseID string) (models.CourseSchedule, error)
+	// AppendDates menambahkan tanggal baru ke jadwal yang sudah ada
+	AppendDates(ctx context.Context, courseID string, dates []string) error
+	Update(ctx context.Context, courseID string, times, dates []string) error
+	Delete(ctx context.Context, courseID string) error
+}
+
+// RegistrationFilter membatasi hasil RegistrationRepository.ListPage
+type RegistrationFilter struct {
+	// Course cocok dengan courseId atau salah satu isi courses
+	Course string
+	Status string
+}
+
+func (f RegistrationFilter) query() bson.M {
+	query := bson.M{}
+	if f.Course != "" {
+		query["$or"] = bson.A{bson.M{"courseId": f.Course}, bson.M{"courses": f.Course}}
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (f RegistrationFilter) match(r models.Registration) bool {
+	if f.Course != "" && r.CourseId != f.Course && !containsString(r.Courses, f.Course) {
+		return false
+	}
+	return f.Status == "" || r.Status == f.Status
+}
+
+// RegistrationRepository menyimpan formulir pendaftaran kursus publik
+type RegistrationRepository interface {
+	Create(ctx context.Context, registration models.Registration) error
+	List(ctx context.Context) ([]models.Registration, error)
+	ListPage(ctx context.Context, filter RegistrationFilter, opts ListOptions) (Page[models.Registration], error)
+}
+
+type mongoCourseRepository struct {
+	coll *mongo.Collection
+	mongoTrash[models.Course]
+}
+
+func (r *mongoCourseRepository) Create(ctx context.Context, course *models.Course) error {
+	if course.ID.IsZero() {
+		course.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, course)
+}
+
+func (r *mongoCourseRepository) List(ctx context.Context) ([]models.Course, error) {
+	return findAll[models.Course](ctx, r.coll, notDeleted(bson.M{}))
+}
+
+func (r *mongoCourseRepository) ListPage(ctx context.Context, filter CourseFilter, opts ListOptions) (Page[models.Course], error) {
+	return findTextPage[models.Course](ctx, r.coll, notDeleted(bson.M{}), filter.Q, opts)
+}
+
+func (r *mongoCourseRepository) Search(ctx context.Context, q string, limit int) ([]Scored[models.Course], error) {
+	return textSearch[models.Course](ctx, r.coll, notDeleted(bson.M{}), q, limit)
+}
+
+func (r *mongoCourseRepository) Get(ctx context.Context, id primitive.ObjectID) (models.Course, error) {
+	return findOne[models.Course](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoCourseRepository) FindByRef(ctx context.Context, ref string) (models.Course, error) {
+	course, err := findOne[models.Course](ctx, r.coll, notDeleted(bson.M{"id": ref}))
+	if !errors.Is(err, ErrNotFound) {
+		return course, err
+	}
+	objID, objErr := primitive.ObjectIDFromHex(ref)
+	if objErr != nil {
+		return course, ErrNotFound
+	}
+	return r.Get(ctx, objID)
+}
+
+func (r *mongoCourseRepository) FindByName(ctx context.Context, name string) (models.Course, error) {
+	return findOne[models.Course](ctx, r.coll, notDeleted(bson.M{"name": name}))
+}
+
+func (r *mongoCourseRepository) GetMany(ctx context.Context, ids []primitive.ObjectID) ([]models.Course, error) {
+	if len(ids) == 0 {
+		return []models.Course{}, nil
+	}
+	return findAll[models.Course](ctx, r.coll, notDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+}
+
+func (r *mongoCourseRepository) Update(ctx context.Context, id primitive.ObjectID, course models.Course) error {
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{
+		"name":         course.Name,
+		"duration":     course.Duration,
+		"cost":         course.Cost,
+		"description":  course.Description,
+		"schedule":     course.Schedule,
+		"billing_plan": course.BillingPlan,
+	}})
+}
+
+func (r *mongoCourseRepository) Count(ctx context.Context) (int64, error) {
+	return r.coll.CountDocuments(ctx, bson.M{})
+}
+
+type mongoScheduleRepository struct {
+	coll *mongo.Collection
+}
+
+func (r *mongoScheduleRepository) Create(ctx context.Context, schedule models.CourseSchedule) error {
+	return insertOne(ctx, r.coll, schedule)
+}
+
+func (r *mongoScheduleRepository) List(ctx context.Context) ([]models.CourseSchedule, error) {
+	return findAll[models.CourseSchedule](ctx, r.coll, bson.M{})
+}
+
+func (r *mongoScheduleRepository) ListPage(ctx context.Context, opts ListOptions) (Page[models.CourseSchedule], error) {
+	return findPage[models.CourseSchedule](ctx, r.coll, bson.M{}, opts)
+}
+
+func (r *mongoScheduleRepository) ListByCourseIDs(ctx context.Context, courseIDs []string) ([]models.CourseSchedule, error) {
+	if len(courseIDs) == 0 {
+		return []models.CourseSchedule{}, nil
+	}
+	return findAll[models.CourseSchedule](ctx, r.coll, bson.M{"courseId": bson.M{"$in": courseIDs}})
+}
+
+func (r *mongoScheduleRepository) FindByCourseID(ctx context.Context, courseID string) (models.CourseSchedule, error) {
+	return findOne[models.CourseSchedule](ctx, r.coll, bson.M{"courseId": courseID})
+}
+
+func (r *mongoScheduleRepository) AppendDates(ctx context.Context, courseID string, dates []string) error {
+	return updateOne(ctx, r.coll,
+		bson.M{"courseId": courseID},
+		bson.M{"$push": bson.M{"dates": bson.M{"$each": dates}}},
+	)
+}
+
+func (r *mongoScheduleRepository) Update(ctx context.Context, courseID string, times, dates []string) error {
+	return updateOne(ctx, r.coll,
+		bson.M{"courseId": courseID},
+		bson.M{"$set": bson.M{"time": times, "dates": dates}},
+	)
+}
+
+func (r *mongoScheduleRepository) Delete(ctx context.Context, courseID string) error {
+	return deleteOne(ctx, r.coll, bson.M{"courseId": courseID})
+}
+
+type mongoRegistrationRepository struct {
+	coll *mongo.Collection
+}
+
+func (r *mongoRegistrationRepository) Create(ctx context.Context, registration models.Registration) error {
+	return insertOne(ctx, r.coll, registration)
+}
+
+func (r *mongoRegistrationRepository) List(ctx context.Context) ([]models.Registration, error) {
+	return findAll[models.Registration](ctx, r.coll, bson.M{})
+}
+
+func (r *mongoRegistrationRepository) ListPage(ctx context.Context, filter RegistrationFilter, opts ListOptions) (Page[models.Registration], error) {
+	return findPage[models.Registration](ctx, r.coll, filter.query(), opts)
+}
+
+type memoryCourseRepository struct {
+	store *memoryStore[primitive.ObjectID, models.Course]
+	memoryTrash[models.Course, *models.Course]
+}
+
+func newMemoryCourseRepository() *memoryCourseRepository {
+	store := newMemoryStore(func(c models.Course) primitive.ObjectID { return c.ID })
+	return &memoryCourseRepository{store: store, memoryTrash: memoryTrash[models.Course, *models.Course]{store: store}}
+}
+
+func (r *memoryCourseRepository) Create(_ context.Context, course *models.Course) error {
+	if course.ID.IsZero() {
+		course.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*course)
+}
+
+func (r *memoryCourseRepository) List(_ context.Context) ([]models.Course, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memoryCourseRepository) ListPage(_ context.Context, filter CourseFilter, opts ListOptions) (Page[models.Course], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memoryCourseRepository) Search(_ context.Context, q string, limit int) ([]Scored[models.Course], error) {
+	return searchItems(r.store.list(nil), "courses", q, limit), nil
+}
+
+func (r *memoryCourseRepository) Get(_ context.Context, id primitive.ObjectID) (models.Course, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryCourseRepository) FindByRef(ctx context.Context, ref string) (models.Course, error) {
+	objID, err := primitive.ObjectIDFromHex(ref)
+	if err != nil {
+		return models.Course{}, ErrNotFound
+	}
+	return r.Get(ctx, objID)
+}
+
+func (r *memoryCourseRepository) FindByName(_ context.Context, name string) (models.Course, error) {
+	return r.store.find(func(c models.Course) bool { return c.Name == name })
+}
+
+func (r *memoryCourseRepository) GetMany(_ context.Context, ids []primitive.ObjectID) ([]models.Course, error) {
+	wanted := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.store.list(func(c models.Course) bool { return wanted[c.ID] }), nil
+}
+
+func (r *memoryCourseRepository) Update(_ context.Context, id primitive.ObjectID, course models.Course) error {
+	return r.store.update(id, func(c *models.Course) {
+		c.Name = course.Name
+		c.Duration = course.Duration
+		c.Cost = course.Cost
+		c.Description = course.Description
+		c.Schedule = course.Schedule
+		c.BillingPlan = course.BillingPlan
+	})
+}
+
+func (r *memoryCourseRepository) Count(_ context.Context) (int64, error) {
+	return int64(r.store.count() + len(r.store.listTrashed())), nil
+}
+
+type memoryScheduleRepository struct {
+	store *memoryStore[string, models.CourseSchedule]
+}
+
+func newMemoryScheduleRepository() *memoryScheduleRepository {
+	return &memoryScheduleRepository{store: newMemoryStore(func(s models.CourseSchedule) string { return s.CourseId })}
+}
+
+func (r *memoryScheduleRepository) Create(_ context.Context, schedule models.CourseSchedule) error {
+	return r.store.insert(schedule)
+}
+
+func (r *memoryScheduleRepository) List(_ context.Context) ([]models.CourseSchedule, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memoryScheduleRepository) ListPage(_ context.Context, opts ListOptions) (Page[models.CourseSchedule], error) {
+	return pageOf(r.store.list(nil), opts)
+}
+
+func (r *memoryScheduleRepository) ListByCourseIDs(_ context.Context, courseIDs []string) ([]models.CourseSchedule, error) {
+	return r.store.list(func(s models.CourseSchedule) bool { return containsString(courseIDs, s.CourseId) }), nil
+}
+
+func (r *memoryScheduleRepository) FindByCourseID(_ context.Context, courseID string) (models.CourseSchedule, error) {
+	return r.store.get(courseID)
+}
+
+func (r *memoryScheduleRepository) AppendDates(_ context.Context, courseID string, dates []string) error {
+	return r.store.update(courseID, func(s *models.CourseSchedule) {
+		s.Dates = append(append([]string{}, s.Dates...), dates...)
+	})
+}
+
+func (r *memoryScheduleRepository) Update(_ context.Context, courseID string, times, dates []string) error {
+	return r.store.update(courseID, func(s *models.CourseSchedule) {
+		s.Time = times
+		s.Dates = dates
+	})
+}
+
+func (r *memoryScheduleRepository) Delete(_ context.Context, courseID string) error {
+	return r.store.delete(courseID)
+}
+
+type memoryRegistrationRepository struct {
+	mu            sync.RWMutex
+	registrations []models.Registration
+}
+
+func (r *memoryRegistrationRepository) Create(_ context.Context, registration models.Registration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registrations = append(r.registrations, registration)
+	return nil
+}
+
+func (r *memoryRegistrationRepository) List(_ context.Context) ([]models.Registration, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.Registration{}, r.registrations...), nil
+}
+
+func (r *memoryRegistrationRepository) ListPage(_ context.Context, filter RegistrationFilter, opts ListOptions) (Page[models.Registration], error) {
+	r.mu.RLock()
+	matched := []models.Registration{}
+	for _, registration := range r.registrations {
+		if filter.match(registration) {
+			matched = append(matched, registration)
+		}
+	}
+	r.mu.RUnlock()
+	return pageOf(matched, opts)
+}
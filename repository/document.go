@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentRepository menyimpan nomor invoice dan kuitansi yang sudah diterbitkan
+type DocumentRepository interface {
+	// Create menyimpan dokumen baru. ErrDuplicate jika entitas itu sudah punya
+	// dokumen dengan jenis yang sama, atau nomor/kodenya sudah dipakai.
+	Create(ctx context.Context, doc *models.Document) error
+	// Find mengembalikan dokumen jenis kind milik sebuah entitas
+	Find(ctx context.Context, kind, entity string, entityID primitive.ObjectID) (models.Document, error)
+	FindByCode(ctx context.Context, code string) (models.Document, error)
+	// SetNumber memberi nomor pada dokumen yang belum bernomor. ErrConflict jika
+	// dokumen itu sudah diberi nomor lebih dulu.
+	SetNumber(ctx context.Context, id primitive.ObjectID, number string) error
+	// NextSeq menaikkan counter bernama name secara atomik dan mengembalikan
+	// nilai barunya, dimulai dari 1
+	NextSeq(ctx context.Context, name string) (int64, error)
+}
+
+type mongoDocumentRepository struct {
+	coll     *mongo.Collection
+	counters *mongo.Collection
+}
+
+func (r *mongoDocumentRepository) Create(ctx context.Context, doc *models.Document) error {
+	if doc.ID.IsZero() {
+		doc.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, doc)
+}
+
+func (r *mongoDocumentRepository) Find(ctx context.Context, kind, entity string, entityID primitive.ObjectID) (models.Document, error) {
+	return findOne[models.Document](ctx, r.coll, bson.M{"kind": kind, "entity": entity, "entity_id": entityID})
+}
+
+func (r *mongoDocumentRepository) FindByCode(ctx context.Context, code string) (models.Document, error) {
+	return findOne[models.Document](ctx, r.coll, bson.M{"code": code})
+}
+
+func (r *mongoDocumentRepository) SetNumber(ctx context.Context, id primitive.ObjectID, number string) error {
+	err := updateOne(ctx, r.coll,
+		bson.M{"_id": id, "number": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"number": number}},
+	)
+	if errors.Is(err, ErrNotFound) {
+		if _, err := findOne[models.Document](ctx, r.coll, bson.M{"_id": id}); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *mongoDocumentRepository) NextSeq(ctx context.Context, name string) (int64, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.counters.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$inc": bson.M{"seq": int64(1)}}, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		// Dua upsert pertama berjalan bersamaan; counter sudah dibuat proses lain
+		err = r.counters.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$inc": bson.M{"seq": int64(1)}}, opts).Decode(&counter)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+type memoryDocumentRepository struct {
+	store *memoryStore[primitive.ObjectID, models.Document]
+
+	mu       sync.Mutex
+	counters map[string]int64
+}
+
+func newMemoryDocumentRepository() *memoryDocumentRepository {
+	return &memoryDocumentRepository{
+		store:    newMemoryStore(func(d models.Document) primitive.ObjectID { return d.ID }),
+		counters: map[string]int64{},
+	}
+}
+
+func (r *memoryDocumentRepository) Create(_ context.Context, doc *models.Document) error {
+	if doc.ID.IsZero() {
+		doc.ID = primitive.NewObjectID()
+	}
+	// Sama dengan index unik di Mongo
+	_, err := r.store.find(func(d models.Document) bool {
+		return (d.Kind == doc.Kind && d.Entity == doc.Entity && d.EntityID == doc.EntityID) ||
+			(doc.Number != "" && d.Number == doc.Number) || d.Code == doc.Code
+	})
+	if err == nil {
+		return ErrDuplicate
+	}
+	return r.store.insert(*doc)
+}
+
+func (r *memoryDocumentRepository) Find(_ context.Context, kind, entity string, entityID primitive.ObjectID) (models.Document, error) {
+	return r.store.find(func(d models.Document) bool {
+		return d.Kind == kind && d.Entity == entity && d.EntityID == entityID
+	})
+}
+
+func (r *memoryDocumentRepository) FindByCode(_ context.Context, code string) (models.Document, error) {
+	return r.store.find(func(d models.Document) bool { return d.Code == code })
+}
+
+func (r *memoryDocumentRepository) SetNumber(_ context.Context, id primitive.ObjectID, number string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.store.find(func(d models.Document) bool { return d.Number == number }); err == nil {
+		return ErrDuplicate
+	}
+	numbered := false
+	err := r.store.update(id, func(d *models.Document) {
+		if d.Number == "" {
+			d.Number = number
+			numbered = true
+		}
+	})
+	if err == nil && !numbered {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memoryDocumentRepository) NextSeq(_ context.Context, name string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[name]++
+	return r.counters[name], nil
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GuruFilter membatasi hasil GuruRepository.List; field kosong berarti tidak difilter
+type GuruFilter struct {
+	Status        string
+	SchoolSubject string
+	// Q mencari di nama dan mata pelajaran; hanya dipakai ListPage
+	Q string
+}
+
+func (f GuruFilter) query() bson.M {
+	query := bson.M{}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	if f.SchoolSubject != "" {
+		query["school_subject"] = f.SchoolSubject
+	}
+	return query
+}
+
+func (f GuruFilter) match(g models.Guru) bool {
+	return (f.Status == "" || g.Status == f.Status) &&
+		(f.SchoolSubject == "" || g.SchoolSubject == f.SchoolSubject) &&
+		(f.Q == "" || textScore(g, "gurus", f.Q) > 0)
+}
+
+// GuruRepository mengelola data guru
+type GuruRepository interface {
+	Create(ctx context.Context, guru *models.Guru) error
+	List(ctx context.Context, filter GuruFilter) ([]models.Guru, error)
+	ListPage(ctx context.Context, filter GuruFilter, opts ListOptions) (Page[models.Guru], error)
+	// Search mencari guru berdasarkan nama atau mata pelajaran, paling relevan lebih dulu
+	Search(ctx context.Context, q string, limit int) ([]Scored[models.Guru], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Guru, error)
+	// Update mengganti semua field guru yang bisa diedit
+	Update(ctx context.Context, id primitive.ObjectID, guru models.Guru) error
+	Trash[models.Guru]
+}
+
+// TransaksiGuruFilter membatasi hasil TransaksiGuruRepository.List
+type TransaksiGuruFilter struct {
+	GuruID *primitive.ObjectID
+	// Period dalam format "MM-YYYY", dicocokkan dengan created_at "DD-MM-YYYY hh:mm:ss WIB"
+	Period string
+}
+
+func (f TransaksiGuruFilter) query() bson.M {
+	query := bson.M{}
+	if f.GuruID != nil {
+		query["guru_id"] = *f.GuruID
+	}
+	if f.Period != "" {
+		query["created_at"] = primitive.Regex{Pattern: `^\d{2}-` + regexp.QuoteMeta(f.Period)}
+	}
+	return query
+}
+
+func (f TransaksiGuruFilter) match(t models.TransaksiGuru) bool {
+	if f.GuruID != nil && t.GuruID != *f.GuruID {
+		return false
+	}
+	if f.Period != "" && (len(t.CreatedAt) < 3 || !strings.HasPrefix(t.CreatedAt[3:], f.Period)) {
+		return false
+	}
+	return true
+}
+
+// TransaksiGuruRepository mengelola transaksi gaji guru
+type TransaksiGuruRepository interface {
+	Create(ctx context.Context, transaksi *models.TransaksiGuru) error
+	List(ctx context.Context, filter TransaksiGuruFilter) ([]models.TransaksiGuru, error)
+	ListPage(ctx context.Context, filter TransaksiGuruFilter, opts ListOptions) (Page[models.TransaksiGuru], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.TransaksiGuru, error)
+	Update(ctx context.Context, id primitive.ObjectID, amount float64, notes string) error
+	Trash[models.TransaksiGuru]
+}
+
+type mongoGuruRepository struct {
+	coll *mongo.Collection
+	mongoTrash[models.Guru]
+}
+
+func (r *mongoGuruRepository) Create(ctx context.Context, guru *models.Guru) error {
+	if guru.ID.IsZero() {
+		guru.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, guru)
+}
+
+func (r *mongoGuruRepository) List(ctx context.Context, filter GuruFilter) ([]models.Guru, error) {
+	return findAll[models.Guru](ctx, r.coll, notDeleted(filter.query()))
+}
+
+func (r *mongoGuruRepository) ListPage(ctx context.Context, filter GuruFilter, opts ListOptions) (Page[models.Guru], error) {
+	return findTextPage[models.Guru](ctx, r.coll, notDeleted(filter.query()), filter.Q, opts)
+}
+
+func (r *mongoGuruRepository) Search(ctx context.Context, q string, limit int) ([]Scored[models.Guru], error) {
+	return textSearch[models.Guru](ctx, r.coll, notDeleted(bson.M{}), q, limit)
+}
+
+func (r *mongoGuruRepository) Get(ctx context.Context, id primitive.ObjectID) (models.Guru, error) {
+	return findOne[models.Guru](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoGuruRepository) Update(ctx context.Context, id primitive.ObjectID, guru models.Guru) error {
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{
+		"fullname":       guru.FullName,
+		"address":        guru.Address,
+		"phonenumber":    guru.PhoneNumber,
+		"email":          guru.Email,
+		"school_subject": guru.SchoolSubject,
+		"status":         guru.Status,
+	}})
+}
+
+type mongoTransaksiGuruRepository struct {
+	coll *mongo.Collection
+	mongoTrash[models.TransaksiGuru]
+}
+
+func (r *mongoTransaksiGuruRepository) Create(ctx context.Context, transaksi *models.TransaksiGuru) error {
+	if transaksi.ID.IsZero() {
+		transaksi.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, transaksi)
+}
+
+func (r *mongoTransaksiGuruRepository) List(ctx context.Context, filter TransaksiGuruFilter) ([]models.TransaksiGuru, error) {
+	return findAll[models.TransaksiGuru](ctx, r.coll, notDeleted(filter.query()))
+}
+
+func (r *mongoTransaksiGuruRepository) ListPage(ctx context.Context, filter TransaksiGuruFilter, opts ListOptions) (Page[models.TransaksiGuru], error) {
+	return findPage[models.TransaksiGuru](ctx, r.coll, notDeleted(filter.query()), opts)
+}
+
+func (r *mongoTransaksiGuruRepository) Get(ctx context.Context, id primitive.ObjectID) (models.TransaksiGuru, error) {
+	return findOne[models.TransaksiGuru](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoTransaksiGuruRepository) Update(ctx context.Context, id primitive.ObjectID, amount float64, notes string) error {
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{"amount": amount, "notes": notes}})
+}
+
+type memoryGuruRepository struct {
+	store *memoryStore[primitive.ObjectID, models.Guru]
+	memoryTrash[models.Guru, *models.Guru]
+}
+
+func newMemoryGuruRepository() *memoryGuruRepository {
+	store := newMemoryStore(func(g models.Guru) primitive.ObjectID { return g.ID })
+	return &memoryGuruRepository{store: store, memoryTrash: memoryTrash[models.Guru, *models.Guru]{store: store}}
+}
+
+func (r *memoryGuruRepository) Create(_ context.Context, guru *models.Guru) error {
+	if guru.ID.IsZero() {
+		guru.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*guru)
+}
+
+func (r *memoryGuruRepository) List(_ context.Context, filter GuruFilter) ([]models.Guru, error) {
+	return r.store.list(filter.match), nil
+}
+
+func (r *memoryGuruRepository) ListPage(_ context.Context, filter GuruFilter, opts ListOptions) (Page[models.Guru], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memoryGuruRepository) Search(_ context.Context, q string, limit int) ([]Scored[models.Guru], error) {
+	return searchItems(r.store.list(nil), "gurus", q, limit), nil
+}
+
+func (r *memoryGuruRepository) Get(_ context.Context, id primitive.ObjectID) (models.Guru, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryGuruRepository) Update(_ context.Context, id primitive.ObjectID, guru models.Guru) error {
+	return r.store.update(id, func(g *models.Guru) {
+		guru.ID = g.ID
+		*g = guru
+	})
+}
+
+type memoryTransaksiGuruRepository struct {
+	store *memoryStore[primitive.ObjectID, models.TransaksiGuru]
+	memoryTrash[models.TransaksiGuru, *models.TransaksiGuru]
+}
+
+func newMemoryTransaksiGuruRepository() *memoryTransaksiGuruRepository {
+	store := newMemoryStore(func(t models.TransaksiGuru) primitive.ObjectID { return t.ID })
+	return &memoryTransaksiGuruRepository{store: store, memoryTrash: memoryTrash[models.TransaksiGuru, *models.TransaksiGuru]{store: store}}
+}
+
+func (r *memoryTransaksiGuruRepository) Create(_ context.Context, transaksi *models.TransaksiGuru) error {
+	if transaksi.ID.IsZero() {
+		transaksi.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*transaksi)
+}
+
+func (r *memoryTransaksiGuruRepository) List(_ context.Context, filter TransaksiGuruFilter) ([]models.TransaksiGuru, error) {
+	return r.store.list(filter.match), nil
+}
+
+func (r *memoryTransaksiGuruRepository) ListPage(_ context.Context, filter TransaksiGuruFilter, opts ListOptions) (Page[models.TransaksiGuru], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memoryTransaksiGuruRepository) Get(_ context.Context, id primitive.ObjectID) (models.TransaksiGuru, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryTransaksiGuruRepository) Update(_ context.Context, id primitive.ObjectID, amount float64, notes string) error {
+	return r.store.update(id, func(t *models.TransaksiGuru) {
+		t.Amount = amount
+		t.Notes = notes
+	})
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidCursor dikembalikan jika ListOptions.Cursor bukan cursor dari halaman sebelumnya
+var ErrInvalidCursor = errors.New("repository: invalid cursor")
+
+// SortField adalah satu kunci pengurutan. Field memakai nama field bson.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions mengatur halaman hasil ListPage. Limit 0 berarti tanpa batas.
+// Jika Cursor diisi, Offset diabaikan dan halaman dimulai tepat setelah item
+// terakhir halaman sebelumnya, sehingga tidak bergeser saat ada data baru.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []SortField
+}
+
+// Page adalah satu halaman hasil ListPage
+type Page[T any] struct {
+	Items []T
+	Total int64 // Jumlah semua item yang cocok dengan filter, bukan hanya halaman ini
+	// NextCursor kosong jika ini halaman terakhir
+	NextCursor string
+}
+
+// pageCursor adalah isi cursor: urutan yang dipakai, nilai field sort dan kunci
+// pembeda dari item terakhir. Urutan ikut disimpan agar cursor tidak dipakai
+// dengan sort yang berbeda.
+type pageCursor struct {
+	Sort   string        `bson:"s"`
+	Values []interface{} `bson:"v"`
+	Key    interface{}   `bson:"k"`
+}
+
+func sortSignature(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(fields []SortField, values []interface{}, key interface{}) (string, error) {
+	data, err := bson.Marshal(pageCursor{Sort: sortSignature(fields), Values: values, Key: key})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string, fields []SortField) (pageCursor, error) {
+	var decoded pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return decoded, ErrInvalidCursor
+	}
+	err = bson.Unmarshal(data, &decoded)
+	if err != nil || decoded.Sort != sortSignature(fields) || len(decoded.Values) != len(fields) {
+		return decoded, ErrInvalidCursor
+	}
+	return decoded, nil
+}
+
+// sortValues mengambil nilai field sort dari dokumen; field yang tidak ada bernilai nil
+func sortValues(doc bson.Raw, fields []SortField) []interface{} {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		rv, err := doc.LookupErr(strings.Split(f.Field, ".")...)
+		if err != nil {
+			continue
+		}
+		_ = rv.Unmarshal(&values[i])
+	}
+	return values
+}
+
+// FindPage sama dengan findPage, untuk koleksi yang belum punya repository sendiri (users)
+func FindPage[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, opts ListOptions) (Page[T], error) {
+	return findPage[T](ctx, coll, filter, opts)
+}
+
+// findPage menjalankan query dengan paging, urutan sesuai opts.Sort lalu _id
+// sebagai pembeda agar urutan selalu stabil.
+func findPage[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, opts ListOptions) (Page[T], error) {
+	page := Page[T]{Items: []T{}}
+
+	total, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return page, err
+	}
+	page.Total = total
+
+	sortDoc := bson.D{}
+	for _, f := range opts.Sort {
+		sortDoc = append(sortDoc, bson.E{Key: f.Field, Value: direction(f.Desc)})
+	}
+	if !sortsByID(opts.Sort) {
+		sortDoc = append(sortDoc, bson.E{Key: "_id", Value: 1})
+	}
+
+	query := filter
+	findOpts := options.Find().SetSort(sortDoc)
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return page, err
+		}
+		query = bson.M{"$and": bson.A{filter, afterQuery(opts.Sort, after)}}
+	} else if opts.Offset > 0 {
+		findOpts.SetSkip(int64(opts.Offset))
+	}
+	if opts.Limit > 0 {
+		// Ambil satu item lebih untuk tahu apakah masih ada halaman berikutnya
+		findOpts.SetLimit(int64(opts.Limit) + 1)
+	}
+
+	cursor, err := coll.Find(ctx, query, findOpts)
+	if err != nil {
+		return page, err
+	}
+	defer cursor.Close(ctx)
+
+	var last bson.Raw
+	for cursor.Next(ctx) {
+		if opts.Limit > 0 && len(page.Items) == opts.Limit {
+			var key interface{}
+			_ = last.Lookup("_id").Unmarshal(&key)
+			page.NextCursor, err = encodeCursor(opts.Sort, sortValues(last, opts.Sort), key)
+			if err != nil {
+				return page, err
+			}
+			break
+		}
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+		last = append(bson.Raw{}, cursor.Current...)
+	}
+	return page, cursor.Err()
+}
+
+func sortsByID(fields []SortField) bool {
+	for _, f := range fields {
+		if f.Field == "_id" {
+			return true
+		}
+	}
+	return false
+}
+
+func direction(desc bool) int {
+	if desc {
+		return -1
+	}
+	return 1
+}
+
+// afterQuery membuat kondisi "sesudah cursor" untuk urutan multi-field:
+// (a > x) OR (a = x AND b > y) OR ... OR (semua sama AND _id > key).
+// Klausa terakhir tidak perlu jika _id sudah ada di urutan.
+func afterQuery(fields []SortField, after pageCursor) bson.M {
+	n := len(fields)
+	if sortsByID(fields) {
+		n--
+	}
+	or := bson.A{}
+	for i := 0; i <= n; i++ {
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[fields[j].Field] = after.Values[j]
+		}
+		if i < len(fields) {
+			op := "$gt"
+			if fields[i].Desc {
+				op = "$lt"
+			}
+			cond[fields[i].Field] = bson.M{op: after.Values[i]}
+		} else {
+			cond["_id"] = bson.M{"$gt": after.Key}
+		}
+		or = append(or, cond)
+	}
+	return bson.M{"$or": or}
+}
+
+// pageOf membuat halaman dari item yang sudah difilter, dipakai implementasi
+// in-memory. Posisi item di items menjadi pembeda untuk urutan yang sama.
+func pageOf[T any](items []T, opts ListOptions) (Page[T], error) {
+	page := Page[T]{Items: []T{}, Total: int64(len(items))}
+
+	type entry struct {
+		item   T
+		values []interface{}
+		key    int64
+	}
+	entries := make([]entry, len(items))
+	for i, item := range items {
+		doc, err := bson.Marshal(item)
+		if err != nil {
+			return page, err
+		}
+		entries[i] = entry{item: item, values: sortValues(doc, opts.Sort), key: int64(i)}
+	}
+
+	less := func(av []interface{}, ak int64, bv []interface{}, bk int64) bool {
+		for i, f := range opts.Sort {
+			if c := compareValues(av[i], bv[i]); c != 0 {
+				return (c < 0) != f.Desc
+			}
+		}
+		return ak < bk
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i].values, entries[i].key, entries[j].values, entries[j].key)
+	})
+
+	start := opts.Offset
+	if opts.Cursor != "" {
+		after, err := decodeCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return page, err
+		}
+		key, ok := after.Key.(int64)
+		if !ok {
+			return page, ErrInvalidCursor
+		}
+		start = sort.Search(len(entries), func(i int) bool {
+			return less(after.Values, key, entries[i].values, entries[i].key)
+		})
+	}
+	if start > len(entries) {
+		start = len(entries)
+	}
+
+	end := len(entries)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+		last := entries[end-1]
+		cursor, err := encodeCursor(opts.Sort, last.values, last.key)
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = cursor
+	}
+	for _, e := range entries[start:end] {
+		page.Items = append(page.Items, e.item)
+	}
+	return page, nil
+}
+
+// compareValues membandingkan dua nilai bson dengan tipe yang sama.
+// nil selalu lebih kecil, sama seperti urutan MongoDB.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case primitive.ObjectID:
+		if bv, ok := b.(primitive.ObjectID); ok {
+			return bytes.Compare(av[:], bv[:])
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareOrdered(boolInt(av), boolInt(bv))
+		}
+	case primitive.DateTime:
+		if bv, ok := b.(primitive.DateTime); ok {
+			return compareOrdered(av, bv)
+		}
+	}
+	if af, ok := toFloat(a); ok {
+		if bf, ok := toFloat(b); ok {
+			return compareOrdered(af, bf)
+		}
+	}
+	return 0
+}
+
+func compareOrdered[N int | float64 | primitive.DateTime](a, b N) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedSiswa menyimpan siswa dengan nama yang sebagian sama, agar urutan
+// harus dibedakan oleh kunci pembeda di cursor
+func seedSiswa(t *testing.T, repos *repository.Repositories) {
+	t.Helper()
+	names := []string{"Citra", "Andi", "Budi", "Andi", "Dewi", "Budi", "Andi", "Eka"}
+	statuses := []string{models.SiswaStatusActive, models.SiswaStatusInactive}
+	for i, name := range names {
+		siswa := models.Siswa{FullName: name, Status: statuses[i%len(statuses)]}
+		if err := repos.Siswa.Create(context.Background(), &siswa); err != nil {
+			t.Fatalf("create siswa: %v", err)
+		}
+	}
+}
+
+func TestListPageCursorRoundTrip(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	seedSiswa(t, repos)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		sort  []repository.SortField
+		limit int
+	}{
+		{name: "insert order", limit: 3},
+		{name: "name ascending", sort: []repository.SortField{{Field: "fullname"}}, limit: 3},
+		{name: "name descending", sort: []repository.SortField{{Field: "fullname", Desc: true}}, limit: 2},
+		{name: "status then name", sort: []repository.SortField{{Field: "status"}, {Field: "fullname", Desc: true}}, limit: 3},
+		{name: "one per page", sort: []repository.SortField{{Field: "fullname"}}, limit: 1},
+		{name: "single page", sort: []repository.SortField{{Field: "fullname"}}, limit: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all, err := repos.Siswa.ListPage(ctx, repository.SiswaFilter{}, repository.ListOptions{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("ListPage: %v", err)
+			}
+			if all.NextCursor != "" {
+				t.Errorf("unlimited page has a next cursor")
+			}
+
+			var walked []primitive.ObjectID
+			opts := repository.ListOptions{Limit: tt.limit, Sort: tt.sort}
+			for pages := 0; ; pages++ {
+				if pages > len(all.Items) {
+					t.Fatalf("cursor did not reach the last page")
+				}
+				page, err := repos.Siswa.ListPage(ctx, repository.SiswaFilter{}, opts)
+				if err != nil {
+					t.Fatalf("ListPage page %d: %v", pages, err)
+				}
+				if page.Total != int64(len(all.Items)) {
+					t.Errorf("page %d: total = %d, want %d", pages, page.Total, len(all.Items))
+				}
+				if len(page.Items) > tt.limit {
+					t.Errorf("page %d: %d items, limit %d", pages, len(page.Items), tt.limit)
+				}
+				for _, siswa := range page.Items {
+					walked = append(walked, siswa.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				opts.Cursor = page.NextCursor
+			}
+
+			if len(walked) != len(all.Items) {
+				t.Fatalf("walked %d items, want %d", len(walked), len(all.Items))
+			}
+			for i, siswa := range all.Items {
+				if walked[i] != siswa.ID {
+					t.Errorf("item %d: got %s (%s), want %s", i, walked[i].Hex(), siswa.FullName, siswa.ID.Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestListPageRejectsForeignCursor(t *testing.T) {
+	repos := repository.NewMemoryRepositories()
+	seedSiswa(t, repos)
+	ctx := context.Background()
+
+	byName := []repository.SortField{{Field: "fullname"}}
+	first, err := repos.Siswa.ListPage(ctx, repository.SiswaFilter{}, repository.ListOptions{Limit: 2, Sort: byName})
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	if first.NextCursor == "" {
+		t.Fatalf("first page has no next cursor")
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   []repository.SortField
+	}{
+		{name: "different field", cursor: first.NextCursor, sort: []repository.SortField{{Field: "status"}}},
+		{name: "different direction", cursor: first.NextCursor, sort: []repository.SortField{{Field: "fullname", Desc: true}}},
+		{name: "no sort", cursor: first.NextCursor},
+		{name: "not base64", cursor: "not a cursor!", sort: byName},
+		{name: "not bson", cursor: "aGVsbG8", sort: byName},
+	}
+	for _, tt := range tests {
+		_, err := repos.Siswa.ListPage(ctx, repository.SiswaFilter{}, repository.ListOptions{Limit: 2, Cursor: tt.cursor, Sort: tt.sort})
+		if !errors.Is(err, repository.ErrInvalidCursor) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, repository.ErrInvalidCursor)
+		}
+	}
+}
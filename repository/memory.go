@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"sync"
+)
+
+// memoryStore adalah penyimpanan in-memory generik yang menjaga urutan insert,
+// dipakai oleh implementasi repository untuk pengujian. Item yang berada di
+// trash (lihat models.SoftDelete) dilewati oleh semua method kecuali yang
+// namanya menyebut trash.
+type memoryStore[K comparable, T any] struct {
+	mu    sync.RWMutex
+	key   func(T) K
+	order []K
+	items map[K]T
+}
+
+func newMemoryStore[K comparable, T any](key func(T) K) *memoryStore[K, T] {
+	return &memoryStore[K, T]{key: key, items: map[K]T{}}
+}
+
+func (s *memoryStore[K, T]) insert(item T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.key(item)
+	if _, exists := s.items[k]; exists {
+		return ErrDuplicate
+	}
+	s.items[k] = item
+	s.order = append(s.order, k)
+	return nil
+}
+
+func (s *memoryStore[K, T]) get(k K) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	item, ok := s.items[k]
+	if !ok || isTrashed(item) {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return item, nil
+}
+
+// find mengembalikan item pertama yang cocok
+func (s *memoryStore[K, T]) find(match func(T) bool) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.order {
+		if item := s.items[k]; !isTrashed(item) && match(item) {
+			return item, nil
+		}
+	}
+	var zero T
+	return zero, ErrNotFound
+}
+
+// list mengembalikan semua item yang cocok sesuai urutan insert; match nil berarti semua
+func (s *memoryStore[K, T]) list(match func(T) bool) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := []T{}
+	for _, k := range s.order {
+		if item := s.items[k]; !isTrashed(item) && (match == nil || match(item)) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// listTrashed mengembalikan semua item di trash sesuai urutan insert
+func (s *memoryStore[K, T]) listTrashed() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := []T{}
+	for _, k := range s.order {
+		if item := s.items[k]; isTrashed(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// update menerapkan fn pada item dengan kunci k
+func (s *memoryStore[K, T]) update(k K, fn func(*T)) error {
+	return s.updateWhere(k, false, fn)
+}
+
+// updateTrashed sama dengan update, tetapi untuk item yang berada di trash
+func (s *memoryStore[K, T]) updateTrashed(k K, fn func(*T)) error {
+	return s.updateWhere(k, true, fn)
+}
+
+func (s *memoryStore[K, T]) updateWhere(k K, trashed bool, fn func(*T)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[k]
+	if !ok || isTrashed(item) != trashed {
+		return ErrNotFound
+	}
+	fn(&item)
+	s.items[k] = item
+	return nil
+}
+
+func (s *memoryStore[K, T]) delete(k K) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[k]; !ok || isTrashed(item) {
+		return ErrNotFound
+	}
+	s.remove(k)
+	return nil
+}
+
+// purge menghapus permanen semua item yang cocok dan mengembalikan jumlahnya
+func (s *memoryStore[K, T]) purge(match func(T) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int
+	for _, k := range append([]K{}, s.order...) {
+		if match(s.items[k]) {
+			s.remove(k)
+			purged++
+		}
+	}
+	return purged
+}
+
+// remove menghapus item; pemanggil harus memegang s.mu
+func (s *memoryStore[K, T]) remove(k K) {
+	delete(s.items, k)
+	for i, existing := range s.order {
+		if existing == k {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *memoryStore[K, T]) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var n int
+	for _, item := range s.items {
+		if !isTrashed(item) {
+			n++
+		}
+	}
+	return n
+}
+
+// isTrashed mengecek apakah item menyisipkan models.SoftDelete dan sedang di trash
+func isTrashed(item interface{}) bool {
+	deletable, ok := item.(interface{ IsDeleted() bool })
+	return ok && deletable.IsDeleted()
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findAll menjalankan query dan men-decode semua hasilnya. Hasil kosong
+// dikembalikan sebagai slice kosong, bukan nil.
+func findAll[T any](ctx context.Context, coll *mongo.Collection, filter interface{}, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := coll.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// findOne mengambil satu dokumen, mengubah mongo.ErrNoDocuments menjadi ErrNotFound
+func findOne[T any](ctx context.Context, coll *mongo.Collection, filter interface{}) (T, error) {
+	var item T
+	err := coll.FindOne(ctx, filter).Decode(&item)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return item, ErrNotFound
+	}
+	return item, err
+}
+
+// updateOne menjalankan update dan mengembalikan ErrNotFound jika tidak ada dokumen yang cocok
+func updateOne(ctx context.Context, coll *mongo.Collection, filter, update interface{}, opts ...*options.UpdateOptions) error {
+	result, err := coll.UpdateOne(ctx, filter, update, opts...)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// deleteOne menghapus satu dokumen dan mengembalikan ErrNotFound jika tidak ada yang terhapus
+func deleteOne(ctx context.Context, coll *mongo.Collection, filter interface{}) error {
+	result, err := coll.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// insertOne menyimpan dokumen baru dan mengubah duplicate key error menjadi ErrDuplicate
+func insertOne(ctx context.Context, coll *mongo.Collection, doc interface{}) error {
+	_, err := coll.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PaymentRepository menyimpan ledger pembayaran tagihan. Ledger hanya bisa
+// ditambah; koreksi dicatat sebagai entri refund atau void.
+type PaymentRepository interface {
+	// Create menambahkan entri; ErrDuplicate jika referensi pembayaran sudah
+	// dipakai di tagihan yang sama atau entri yang di-void sudah pernah di-void.
+	// ErrConflict jika Seq sudah dipakai entri lain, artinya ledger berubah
+	// sejak dibaca dan pengecekan saldo pemanggil sudah tidak berlaku.
+	Create(ctx context.Context, payment *models.Payment) error
+	Get(ctx context.Context, id primitive.ObjectID) (models.Payment, error)
+	// ListByTagihan mengembalikan semua entri satu tagihan, urut waktu dicatat
+	ListByTagihan(ctx context.Context, tagihanID primitive.ObjectID) ([]models.Payment, error)
+}
+
+// PaymentSeqIndex adalah nama index unik (tagihan_id, seq) di koleksi payments.
+// Create memakainya untuk membedakan ledger yang berubah dari referensi ganda.
+const PaymentSeqIndex = "tagihan_id_seq_unique"
+
+type mongoPaymentRepository struct {
+	coll *mongo.Collection
+}
+
+func (r *mongoPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	_, err := r.coll.InsertOne(ctx, payment)
+	if mongo.IsDuplicateKeyError(err) {
+		if strings.Contains(err.Error(), PaymentSeqIndex) {
+			return ErrConflict
+		}
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoPaymentRepository) Get(ctx context.Context, id primitive.ObjectID) (models.Payment, error) {
+	return findOne[models.Payment](ctx, r.coll, bson.M{"_id": id})
+}
+
+func (r *mongoPaymentRepository) ListByTagihan(ctx context.Context, tagihanID primitive.ObjectID) ([]models.Payment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	return findAll[models.Payment](ctx, r.coll, bson.M{"tagihan_id": tagihanID}, opts)
+}
+
+type memoryPaymentRepository struct {
+	store *memoryStore[primitive.ObjectID, models.Payment]
+}
+
+func newMemoryPaymentRepository() *memoryPaymentRepository {
+	return &memoryPaymentRepository{store: newMemoryStore(func(p models.Payment) primitive.ObjectID { return p.ID })}
+}
+
+func (r *memoryPaymentRepository) Create(_ context.Context, payment *models.Payment) error {
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	// Sama dengan index unik di koleksi payments
+	_, err := r.store.find(func(p models.Payment) bool {
+		return payment.Seq != 0 && p.TagihanID == payment.TagihanID && p.Seq == payment.Seq
+	})
+	if err == nil {
+		return ErrConflict
+	}
+	_, err = r.store.find(func(p models.Payment) bool {
+		if p.TagihanID != payment.TagihanID {
+			return false
+		}
+		if payment.VoidOf != nil && p.VoidOf != nil && *p.VoidOf == *payment.VoidOf {
+			return true
+		}
+		return payment.Kind == models.PaymentKindPayment && p.Kind == models.PaymentKindPayment &&
+			payment.Reference != "" && p.Reference == payment.Reference
+	})
+	if err == nil {
+		return ErrDuplicate
+	}
+	return r.store.insert(*payment)
+}
+
+func (r *memoryPaymentRepository) Get(_ context.Context, id primitive.ObjectID) (models.Payment, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryPaymentRepository) ListByTagihan(_ context.Context, tagihanID primitive.ObjectID) ([]models.Payment, error) {
+	return r.store.list(func(p models.Payment) bool { return p.TagihanID == tagihanID }), nil
+}
@@ -0,0 +1,82 @@
+// Package repository memisahkan akses MongoDB dari controller. Setiap agregat
+// punya interface sendiri dengan implementasi Mongo untuk production dan
+// implementasi in-memory agar handler bisa diuji tanpa database.
+package repository
+
+import (
+	"errors"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrNotFound dikembalikan jika dokumen yang dicari tidak ada
+var ErrNotFound = errors.New("repository: not found")
+
+// ErrDuplicate dikembalikan jika dokumen dengan kunci yang sama sudah ada
+var ErrDuplicate = errors.New("repository: duplicate key")
+
+// ErrConflict dikembalikan jika dokumen sudah diubah proses lain sehingga update dibatalkan
+var ErrConflict = errors.New("repository: conflicting update")
+
+// Repositories mengumpulkan semua repository yang dipakai controller
+type Repositories struct {
+	Siswa          SiswaRepository
+	TransaksiSiswa TransaksiSiswaRepository
+	Guru           GuruRepository
+	Tagihan        TagihanRepository
+	TransaksiGuru  TransaksiGuruRepository
+	Course         CourseRepository
+	Schedule       ScheduleRepository
+	Registration   RegistrationRepository
+	Payment        PaymentRepository
+	Billing        BillingScheduleRepository
+	Document       DocumentRepository
+	Audit          AuditRepository
+}
+
+// NewMongoRepositories membuat semua repository di atas database MongoDB
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	siswa := db.Collection("siswa")
+	transaksiSiswa := db.Collection("transaksi_siswa")
+	gurus := db.Collection("gurus")
+	tagihans := db.Collection("tagihans")
+	transaksiGuru := db.Collection("transaksi_guru")
+	courses := db.Collection("courses")
+	payments := db.Collection("payments")
+	documents := db.Collection("documents")
+	return withAudit(&Repositories{
+		Siswa:          &mongoSiswaRepository{coll: siswa, mongoTrash: mongoTrash[models.Siswa]{siswa}},
+		TransaksiSiswa: &mongoTransaksiSiswaRepository{coll: transaksiSiswa, mongoTrash: mongoTrash[models.TransaksiSiswa]{transaksiSiswa}},
+		Guru:           &mongoGuruRepository{coll: gurus, mongoTrash: mongoTrash[models.Guru]{gurus}},
+		Tagihan:        &mongoTagihanRepository{coll: tagihans, payments: payments, documents: documents, mongoTrash: mongoTrash[models.Tagihan]{tagihans}},
+		TransaksiGuru:  &mongoTransaksiGuruRepository{coll: transaksiGuru, mongoTrash: mongoTrash[models.TransaksiGuru]{transaksiGuru}},
+		Course:         &mongoCourseRepository{coll: courses, mongoTrash: mongoTrash[models.Course]{courses}},
+		Schedule:       &mongoScheduleRepository{coll: db.Collection("course_schedules")},
+		Registration:   &mongoRegistrationRepository{coll: db.Collection("course_registrations")},
+		Payment:        &mongoPaymentRepository{coll: payments},
+		Billing:        &mongoBillingScheduleRepository{coll: db.Collection("billing_schedules")},
+		Document:       &mongoDocumentRepository{coll: documents, counters: db.Collection("counters")},
+		Audit:          &mongoAuditRepository{coll: db.Collection("audit_log")},
+	})
+}
+
+// NewMemoryRepositories membuat semua repository dalam memori, untuk pengujian
+func NewMemoryRepositories() *Repositories {
+	payments := newMemoryPaymentRepository()
+	documents := newMemoryDocumentRepository()
+	return withAudit(&Repositories{
+		Siswa:          newMemorySiswaRepository(),
+		TransaksiSiswa: newMemoryTransaksiSiswaRepository(),
+		Guru:           newMemoryGuruRepository(),
+		Tagihan:        newMemoryTagihanRepository(payments, documents),
+		TransaksiGuru:  newMemoryTransaksiGuruRepository(),
+		Course:         newMemoryCourseRepository(),
+		Schedule:       newMemoryScheduleRepository(),
+		Registration:   &memoryRegistrationRepository{},
+		Payment:        payments,
+		Billing:        newMemoryBillingScheduleRepository(),
+		Document:       documents,
+		Audit:          newMemoryAuditRepository(),
+	})
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Scored adalah satu hasil pencarian; Score lebih besar berarti lebih relevan
+type Scored[T any] struct {
+	Item  T
+	Score float64
+}
+
+// textField adalah field yang ikut dicari beserta bobotnya di index teks
+type textField struct {
+	name   string
+	weight int
+}
+
+// textIndexes adalah field yang dicari per koleksi. Nama lebih berbobot
+// daripada field lain supaya kecocokan nama tampil paling atas.
+var textIndexes = map[string][]textField{
+	"siswa":   {{"fullname", 10}, {"email", 5}, {"phonenumber", 5}},
+	"gurus":   {{"fullname", 10}, {"school_subject", 3}},
+	"courses": {{"name", 10}, {"description", 2}},
+}
+
+// textIndexName dipakai agar index bisa dikenali dan dibuat ulang dengan aman
+const textIndexName = "search_text"
+
+// EnsureSearchIndexes membuat index teks untuk pencarian. Bahasa index "none"
+// karena data berisi nama orang dan teks bahasa Indonesia, jadi stemming
+// bahasa Inggris justru merusak hasil. Aman dijalankan berulang kali.
+func EnsureSearchIndexes(ctx context.Context, db *mongo.Database) error {
+	for name, fields := range textIndexes {
+		keys := bson.D{}
+		weights := bson.D{}
+		for _, f := range fields {
+			keys = append(keys, bson.E{Key: f.name, Value: "text"})
+			weights = append(weights, bson.E{Key: f.name, Value: f.weight})
+		}
+		_, err := db.Collection(name).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    keys,
+			Options: options.Index().SetName(textIndexName).SetWeights(weights).SetDefaultLanguage("none"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textSearch mencari dokumen yang cocok dengan filter memakai index teks dan
+// mengurutkannya berdasarkan skor. Index teks hanya mencocokkan kata utuh, jadi jika tidak ada hasil
+// pencarian diulang dengan potongan kata (regex) supaya "bud" atau potongan
+// nomor telepon tetap ketemu; hasil cara kedua diberi skor 0.
+func textSearch[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, q string, limit int) ([]Scored[T], error) {
+	if strings.TrimSpace(q) == "" {
+		return []Scored[T]{}, nil
+	}
+	findOpts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(int64(limit))
+	results, err := findScored[T](ctx, coll, bson.M{"$and": bson.A{filter, bson.M{"$text": bson.M{"$search": q}}}}, findOpts)
+	if err != nil || len(results) > 0 {
+		return results, err
+	}
+	return findScored[T](ctx, coll, bson.M{"$and": bson.A{filter, partialQuery(coll.Name(), q)}}, options.Find().SetLimit(int64(limit)))
+}
+
+func findScored[T any](ctx context.Context, coll *mongo.Collection, filter interface{}, opts *options.FindOptions) ([]Scored[T], error) {
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []Scored[T]{}
+	for cursor.Next(ctx) {
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return nil, err
+		}
+		score, _ := cursor.Current.Lookup("score").DoubleOK()
+		results = append(results, Scored[T]{Item: item, Score: score})
+	}
+	return results, cursor.Err()
+}
+
+// findTextPage sama dengan findPage ditambah filter pencarian q dengan aturan
+// yang sama seperti textSearch: kata utuh lewat index teks, lalu potongan kata.
+func findTextPage[T any](ctx context.Context, coll *mongo.Collection, filter bson.M, q string, opts ListOptions) (Page[T], error) {
+	if strings.TrimSpace(q) == "" {
+		return findPage[T](ctx, coll, filter, opts)
+	}
+	page, err := findPage[T](ctx, coll, bson.M{"$and": bson.A{filter, bson.M{"$text": bson.M{"$search": q}}}}, opts)
+	if err != nil || page.Total > 0 {
+		return page, err
+	}
+	return findPage[T](ctx, coll, bson.M{"$and": bson.A{filter, partialQuery(coll.Name(), q)}}, opts)
+}
+
+// partialQuery mencocokkan setiap kata q sebagai potongan teks di salah satu field pencarian
+func partialQuery(collection, q string) bson.M {
+	and := bson.A{}
+	for _, term := range strings.Fields(q) {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(term), Options: "i"}
+		or := bson.A{}
+		for _, f := range textIndexes[collection] {
+			or = append(or, bson.M{f.name: pattern})
+		}
+		and = append(and, bson.M{"$or": or})
+	}
+	return bson.M{"$and": and}
+}
+
+// textScore menilai item untuk implementasi in-memory: jumlah bobot field
+// yang mengandung setiap kata q. Nilai 0 berarti tidak cocok.
+func textScore(item interface{}, collection, q string) float64 {
+	doc, err := bson.Marshal(item)
+	if err != nil {
+		return 0
+	}
+	var score float64
+	for _, term := range strings.Fields(strings.ToLower(q)) {
+		matched := false
+		for _, f := range textIndexes[collection] {
+			value, ok := bson.Raw(doc).Lookup(f.name).StringValueOK()
+			if ok && strings.Contains(strings.ToLower(value), term) {
+				score += float64(f.weight)
+				matched = true
+			}
+		}
+		if !matched {
+			return 0
+		}
+	}
+	return score
+}
+
+// searchItems adalah versi in-memory dari textSearch
+func searchItems[T any](items []T, collection, q string, limit int) []Scored[T] {
+	results := []Scored[T]{}
+	for _, item := range items {
+		if score := textScore(item, collection, q); score > 0 {
+			results = append(results, Scored[T]{Item: item, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SiswaFilter membatasi hasil SiswaRepository.ListPage; field kosong berarti tidak difilter
+type SiswaFilter struct {
+	Status string
+	// Q mencari di nama, email dan nomor telepon
+	Q string
+}
+
+func (f SiswaFilter) query() bson.M {
+	query := bson.M{}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (f SiswaFilter) match(s models.Siswa) bool {
+	return (f.Status == "" || s.Status == f.Status) && (f.Q == "" || textScore(s, "siswa", f.Q) > 0)
+}
+
+// TransaksiSiswaFilter membatasi hasil TransaksiSiswaRepository.ListPage
+type TransaksiSiswaFilter struct {
+	SiswaID *primitive.ObjectID
+	Status  string
+}
+
+func (f TransaksiSiswaFilter) query() bson.M {
+	query := bson.M{}
+	if f.SiswaID != nil {
+		query["siswa_id"] = *f.SiswaID
+	}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	return query
+}
+
+func (f TransaksiSiswaFilter) match(t models.TransaksiSiswa) bool {
+	return (f.SiswaID == nil || t.SiswaID == *f.SiswaID) && (f.Status == "" || t.Status == f.Status)
+}
+
+// SiswaRepository mengelola data siswa
+type SiswaRepository interface {
+	// Create menyimpan siswa baru; ID dibuat otomatis jika kosong
+	Create(ctx context.Context, siswa *models.Siswa) error
+	List(ctx context.Context) ([]models.Siswa, error)
+	ListPage(ctx context.Context, filter SiswaFilter, opts ListOptions) (Page[models.Siswa], error)
+	// Search mencari siswa berdasarkan nama, email atau telepon, paling relevan lebih dulu
+	Search(ctx context.Context, q string, limit int) ([]Scored[models.Siswa], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Siswa, error)
+	// Update mengganti field yang bisa diedit (nama, alamat, telepon, email, status)
+	Update(ctx context.Context, id primitive.ObjectID, siswa models.Siswa) error
+	SetStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	Trash[models.Siswa]
+}
+
+// TransaksiSiswaRepository mengelola transaksi pembayaran siswa
+type TransaksiSiswaRepository interface {
+	Create(ctx context.Context, transaksi *models.TransaksiSiswa) error
+	List(ctx context.Context) ([]models.TransaksiSiswa, error)
+	ListPage(ctx context.Context, filter TransaksiSiswaFilter, opts ListOptions) (Page[models.TransaksiSiswa], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.TransaksiSiswa, error)
+	// SetStatus mengubah status; PaidAt ikut diisi saat status menjadi paid
+	SetStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	Trash[models.TransaksiSiswa]
+}
+
+type mongoSiswaRepository struct {
+	coll *mongo.Collection
+	mongoTrash[models.Siswa]
+}
+
+func (r *mongoSiswaRepository) Create(ctx context.Context, siswa *models.Siswa) error {
+	if siswa.ID.IsZero() {
+		siswa.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, siswa)
+}
+
+func (r *mongoSiswaRepository) List(ctx context.Context) ([]models.Siswa, error) {
+	return findAll[models.Siswa](ctx, r.coll, notDeleted(bson.M{}))
+}
+
+func (r *mongoSiswaRepository) ListPage(ctx context.Context, filter SiswaFilter, opts ListOptions) (Page[models.Siswa], error) {
+	return findTextPage[models.Siswa](ctx, r.coll, notDeleted(filter.query()), filter.Q, opts)
+}
+
+func (r *mongoSiswaRepository) Search(ctx context.Context, q string, limit int) ([]Scored[models.Siswa], error) {
+	return textSearch[models.Siswa](ctx, r.coll, notDeleted(bson.M{}), q, limit)
+}
+
+func (r *mongoSiswaRepository) Get(ctx context.Context, id primitive.ObjectID) (models.Siswa, error) {
+	return findOne[models.Siswa](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoSiswaRepository) Update(ctx context.Context, id primitive.ObjectID, siswa models.Siswa) error {
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{
+		"fullname":    siswa.FullName,
+		"address":     siswa.Address,
+		"phonenumber": siswa.PhoneNumber,
+		"email":       siswa.Email,
+		"status":      siswa.Status,
+	}})
+}
+
+func (r *mongoSiswaRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": bson.M{"status": status}})
+}
+
+type mongoTransaksiSiswaRepository struct {
+	coll *mongo.Collection
+	mongoTrash[models.TransaksiSiswa]
+}
+
+func (r *mongoTransaksiSiswaRepository) Create(ctx context.Context, transaksi *models.TransaksiSiswa) error {
+	if transaksi.ID.IsZero() {
+		transaksi.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, transaksi)
+}
+
+func (r *mongoTransaksiSiswaRepository) List(ctx context.Context) ([]models.TransaksiSiswa, error) {
+	return findAll[models.TransaksiSiswa](ctx, r.coll, notDeleted(bson.M{}))
+}
+
+func (r *mongoTransaksiSiswaRepository) ListPage(ctx context.Context, filter TransaksiSiswaFilter, opts ListOptions) (Page[models.TransaksiSiswa], error) {
+	return findPage[models.TransaksiSiswa](ctx, r.coll, notDeleted(filter.query()), opts)
+}
+
+func (r *mongoTransaksiSiswaRepository) Get(ctx context.Context, id primitive.ObjectID) (models.TransaksiSiswa, error) {
+	return findOne[models.TransaksiSiswa](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoTransaksiSiswaRepository) SetStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	set := bson.M{"status": status}
+	if status == models.TransaksiStatusPaid {
+		set["paid_at"] = primitive.NewDateTimeFromTime(time.Now())
+	}
+	return updateOne(ctx, r.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": set})
+}
+
+type memorySiswaRepository struct {
+	store *memoryStore[primitive.ObjectID, models.Siswa]
+	memoryTrash[models.Siswa, *models.Siswa]
+}
+
+func newMemorySiswaRepository() *memorySiswaRepository {
+	store := newMemoryStore(func(s models.Siswa) primitive.ObjectID { return s.ID })
+	return &memorySiswaRepository{store: store, memoryTrash: memoryTrash[models.Siswa, *models.Siswa]{store: store}}
+}
+
+func (r *memorySiswaRepository) Create(_ context.Context, siswa *models.Siswa) error {
+	if siswa.ID.IsZero() {
+		siswa.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*siswa)
+}
+
+func (r *memorySiswaRepository) List(_ context.Context) ([]models.Siswa, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memorySiswaRepository) ListPage(_ context.Context, filter SiswaFilter, opts ListOptions) (Page[models.Siswa], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memorySiswaRepository) Search(_ context.Context, q string, limit int) ([]Scored[models.Siswa], error) {
+	return searchItems(r.store.list(nil), "siswa", q, limit), nil
+}
+
+func (r *memorySiswaRepository) Get(_ context.Context, id primitive.ObjectID) (models.Siswa, error) {
+	return r.store.get(id)
+}
+
+func (r *memorySiswaRepository) Update(_ context.Context, id primitive.ObjectID, siswa models.Siswa) error {
+	return r.store.update(id, func(s *models.Siswa) {
+		s.FullName = siswa.FullName
+		s.Address = siswa.Address
+		s.PhoneNumber = siswa.PhoneNumber
+		s.Email = siswa.Email
+		s.Status = siswa.Status
+	})
+}
+
+func (r *memorySiswaRepository) SetStatus(_ context.Context, id primitive.ObjectID, status string) error {
+	return r.store.update(id, func(s *models.Siswa) { s.Status = status })
+}
+
+type memoryTransaksiSiswaRepository struct {
+	store *memoryStore[primitive.ObjectID, models.TransaksiSiswa]
+	memoryTrash[models.TransaksiSiswa, *models.TransaksiSiswa]
+}
+
+func newMemoryTransaksiSiswaRepository() *memoryTransaksiSiswaRepository {
+	store := newMemoryStore(func(t models.TransaksiSiswa) primitive.ObjectID { return t.ID })
+	return &memoryTransaksiSiswaRepository{store: store, memoryTrash: memoryTrash[models.TransaksiSiswa, *models.TransaksiSiswa]{store: store}}
+}
+
+func (r *memoryTransaksiSiswaRepository) Create(_ context.Context, transaksi *models.TransaksiSiswa) error {
+	if transaksi.ID.IsZero() {
+		transaksi.ID = primitive.NewObjectID()
+	}
+	return r.store.insert(*transaksi)
+}
+
+func (r *memoryTransaksiSiswaRepository) List(_ context.Context) ([]models.TransaksiSiswa, error) {
+	return r.store.list(nil), nil
+}
+
+func (r *memoryTransaksiSiswaRepository) ListPage(_ context.Context, filter TransaksiSiswaFilter, opts ListOptions) (Page[models.TransaksiSiswa], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memoryTransaksiSiswaRepository) Get(_ context.Context, id primitive.ObjectID) (models.TransaksiSiswa, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryTransaksiSiswaRepository) SetStatus(_ context.Context, id primitive.ObjectID, status string) error {
+	return r.store.update(id, func(t *models.TransaksiSiswa) {
+		t.Status = status
+		if status == models.TransaksiStatusPaid {
+			paidAt := primitive.NewDateTimeFromTime(time.Now())
+			t.PaidAt = &paidAt
+		}
+	})
+}
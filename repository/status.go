@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// statusCollections adalah koleksi yang dulu menyimpan status sebagai teks bahasa Indonesia
+var statusCollections = []string{"siswa", "gurus", "tagihans", "transaksi_siswa"}
+
+// NormalizeStatuses mengubah nilai status lama ("aktif", "Belum Bayar", ...) di database
+// menjadi kode status yang stabil. Aman dijalankan berulang kali.
+func NormalizeStatuses(ctx context.Context, db *mongo.Database) (int64, error) {
+	var total int64
+	codes := []string{models.SiswaStatusActive, models.SiswaStatusInactive, models.TagihanStatusUnpaid, models.TagihanStatusPaid}
+	for _, name := range statusCollections {
+		for _, code := range codes {
+			legacy := models.LegacyStatuses(code)
+			if len(legacy) == 0 {
+				continue
+			}
+			result, err := db.Collection(name).UpdateMany(ctx,
+				bson.M{"status": bson.M{"$in": legacy}},
+				bson.M{"$set": bson.M{"status": code}},
+			)
+			if err != nil {
+				return total, err
+			}
+			total += result.ModifiedCount
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,503 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TagihanFilter membatasi hasil TagihanRepository.List; field kosong berarti tidak difilter
+type TagihanFilter struct {
+	SiswaID     *primitive.ObjectID
+	CourseID    *primitive.ObjectID
+	Statuses    []string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	DueBefore   time.Time // Hanya tagihan dengan due_date sebelum waktu ini
+}
+
+// TagihanBalance adalah ringkasan ledger pembayaran yang disimpan di tagihan
+type TagihanBalance struct {
+	Amount      float64 // Nominal tagihan yang dipakai menghitung saldo
+	PaidAmount  float64
+	Outstanding float64
+	Status      string
+	PaidAt      *time.Time // nil jika tagihan belum lunas
+	LedgerCount int        // Jumlah entri ledger yang dihitung
+	UpdatedAt   time.Time
+}
+
+// TagihanLateFee adalah satu denda keterlambatan yang ditambahkan ke tagihan
+type TagihanLateFee struct {
+	Seq    int                  // Denda ke-berapa, dimulai dari 1
+	Amount float64              // Nominal denda, menambah amount dan outstanding
+	Items  []models.TagihanItem // Baris baru; tagihan lama tanpa rincian juga mendapat baris biaya kursus
+	At     time.Time
+}
+
+// TagihanDetails adalah perubahan data tagihan lewat UpdateDetails. Field
+// Expected* adalah keadaan tagihan saat dibaca; update ditolak jika ledger,
+// nominal atau status tagihan sudah berubah sejak itu.
+type TagihanDetails struct {
+	SiswaID    primitive.ObjectID
+	SiswaNama  string
+	SiswaEmail string
+	CourseID   primitive.ObjectID
+	CourseName string
+	CourseItem string // Deskripsi baru baris biaya kursus; kosong berarti tidak diubah
+	DueDate    time.Time
+	Status     string
+	OverdueAt  *time.Time // nil menghapus overdue_at
+	UpdatedAt  time.Time
+
+	ExpectedLedgerCount int
+	ExpectedAmount      float64
+	ExpectedStatus      string
+}
+
+// TagihanRepository mengelola tagihan kursus siswa
+type TagihanRepository interface {
+	Create(ctx context.Context, tagihan *models.Tagihan) error
+	List(ctx context.Context, filter TagihanFilter) ([]models.Tagihan, error)
+	ListPage(ctx context.Context, filter TagihanFilter, opts ListOptions) (Page[models.Tagihan], error)
+	Get(ctx context.Context, id primitive.ObjectID) (models.Tagihan, error)
+	// UpdateDetails mengubah siswa, kursus, jatuh tempo dan status tagihan
+	// tanpa menyentuh saldo dan denda. ErrConflict jika tagihan sudah berubah
+	// dari keadaan Expected* di details.
+	UpdateDetails(ctx context.Context, id primitive.ObjectID, details TagihanDetails) error
+	// SetBalance menyimpan ringkasan ledger pembayaran. Tidak mengubah apa pun
+	// jika tagihan sudah menyimpan ringkasan dari ledger yang sama panjang atau
+	// lebih panjang, atau jika amount tagihan sudah berbeda dari balance.Amount,
+	// sehingga pembayaran dan denda bersamaan tidak saling menimpa.
+	SetBalance(ctx context.Context, id primitive.ObjectID, balance TagihanBalance) error
+	// MarkOverdue mengubah tagihan yang belum lunas dan sudah lewat jatuh tempo
+	// menjadi terlambat. ErrConflict jika tagihan sudah tidak memenuhi syarat.
+	MarkOverdue(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	// AddLateFee menambahkan denda ke tagihan yang terlambat. ErrConflict jika
+	// tagihan sudah tidak terlambat atau denda ke-fee.Seq sudah pernah dikenakan.
+	AddLateFee(ctx context.Context, id primitive.ObjectID, fee TagihanLateFee) error
+	// FindByCycle mengambil tagihan yang diterbitkan untuk satu siklus jadwal
+	// tagihan, termasuk yang ada di trash karena index unik juga mencakupnya
+	FindByCycle(ctx context.Context, scheduleID primitive.ObjectID, cycle int) (models.Tagihan, error)
+	Trash[models.Tagihan]
+	// CourseIDsBySiswa mengembalikan ID kursus yang pernah ditagihkan ke siswa
+	CourseIDsBySiswa(ctx context.Context, siswaID primitive.ObjectID) ([]primitive.ObjectID, error)
+}
+
+type mongoTagihanRepository struct {
+	coll      *mongo.Collection
+	payments  *mongo.Collection
+	documents *mongo.Collection
+	mongoTrash[models.Tagihan]
+}
+
+func (r *mongoTagihanRepository) Create(ctx context.Context, tagihan *models.Tagihan) error {
+	if tagihan.ID.IsZero() {
+		tagihan.ID = primitive.NewObjectID()
+	}
+	return insertOne(ctx, r.coll, tagihan)
+}
+
+func (f TagihanFilter) query() bson.M {
+	query := bson.M{}
+	if f.SiswaID != nil {
+		query["siswa_id"] = *f.SiswaID
+	}
+	if f.CourseID != nil {
+		query["course_id"] = *f.CourseID
+	}
+	if len(f.Statuses) > 0 {
+		query["status"] = bson.M{"$in": f.Statuses}
+	}
+	created := bson.M{}
+	if !f.CreatedFrom.IsZero() {
+		created["$gte"] = f.CreatedFrom
+	}
+	if !f.CreatedTo.IsZero() {
+		created["$lte"] = f.CreatedTo
+	}
+	if len(created) > 0 {
+		query["created_at"] = created
+	}
+	if !f.DueBefore.IsZero() {
+		query["due_date"] = bson.M{"$lt": f.DueBefore}
+	}
+	return query
+}
+
+func (f TagihanFilter) match(t models.Tagihan) bool {
+	if f.SiswaID != nil && t.SiswaID != *f.SiswaID {
+		return false
+	}
+	if f.CourseID != nil && t.CourseID != *f.CourseID {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsString(f.Statuses, t.Status) {
+		return false
+	}
+	created := t.CreatedAt.Time()
+	if !f.CreatedFrom.IsZero() && created.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && created.After(f.CreatedTo) {
+		return false
+	}
+	if !f.DueBefore.IsZero() && !t.DueDate.Time().Before(f.DueBefore) {
+		return false
+	}
+	return true
+}
+
+func (r *mongoTagihanRepository) List(ctx context.Context, filter TagihanFilter) ([]models.Tagihan, error) {
+	return findAll[models.Tagihan](ctx, r.coll, notDeleted(filter.query()))
+}
+
+func (r *mongoTagihanRepository) ListPage(ctx context.Context, filter TagihanFilter, opts ListOptions) (Page[models.Tagihan], error) {
+	return findPage[models.Tagihan](ctx, r.coll, notDeleted(filter.query()), opts)
+}
+
+func (r *mongoTagihanRepository) Get(ctx context.Context, id primitive.ObjectID) (models.Tagihan, error) {
+	return findOne[models.Tagihan](ctx, r.coll, notDeleted(bson.M{"_id": id}))
+}
+
+func (r *mongoTagihanRepository) FindByCycle(ctx context.Context, scheduleID primitive.ObjectID, cycle int) (models.Tagihan, error) {
+	return findOne[models.Tagihan](ctx, r.coll, bson.M{"schedule_id": scheduleID, "cycle": cycle})
+}
+
+func (r *mongoTagihanRepository) UpdateDetails(ctx context.Context, id primitive.ObjectID, details TagihanDetails) error {
+	set := bson.M{
+		"siswa_id":    details.SiswaID,
+		"siswa_nama":  details.SiswaNama,
+		"siswa_email": details.SiswaEmail,
+		"course_id":   details.CourseID,
+		"course_name": details.CourseName,
+		"due_date":    primitive.NewDateTimeFromTime(details.DueDate),
+		"status":      details.Status,
+		"updated_at":  primitive.NewDateTimeFromTime(details.UpdatedAt),
+	}
+	update := bson.M{"$set": set}
+	if details.OverdueAt != nil {
+		set["overdue_at"] = primitive.NewDateTimeFromTime(*details.OverdueAt)
+	} else {
+		update["$unset"] = bson.M{"overdue_at": ""}
+	}
+	opts := options.Update()
+	if details.CourseItem != "" {
+		set["items.$[course].description"] = details.CourseItem
+		opts.SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"course.kind": models.TagihanItemCourse}}})
+	}
+
+	// $not juga cocok dengan tagihan lama yang belum punya ledger_count
+	err := updateOne(ctx, r.coll,
+		notDeleted(bson.M{
+			"_id":          id,
+			"amount":       details.ExpectedAmount,
+			"status":       details.ExpectedStatus,
+			"ledger_count": bson.M{"$not": bson.M{"$gt": details.ExpectedLedgerCount}},
+		}),
+		update, opts,
+	)
+	if errors.Is(err, ErrNotFound) {
+		if _, err := r.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *mongoTagihanRepository) SetBalance(ctx context.Context, id primitive.ObjectID, balance TagihanBalance) error {
+	set := bson.M{
+		"paid":         balance.PaidAt != nil,
+		"status":       balance.Status,
+		"paid_amount":  balance.PaidAmount,
+		"outstanding":  balance.Outstanding,
+		"ledger_count": balance.LedgerCount,
+		"updated_at":   primitive.NewDateTimeFromTime(balance.UpdatedAt),
+	}
+	update := bson.M{"$set": set}
+	if balance.PaidAt != nil {
+		set["paid_at"] = primitive.NewDateTimeFromTime(*balance.PaidAt)
+	} else {
+		update["$unset"] = bson.M{"paid_at": ""}
+	}
+	// $not juga cocok dengan tagihan lama yang belum punya ledger_count
+	filter := notDeleted(bson.M{
+		"_id":          id,
+		"amount":       balance.Amount,
+		"ledger_count": bson.M{"$not": bson.M{"$gte": balance.LedgerCount}},
+	})
+	_, err := r.coll.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *mongoTagihanRepository) MarkOverdue(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	now := primitive.NewDateTimeFromTime(at)
+	err := updateOne(ctx, r.coll,
+		notDeleted(bson.M{
+			"_id":      id,
+			"status":   bson.M{"$in": bson.A{models.TagihanStatusUnpaid, models.TagihanStatusPartial}},
+			"due_date": bson.M{"$lt": now},
+		}),
+		bson.M{"$set": bson.M{"status": models.TagihanStatusOverdue, "overdue_at": now, "updated_at": now}},
+	)
+	if errors.Is(err, ErrNotFound) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *mongoTagihanRepository) AddLateFee(ctx context.Context, id primitive.ObjectID, fee TagihanLateFee) error {
+	err := updateOne(ctx, r.coll,
+		notDeleted(bson.M{
+			"_id":            id,
+			"status":         models.TagihanStatusOverdue,
+			"late_fee_count": bson.M{"$not": bson.M{"$gte": fee.Seq}},
+		}),
+		bson.M{
+			"$push": bson.M{"items": bson.M{"$each": fee.Items}},
+			"$inc":  bson.M{"amount": fee.Amount, "outstanding": fee.Amount},
+			"$set":  bson.M{"late_fee_count": fee.Seq, "updated_at": primitive.NewDateTimeFromTime(fee.At)},
+		},
+	)
+	if errors.Is(err, ErrNotFound) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *mongoTagihanRepository) CourseIDsBySiswa(ctx context.Context, siswaID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	values, err := r.coll.Distinct(ctx, "course_id", notDeleted(bson.M{"siswa_id": siswaID}))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Purge menghapus permanen tagihan di trash, kecuali yang sudah punya entri
+// ledger pembayaran atau invoice/kuitansi. Catatan keuangan itu harus tetap
+// bisa ditelusuri, jadi tagihannya dibiarkan di trash.
+func (r *mongoTagihanRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	trashed := bson.M{"deleted_at": bson.M{"$lt": before}}
+	ids, err := r.coll.Distinct(ctx, "_id", trashed)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	paid, err := r.payments.Distinct(ctx, "tagihan_id", bson.M{"tagihan_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	issued, err := r.documents.Distinct(ctx, "entity_id", bson.M{
+		"entity":    models.DocumentEntityTagihan,
+		"entity_id": bson.M{"$in": ids},
+	})
+	if err != nil {
+		return 0, err
+	}
+	trashed["_id"] = bson.M{"$in": ids, "$nin": append(paid, issued...)}
+	result, err := r.coll.DeleteMany(ctx, trashed)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+type memoryTagihanRepository struct {
+	store     *memoryStore[primitive.ObjectID, models.Tagihan]
+	payments  *memoryPaymentRepository
+	documents *memoryDocumentRepository
+	memoryTrash[models.Tagihan, *models.Tagihan]
+}
+
+func newMemoryTagihanRepository(payments *memoryPaymentRepository, documents *memoryDocumentRepository) *memoryTagihanRepository {
+	store := newMemoryStore(func(t models.Tagihan) primitive.ObjectID { return t.ID })
+	return &memoryTagihanRepository{
+		store:       store,
+		payments:    payments,
+		documents:   documents,
+		memoryTrash: memoryTrash[models.Tagihan, *models.Tagihan]{store: store},
+	}
+}
+
+// Purge sama dengan versi Mongo: tagihan yang punya ledger atau dokumen tetap di trash
+func (r *memoryTagihanRepository) Purge(_ context.Context, before time.Time) (int64, error) {
+	return int64(r.store.purge(func(t models.Tagihan) bool {
+		if !t.DeletedBefore(before) {
+			return false
+		}
+		_, errPayment := r.payments.store.find(func(p models.Payment) bool { return p.TagihanID == t.ID })
+		_, errDocument := r.documents.store.find(func(d models.Document) bool {
+			return d.Entity == models.DocumentEntityTagihan && d.EntityID == t.ID
+		})
+		return errPayment != nil && errDocument != nil
+	})), nil
+}
+
+func (r *memoryTagihanRepository) Create(_ context.Context, tagihan *models.Tagihan) error {
+	if tagihan.ID.IsZero() {
+		tagihan.ID = primitive.NewObjectID()
+	}
+	if tagihan.ScheduleID != nil {
+		// Sama dengan index unik schedule_id_cycle_unique di Mongo
+		if _, err := r.FindByCycle(context.Background(), *tagihan.ScheduleID, tagihan.Cycle); err == nil {
+			return ErrDuplicate
+		}
+	}
+	return r.store.insert(*tagihan)
+}
+
+func (r *memoryTagihanRepository) List(_ context.Context, filter TagihanFilter) ([]models.Tagihan, error) {
+	return r.store.list(filter.match), nil
+}
+
+func (r *memoryTagihanRepository) ListPage(_ context.Context, filter TagihanFilter, opts ListOptions) (Page[models.Tagihan], error) {
+	return pageOf(r.store.list(filter.match), opts)
+}
+
+func (r *memoryTagihanRepository) Get(_ context.Context, id primitive.ObjectID) (models.Tagihan, error) {
+	return r.store.get(id)
+}
+
+func (r *memoryTagihanRepository) FindByCycle(_ context.Context, scheduleID primitive.ObjectID, cycle int) (models.Tagihan, error) {
+	match := func(t models.Tagihan) bool {
+		return t.ScheduleID != nil && *t.ScheduleID == scheduleID && t.Cycle == cycle
+	}
+	if t, err := r.store.find(match); err == nil {
+		return t, nil
+	}
+	for _, t := range r.store.listTrashed() {
+		if match(t) {
+			return t, nil
+		}
+	}
+	return models.Tagihan{}, ErrNotFound
+}
+
+func (r *memoryTagihanRepository) UpdateDetails(_ context.Context, id primitive.ObjectID, details TagihanDetails) error {
+	updated := false
+	err := r.store.update(id, func(t *models.Tagihan) {
+		if t.LedgerCount > details.ExpectedLedgerCount || t.Amount != details.ExpectedAmount || t.Status != details.ExpectedStatus {
+			return
+		}
+		t.SiswaID = details.SiswaID
+		t.SiswaNama = details.SiswaNama
+		t.SiswaEmail = details.SiswaEmail
+		t.CourseID = details.CourseID
+		t.CourseName = details.CourseName
+		t.DueDate = primitive.NewDateTimeFromTime(details.DueDate)
+		t.Status = details.Status
+		t.OverdueAt = nil
+		if details.OverdueAt != nil {
+			overdueAt := primitive.NewDateTimeFromTime(*details.OverdueAt)
+			t.OverdueAt = &overdueAt
+		}
+		if details.CourseItem != "" {
+			items := append([]models.TagihanItem{}, t.Items...)
+			for i := range items {
+				if items[i].Kind == models.TagihanItemCourse {
+					items[i].Description = details.CourseItem
+				}
+			}
+			t.Items = items
+		}
+		t.UpdatedAt = primitive.NewDateTimeFromTime(details.UpdatedAt)
+		updated = true
+	})
+	if err == nil && !updated {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memoryTagihanRepository) SetBalance(_ context.Context, id primitive.ObjectID, balance TagihanBalance) error {
+	err := r.store.update(id, func(t *models.Tagihan) {
+		if t.LedgerCount >= balance.LedgerCount || t.Amount != balance.Amount {
+			return
+		}
+		t.Paid = balance.PaidAt != nil
+		t.Status = balance.Status
+		t.PaidAmount = balance.PaidAmount
+		t.Outstanding = balance.Outstanding
+		t.LedgerCount = balance.LedgerCount
+		t.PaidAt = nil
+		if balance.PaidAt != nil {
+			paidAt := primitive.NewDateTimeFromTime(*balance.PaidAt)
+			t.PaidAt = &paidAt
+		}
+		t.UpdatedAt = primitive.NewDateTimeFromTime(balance.UpdatedAt)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (r *memoryTagihanRepository) MarkOverdue(_ context.Context, id primitive.ObjectID, at time.Time) error {
+	marked := false
+	err := r.store.update(id, func(t *models.Tagihan) {
+		if t.Status != models.TagihanStatusUnpaid && t.Status != models.TagihanStatusPartial || !t.DueDate.Time().Before(at) {
+			return
+		}
+		now := primitive.NewDateTimeFromTime(at)
+		t.Status = models.TagihanStatusOverdue
+		t.OverdueAt = &now
+		t.UpdatedAt = now
+		marked = true
+	})
+	if errors.Is(err, ErrNotFound) || err == nil && !marked {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memoryTagihanRepository) AddLateFee(_ context.Context, id primitive.ObjectID, fee TagihanLateFee) error {
+	added := false
+	err := r.store.update(id, func(t *models.Tagihan) {
+		if t.Status != models.TagihanStatusOverdue || t.LateFees >= fee.Seq {
+			return
+		}
+		t.Items = append(append([]models.TagihanItem{}, t.Items...), fee.Items...)
+		t.Amount += fee.Amount
+		t.Outstanding += fee.Amount
+		t.LateFees = fee.Seq
+		t.UpdatedAt = primitive.NewDateTimeFromTime(fee.At)
+		added = true
+	})
+	if errors.Is(err, ErrNotFound) || err == nil && !added {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memoryTagihanRepository) CourseIDsBySiswa(_ context.Context, siswaID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	seen := map[primitive.ObjectID]bool{}
+	ids := []primitive.ObjectID{}
+	for _, t := range r.store.list(func(t models.Tagihan) bool { return t.SiswaID == siswaID }) {
+		if !seen[t.CourseID] {
+			seen[t.CourseID] = true
+			ids = append(ids, t.CourseID)
+		}
+	}
+	return ids, nil
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
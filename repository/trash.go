@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Trash adalah operasi soft delete yang sama untuk semua data domain. Delete
+// hanya mengisi deleted_at; data di trash tidak muncul di method lain sampai
+// dipulihkan dengan Restore atau dihapus permanen oleh Purge.
+type Trash[T any] interface {
+	// Delete memindahkan data ke trash; by adalah user yang menghapus, boleh nil
+	Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error
+	// ListDeleted mengembalikan data yang sedang berada di trash
+	ListDeleted(ctx context.Context, opts ListOptions) (Page[T], error)
+	// Restore mengeluarkan data dari trash; ErrNotFound jika data tidak ada di trash
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	Purger
+}
+
+// Purger menghapus permanen data yang masuk trash sebelum waktu before
+type Purger interface {
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purgers mengembalikan semua repository yang punya trash, per nama resource
+func (r *Repositories) Purgers() map[string]Purger {
+	return map[string]Purger{
+		"siswa":           r.Siswa,
+		"transaksi_siswa": r.TransaksiSiswa,
+		"guru":            r.Guru,
+		"transaksi_guru":  r.TransaksiGuru,
+		"tagihan":         r.Tagihan,
+		"course":          r.Course,
+	}
+}
+
+// notDeleted menambahkan syarat "tidak di trash" ke filter
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// mongoTrash mengimplementasikan Trash untuk satu koleksi; disisipkan di repository Mongo
+type mongoTrash[T any] struct {
+	coll *mongo.Collection
+}
+
+func (t mongoTrash[T]) Delete(ctx context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	set := bson.M{"deleted_at": time.Now()}
+	if by != nil {
+		set["deleted_by"] = *by
+	}
+	return updateOne(ctx, t.coll, notDeleted(bson.M{"_id": id}), bson.M{"$set": set})
+}
+
+func (t mongoTrash[T]) ListDeleted(ctx context.Context, opts ListOptions) (Page[T], error) {
+	return findPage[T](ctx, t.coll, bson.M{"deleted_at": bson.M{"$ne": nil}}, opts)
+}
+
+func (t mongoTrash[T]) Restore(ctx context.Context, id primitive.ObjectID) error {
+	return updateOne(ctx, t.coll,
+		bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}},
+	)
+}
+
+func (t mongoTrash[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := t.coll.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// trashable adalah pointer ke model yang menyisipkan models.SoftDelete
+type trashable[T any] interface {
+	*T
+	DeletedBefore(t time.Time) bool
+	Trash(at time.Time, by *primitive.ObjectID)
+	Restore()
+}
+
+// memoryTrash adalah versi in-memory dari mongoTrash
+type memoryTrash[T any, P trashable[T]] struct {
+	store *memoryStore[primitive.ObjectID, T]
+}
+
+func (t memoryTrash[T, P]) Delete(_ context.Context, id primitive.ObjectID, by *primitive.ObjectID) error {
+	return t.store.update(id, func(item *T) { P(item).Trash(time.Now(), by) })
+}
+
+func (t memoryTrash[T, P]) ListDeleted(_ context.Context, opts ListOptions) (Page[T], error) {
+	return pageOf(t.store.listTrashed(), opts)
+}
+
+func (t memoryTrash[T, P]) Restore(_ context.Context, id primitive.ObjectID) error {
+	return t.store.updateTrashed(id, func(item *T) { P(item).Restore() })
+}
+
+func (t memoryTrash[T, P]) Purge(_ context.Context, before time.Time) (int64, error) {
+	return int64(t.store.purge(func(item T) bool { return P(&item).DeletedBefore(before) })), nil
+}
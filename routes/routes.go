@@ -3,127 +3,246 @@ package routes
 import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/organisasi/tubesbackend/config"
 	"github.com/organisasi/tubesbackend/controllers"
+	"github.com/organisasi/tubesbackend/i18n"
+	"github.com/organisasi/tubesbackend/invoice"
 	"github.com/organisasi/tubesbackend/middlewares"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/service"
+	"github.com/organisasi/tubesbackend/utils"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRoutes(db *mongo.Database) *gin.Engine {
-	router := gin.Default()
+// can adalah singkatan untuk middlewares.RequirePermission agar daftar route tetap ringkas
+func can(resource, action string) gin.HandlerFunc {
+	return middlewares.RequirePermission(resource, action)
+}
+
+// SetupRoutes menyusun semua route; mailer dibuat oleh pemanggil sesuai konfigurasi
+func SetupRoutes(db *mongo.Database, cfg *config.Config, mailer utils.Mailer) *gin.Engine {
+	// gin.Recovery diganti ErrorHandler agar panic juga dibalas dengan format error standar
+	router := gin.New()
+	i18n.SetDefault(cfg.Locale)
+	router.Use(gin.Logger(), middlewares.RequestID(), i18n.Middleware(), middlewares.ErrorHandler())
+	repos := repository.NewMongoRepositories(db)
+	billing := service.NewBillingService(repos)
+	enrollment := service.NewEnrollmentService(repos)
+	payroll := service.NewPayrollService(repos, cfg.Location())
+	documents := service.NewDocumentService(repos, service.DocumentOptions{
+		Header: invoice.Header{
+			Name:    cfg.School.Name,
+			Address: cfg.School.Address,
+			Phone:   cfg.School.Phone,
+			Email:   cfg.School.Email,
+			Website: cfg.School.Website,
+		},
+		InvoicePrefix: cfg.Invoice.InvoicePrefix,
+		ReceiptPrefix: cfg.Invoice.ReceiptPrefix,
+		BaseURL:       cfg.Server.BaseURL,
+		Location:      cfg.Location(),
+	})
 
 	// Middleware CORS
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://127.0.0.1:5504", "http://localhost:5504"},
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", "Accept-Language", middlewares.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middlewares.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
+	// Liveness dan readiness untuk orchestrator
+	healthCtrl := controllers.HealthController{DB: db}
+	router.GET("/healthz", healthCtrl.Healthz)
+	router.GET("/readyz", healthCtrl.Readyz)
+
+	// Public key untuk verifikasi JWT oleh service lain
+	router.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
+	// Verifikasi invoice dan kuitansi dari QR code; publik karena dipindai tanpa login
+	documentCtrl := controllers.DocumentController{Documents: documents}
+	router.GET("/documents/verify/:code", documentCtrl.VerifyDocument)
+
 	// Auth routes
-	authCtrl := controllers.AuthController{DB: db}
+	authCtrl := controllers.AuthController{DB: db, Mailer: mailer, Config: cfg, Audit: repos.Audit}
 	authRoutes := router.Group("/auth")
 	{
-		authRoutes.POST("/register", authCtrl.Register)
+		if cfg.FeatureEnabled("registration") {
+			authRoutes.POST("/register", authCtrl.Register)
+		}
 		authRoutes.POST("/login", authCtrl.Login)
+		authRoutes.POST("/refresh", authCtrl.Refresh)
+		authRoutes.POST("/forgot-password", authCtrl.ForgotPassword)
+		authRoutes.GET("/reset-password", authCtrl.CheckResetToken)
+		authRoutes.POST("/reset-password", authCtrl.ResetPassword)
+		authRoutes.GET("/verify-email", authCtrl.CheckVerifyEmailToken)
+		authRoutes.POST("/verify-email", authCtrl.VerifyEmail)
+		authRoutes.POST("/2fa/verify", authCtrl.VerifyTwoFactor)
+
+		// Pendaftaran 2FA menerima access token biasa atau token tantangan setup
+		twoFactorSetup := authRoutes.Group("/2fa")
+		twoFactorSetup.Use(middlewares.TwoFactorSetupMiddleware(db))
+		twoFactorSetup.POST("/enroll", authCtrl.EnrollTwoFactor)
+		twoFactorSetup.POST("/confirm", authCtrl.ConfirmTwoFactor)
 
 		// Gunakan middleware untuk melindungi route ini
 		authRoutes.Use(middlewares.AuthMiddleware(db))
-		authRoutes.GET("/users", authCtrl.GetAllUsers)
-		authRoutes.PUT("/users/:id/status", authCtrl.UpdateUserStatus)
+		authRoutes.POST("/logout", authCtrl.Logout)
+		authRoutes.POST("/2fa/disable", authCtrl.DisableTwoFactor)
+		authRoutes.GET("/me", authCtrl.GetMe)
+		authRoutes.PUT("/me", authCtrl.UpdateMe)
+		authRoutes.PUT("/me/password", authCtrl.ChangeMyPassword)
+		authRoutes.PUT("/me/email", authCtrl.UpdateMyEmail)
+		authRoutes.GET("/users", can("users", "read"), authCtrl.GetAllUsers)
+		authRoutes.PUT("/users/:id/status", can("users", "update"), authCtrl.UpdateUserStatus)
+		authRoutes.POST("/users/:id/unlock", can("users", "update"), authCtrl.UnlockUser)
+		authRoutes.PUT("/users/:id/role", can("users", "update"), authCtrl.UpdateUserRole)
+		authRoutes.DELETE("/users/:id", can("users", "delete"), authCtrl.DeleteUser)
+		authRoutes.POST("/users/:id/restore", can("users", "restore"), authCtrl.RestoreUser)
+		authRoutes.POST("/users/bulk-review", can("users", "update"), authCtrl.BulkReviewUsers)
+		authRoutes.PUT("/users/:id/link", can("users", "link"), authCtrl.LinkUser)
+		authRoutes.DELETE("/users/:id/link", can("users", "link"), authCtrl.UnlinkUser)
+	}
+
+	// Data milik user yang sedang login (berdasarkan link siswa_id / guru_id)
+	meCtrl := controllers.NewMeController(repos)
+	meRoutes := router.Group("/me")
+	meRoutes.Use(middlewares.AuthMiddleware(db))
+	{
+		meRoutes.GET("/tagihan", can("tagihan", "read_own"), meCtrl.MyTagihan)
+		meRoutes.GET("/courses", can("course", "read"), meCtrl.MyCourses)
+		meRoutes.GET("/schedule", can("schedule", "read"), meCtrl.MySchedule)
+		meRoutes.GET("/salary-slips", can("transaksi_guru", "read_own"), meCtrl.MySalarySlips)
 	}
 
 	// Course routes
 	// Membuat instance controller dengan database yang sudah terhubung
-	courseCtrl := controllers.NewCourseController(db)
-	courseUsersCtrl := controllers.NewCourseUsers(db)
+	courseCtrl := controllers.NewCourseController(repos.Course)
+	courseUsersCtrl := controllers.NewCourseUsers(repos.Registration)
 	courseRoutes := router.Group("/courses")
 	{
+		// Route publik: katalog kursus dan formulir pendaftaran
+		courseRoutes.GET("", courseCtrl.GetCourses)         // Dapatkan semua kursus
+		courseRoutes.GET("/:id", courseCtrl.FindCourseById) // Cari kursus berdasarkan ID
+		if cfg.FeatureEnabled("course_registration") {
+			courseRoutes.POST("/register", courseUsersCtrl.RegisterCourse) // Daftar kursus
+		}
+
 		// Kursus management routes
-		courseRoutes.POST("", courseCtrl.CreateCourse)           // Tambah kursus baru
-		courseRoutes.GET("", courseCtrl.GetCourses)              // Dapatkan semua kursus
-		courseRoutes.GET("/:id", courseCtrl.FindCourseById)      // Cari kursus berdasarkan ID
-		courseRoutes.PUT("/:id", courseCtrl.UpdateCourseById)    // Perbarui kursus berdasarkan ID
-		courseRoutes.DELETE("/:id", courseCtrl.DeleteCourse)     // Hapus kursus berdasarkan ID
-		courseRoutes.GET("/next-id", courseCtrl.GetNextCourseId) // Dapatkan ID kursus berikutnya
+		courseRoutes.Use(middlewares.AuthMiddleware(db))
+		courseRoutes.POST("", can("course", "create"), courseCtrl.CreateCourse)               // Tambah kursus baru
+		courseRoutes.PUT("/:id", can("course", "update"), courseCtrl.UpdateCourseById)        // Perbarui kursus berdasarkan ID
+		courseRoutes.DELETE("/:id", can("course", "delete"), courseCtrl.DeleteCourse)         // Hapus kursus berdasarkan ID
+		courseRoutes.GET("/trash", can("course", "restore"), courseCtrl.GetCourseTrash)       // Kursus yang sudah dihapus
+		courseRoutes.POST("/:id/restore", can("course", "restore"), courseCtrl.RestoreCourse) // Pulihkan kursus dari trash
+		courseRoutes.GET("/next-id", can("course", "create"), courseCtrl.GetNextCourseId)     // Dapatkan ID kursus berikutnya
 
 		// Pendaftaran kursus
-		courseRoutes.POST("/register", courseUsersCtrl.RegisterCourse)                // Daftar kursus
-		courseRoutes.GET("/registrations", courseUsersCtrl.GetAllCourseRegistrations) // Dapatkan semua pendaftaran kursus
-
+		courseRoutes.GET("/registrations", can("registration", "read"), courseUsersCtrl.GetAllCourseRegistrations) // Dapatkan semua pendaftaran kursus
 	}
 
 	// Inisialisasi controller dan rute untuk menangani permintaan
-	scheduleCtrl := controllers.NewScheduleController(db)
+	scheduleCtrl := controllers.NewScheduleController(repos.Schedule, repos.Course)
 
 	scheduleRoutes := router.Group("/schedules")
 	scheduleRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi semua route schedule
 	{
-		scheduleRoutes.POST("", scheduleCtrl.AddSchedule)                    // Menambahkan jadwal baru
-		scheduleRoutes.GET("/:courseId", scheduleCtrl.GetScheduleByCourseId) // Mendapatkan jadwal berdasarkan courseId
-		scheduleRoutes.GET("", scheduleCtrl.GetAllSchedules)                 // Mendapatkan semua jadwal
-		scheduleRoutes.PUT("/:courseId", scheduleCtrl.UpdateSchedule)        // Memperbarui jadwal berdasarkan courseId
-		scheduleRoutes.DELETE("/:courseId", scheduleCtrl.DeleteSchedule)     // Menghapus jadwal berdasarkan courseId
+		scheduleRoutes.POST("", can("schedule", "create"), scheduleCtrl.AddSchedule)                  // Menambahkan jadwal baru
+		scheduleRoutes.GET("/:courseId", can("schedule", "read"), scheduleCtrl.GetScheduleByCourseId) // Mendapatkan jadwal berdasarkan courseId
+		scheduleRoutes.GET("", can("schedule", "read"), scheduleCtrl.GetAllSchedules)                 // Mendapatkan semua jadwal
+		scheduleRoutes.PUT("/:courseId", can("schedule", "update"), scheduleCtrl.UpdateSchedule)      // Memperbarui jadwal berdasarkan courseId
+		scheduleRoutes.DELETE("/:courseId", can("schedule", "delete"), scheduleCtrl.DeleteSchedule)   // Menghapus jadwal berdasarkan courseId
 	}
 
 	// Siswa routes
-	siswaCtrl := controllers.SiswaController{DB: db}
+	siswaCtrl := controllers.SiswaController{Siswa: repos.Siswa, Transaksi: repos.TransaksiSiswa, Enrollment: enrollment, Documents: documents}
 	siswaRoutes := router.Group("/siswa")
 	siswaRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi semua route siswa
 	{
-		siswaRoutes.POST("", siswaCtrl.CreateSiswa)
-		siswaRoutes.GET("", siswaCtrl.GetSiswa)
-		siswaRoutes.GET("/:id", siswaCtrl.GetSiswaByID)
-		siswaRoutes.PUT("/:id", siswaCtrl.UpdateSiswa)
-		siswaRoutes.DELETE("/:id", siswaCtrl.DeleteSiswa)
-		siswaRoutes.POST("/create/transaksi", siswaCtrl.CreateTransaksiSiswa)
-		siswaRoutes.PUT("/update/transaksi", siswaCtrl.UpdateStatusTransaksi)
-		siswaRoutes.GET("/all/transaksi", siswaCtrl.GetAllTransaksiSiswa)
-		siswaRoutes.DELETE("/delete/transaksi/:id", siswaCtrl.DeleteTransaksi)
-		siswaRoutes.GET("/get/transaksi/:id", siswaCtrl.GetTransaksiByID)
+		siswaRoutes.POST("", can("siswa", "create"), siswaCtrl.CreateSiswa)
+		siswaRoutes.GET("", can("siswa", "read"), siswaCtrl.GetSiswa)
+		siswaRoutes.GET("/:id", can("siswa", "read"), siswaCtrl.GetSiswaByID)
+		siswaRoutes.PUT("/:id", can("siswa", "update"), siswaCtrl.UpdateSiswa)
+		siswaRoutes.DELETE("/:id", can("siswa", "delete"), siswaCtrl.DeleteSiswa)
+		siswaRoutes.GET("/trash", can("siswa", "restore"), siswaCtrl.GetSiswaTrash)
+		siswaRoutes.POST("/:id/restore", can("siswa", "restore"), siswaCtrl.RestoreSiswa)
+		siswaRoutes.POST("/create/transaksi", can("transaksi_siswa", "create"), siswaCtrl.CreateTransaksiSiswa)
+		siswaRoutes.PUT("/update/transaksi", can("transaksi_siswa", "update"), siswaCtrl.UpdateStatusTransaksi)
+		siswaRoutes.GET("/all/transaksi", can("transaksi_siswa", "read"), siswaCtrl.GetAllTransaksiSiswa)
+		siswaRoutes.DELETE("/delete/transaksi/:id", can("transaksi_siswa", "delete"), siswaCtrl.DeleteTransaksi)
+		siswaRoutes.GET("/trash/transaksi", can("transaksi_siswa", "restore"), siswaCtrl.GetTransaksiTrash)
+		siswaRoutes.POST("/restore/transaksi/:id", can("transaksi_siswa", "restore"), siswaCtrl.RestoreTransaksi)
+		siswaRoutes.GET("/get/transaksi/:id", can("transaksi_siswa", "read"), siswaCtrl.GetTransaksiByID)
+		siswaRoutes.GET("/get/transaksi/:id/invoice.pdf", can("transaksi_siswa", "read"), siswaCtrl.GetTransaksiInvoice)
+		siswaRoutes.GET("/get/transaksi/:id/receipt.pdf", can("transaksi_siswa", "read"), siswaCtrl.GetTransaksiReceipt)
 
 	}
 
 	// Guru routes
-	guruCtrl := controllers.GuruController{DB: db}
+	guruCtrl := controllers.GuruController{Guru: repos.Guru}
 	guruRoutes := router.Group("/gurus")
 	guruRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi semua route guru
 	{
-		guruRoutes.GET("", guruCtrl.GetAllGuru)
-		guruRoutes.POST("", guruCtrl.CreateGuru)
-		guruRoutes.GET("/:id", guruCtrl.GetGuruByID)
-		guruRoutes.PUT("/:id", guruCtrl.UpdateGuru)
-		guruRoutes.DELETE("/:id", guruCtrl.DeleteGuru)
-		guruRoutes.GET("/status", guruCtrl.GetGuruByStatus) // Get guru by status
+		guruRoutes.GET("", can("guru", "read"), guruCtrl.GetAllGuru)
+		guruRoutes.POST("", can("guru", "create"), guruCtrl.CreateGuru)
+		guruRoutes.GET("/:id", can("guru", "read"), guruCtrl.GetGuruByID)
+		guruRoutes.PUT("/:id", can("guru", "update"), guruCtrl.UpdateGuru)
+		guruRoutes.DELETE("/:id", can("guru", "delete"), guruCtrl.DeleteGuru)
+		guruRoutes.GET("/trash", can("guru", "restore"), guruCtrl.GetGuruTrash)
+		guruRoutes.POST("/:id/restore", can("guru", "restore"), guruCtrl.RestoreGuru)
+		guruRoutes.GET("/status", can("guru", "read"), guruCtrl.GetGuruByStatus) // Get guru by status
 	}
 
 	// Tagihan routes
-	tagihanCtrl := controllers.TagihanController{DB: db}
+	tagihanCtrl := controllers.TagihanController{Tagihan: repos.Tagihan, Schedules: repos.Billing, Billing: billing, Documents: documents}
 	tagihanRoutes := router.Group("/tagihan")
 	tagihanRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi semua route tagihan
 	{
-		tagihanRoutes.GET("", tagihanCtrl.GetTagihan)
-		tagihanRoutes.GET("/:id", tagihanCtrl.GetTagihanByID)
-		tagihanRoutes.POST("", tagihanCtrl.CreateTagihan)
-		tagihanRoutes.PUT("/:id", tagihanCtrl.UpdateTagihan)
-		tagihanRoutes.DELETE("/:id", tagihanCtrl.DeleteTagihan)
-		tagihanRoutes.PUT("/:id/bayar", tagihanCtrl.BayarTagihan)
-		tagihanRoutes.GET("/user", tagihanCtrl.GetTagihanByUser)
-		tagihanRoutes.GET("/laporan", tagihanCtrl.GetLaporanTagihan)
+		tagihanRoutes.GET("", can("tagihan", "read"), tagihanCtrl.GetTagihan)
+		tagihanRoutes.GET("/:id", can("tagihan", "read"), tagihanCtrl.GetTagihanByID)
+		tagihanRoutes.POST("", can("tagihan", "create"), tagihanCtrl.CreateTagihan)
+		tagihanRoutes.PUT("/:id", can("tagihan", "update"), tagihanCtrl.UpdateTagihan)
+		tagihanRoutes.DELETE("/:id", can("tagihan", "delete"), tagihanCtrl.DeleteTagihan)
+		tagihanRoutes.GET("/trash", can("tagihan", "restore"), tagihanCtrl.GetTagihanTrash)
+		tagihanRoutes.POST("/:id/restore", can("tagihan", "restore"), tagihanCtrl.RestoreTagihan)
+		tagihanRoutes.PUT("/:id/bayar", can("tagihan", "update"), tagihanCtrl.BayarTagihan)
+		tagihanRoutes.GET("/:id/invoice.pdf", can("tagihan", "read"), tagihanCtrl.GetTagihanInvoice)
+		tagihanRoutes.GET("/:id/payments", can("tagihan", "read"), tagihanCtrl.GetTagihanPayments)
+		tagihanRoutes.POST("/:id/payments", can("tagihan", "update"), tagihanCtrl.CreateTagihanPayment)
+		tagihanRoutes.POST("/:id/payments/:paymentId/void", can("tagihan", "update"), tagihanCtrl.VoidTagihanPayment)
+		tagihanRoutes.GET("/:id/payments/:paymentId/receipt.pdf", can("tagihan", "read"), tagihanCtrl.GetPaymentReceipt)
+		tagihanRoutes.POST("/:id/refunds", can("tagihan", "update"), tagihanCtrl.RefundTagihan)
+		tagihanRoutes.GET("/user", can("tagihan", "read_own"), tagihanCtrl.GetTagihanByUser)
+		tagihanRoutes.POST("/schedules", can("tagihan", "create"), tagihanCtrl.EnrollSiswa)
+		tagihanRoutes.GET("/schedules", can("tagihan", "read"), tagihanCtrl.GetBillingSchedules)
+		tagihanRoutes.GET("/schedules/:id", can("tagihan", "read"), tagihanCtrl.GetBillingScheduleByID)
+		tagihanRoutes.POST("/schedules/:id/cancel", can("tagihan", "update"), tagihanCtrl.CancelBillingSchedule)
+		tagihanRoutes.GET("/laporan", can("tagihan", "read"), tagihanCtrl.GetLaporanTagihan)
 	}
 
 	// Transaksi Guru Routes
-	transaksiGuruCtrl := controllers.TransaksiGuruController{DB: db}
+	transaksiGuruCtrl := controllers.TransaksiGuruController{Transaksi: repos.TransaksiGuru, Payroll: payroll}
 	transaksiRoutes := router.Group("/transaksi-guru")
-	// transaksiRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi dengan autentikasi
+	transaksiRoutes.Use(middlewares.AuthMiddleware(db)) // Proteksi dengan autentikasi
 	{
 
-		transaksiRoutes.POST("", transaksiGuruCtrl.CreateTransaksiGuru)
-		transaksiRoutes.GET("", transaksiGuruCtrl.GetAllTransaksiGuru)
-		transaksiRoutes.GET("/laporan", transaksiGuruCtrl.GetLaporanGajiGuru)
-		transaksiRoutes.GET("/:id", transaksiGuruCtrl.GetTransaksiGuruByID)
-		transaksiRoutes.PUT("/:id", transaksiGuruCtrl.UpdateTransaksiGuru)
-		transaksiRoutes.DELETE("/:id", transaksiGuruCtrl.DeleteTransaksiGuru)
+		transaksiRoutes.POST("", can("transaksi_guru", "create"), transaksiGuruCtrl.CreateTransaksiGuru)
+		transaksiRoutes.GET("", can("transaksi_guru", "read"), transaksiGuruCtrl.GetAllTransaksiGuru)
+		transaksiRoutes.GET("/laporan", can("transaksi_guru", "read"), transaksiGuruCtrl.GetLaporanGajiGuru)
+		transaksiRoutes.GET("/:id", can("transaksi_guru", "read"), transaksiGuruCtrl.GetTransaksiGuruByID)
+		transaksiRoutes.PUT("/:id", can("transaksi_guru", "update"), transaksiGuruCtrl.UpdateTransaksiGuru)
+		transaksiRoutes.DELETE("/:id", can("transaksi_guru", "delete"), transaksiGuruCtrl.DeleteTransaksiGuru)
+		transaksiRoutes.GET("/trash", can("transaksi_guru", "restore"), transaksiGuruCtrl.GetTransaksiGuruTrash)
+		transaksiRoutes.POST("/:id/restore", can("transaksi_guru", "restore"), transaksiGuruCtrl.RestoreTransaksiGuru)
 	}
+
+	// Audit log semua perubahan data; hanya admin yang punya permission audit:read
+	auditCtrl := controllers.AuditController{Audit: repos.Audit}
+	router.GET("/audit", middlewares.AuthMiddleware(db), can("audit", "read"), auditCtrl.GetAuditLog)
+
+	// Pencarian gabungan siswa, guru dan kursus; hasil disaring sesuai permission user
+	searchCtrl := controllers.NewSearchController(repos)
+	router.GET("/search", middlewares.AuthMiddleware(db), searchCtrl.Search)
 	return router
 }
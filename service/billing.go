@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultDueDays adalah jatuh tempo bawaan tagihan jika tidak ditentukan
+const defaultDueDays = 7
+
+// BillingService mengatur pembuatan tagihan kursus dan ledger pembayarannya
+type BillingService struct {
+	tagihan   repository.TagihanRepository
+	payments  repository.PaymentRepository
+	schedules repository.BillingScheduleRepository
+	siswa     repository.SiswaRepository
+	course    repository.CourseRepository
+	now       func() time.Time
+}
+
+// NewBillingService membuat BillingService di atas repository yang diberikan
+func NewBillingService(repos *repository.Repositories) *BillingService {
+	return &BillingService{
+		tagihan:   repos.Tagihan,
+		payments:  repos.Payment,
+		schedules: repos.Billing,
+		siswa:     repos.Siswa,
+		course:    repos.Course,
+		now:       time.Now,
+	}
+}
+
+// CreateTagihanInput adalah data untuk membuat tagihan baru
+type CreateTagihanInput struct {
+	SiswaID  primitive.ObjectID
+	CourseID primitive.ObjectID
+	DueDate  time.Time // Kosong berarti 7 hari dari sekarang
+}
+
+// CreateTagihan menagihkan kursus ke siswa. Nominal diambil dari biaya kursus,
+// nama dan email siswa disalin agar laporan tidak perlu join.
+func (s *BillingService) CreateTagihan(ctx context.Context, in CreateTagihanInput) (models.Tagihan, error) {
+	siswa, err := s.siswa.Get(ctx, in.SiswaID)
+	if err != nil {
+		return models.Tagihan{}, notFound(err, "Siswa")
+	}
+	course, err := s.course.Get(ctx, in.CourseID)
+	if err != nil {
+		return models.Tagihan{}, notFound(err, "Course")
+	}
+
+	now := s.now()
+	dueDate := in.DueDate
+	if dueDate.IsZero() {
+		dueDate = now.AddDate(0, 0, defaultDueDays)
+	}
+
+	tagihan := models.Tagihan{
+		ID:          primitive.NewObjectID(),
+		SiswaID:     siswa.ID,
+		SiswaNama:   siswa.FullName,
+		SiswaEmail:  siswa.Email,
+		CourseID:    course.ID,
+		CourseName:  course.Name,
+		Amount:      course.Cost,
+		Items:       []models.TagihanItem{courseItem(course.Name, course.Cost, now)},
+		Outstanding: course.Cost,
+		DueDate:     primitive.NewDateTimeFromTime(dueDate),
+		Paid:        false,
+		Status:      models.TagihanStatusUnpaid, // Status awal saat tagihan dibuat, ledger masih kosong
+		CreatedAt:   primitive.NewDateTimeFromTime(now),
+	}
+	if err := s.tagihan.Create(ctx, &tagihan); err != nil {
+		return models.Tagihan{}, err
+	}
+	return tagihan, nil
+}
+
+// UpdateTagihanInput berisi perubahan tagihan; field nil tidak diubah
+type UpdateTagihanInput struct {
+	SiswaID  *primitive.ObjectID
+	CourseID *primitive.ObjectID
+	DueDate  *time.Time
+}
+
+// updateTagihanAttempts membatasi pengulangan UpdateTagihan saat tagihan
+// berubah oleh pembayaran atau denda di tengah jalan
+const updateTagihanAttempts = 3
+
+// UpdateTagihan mengubah siswa, kursus atau jatuh tempo tagihan. Data salinan
+// (nama siswa, email, nama kursus dan baris biaya kursus) ikut diperbarui, dan
+// status terlambat dihitung ulang dari jatuh tempo yang baru. Saldo dan denda
+// tidak disentuh; jika keduanya berubah di tengah jalan, perubahan dihitung
+// ulang dari tagihan terbaru.
+func (s *BillingService) UpdateTagihan(ctx context.Context, id primitive.ObjectID, in UpdateTagihanInput) (models.Tagihan, error) {
+	var siswa *models.Siswa
+	if in.SiswaID != nil {
+		found, err := s.siswa.Get(ctx, *in.SiswaID)
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Siswa")
+		}
+		siswa = &found
+	}
+	var course *models.Course
+	if in.CourseID != nil {
+		found, err := s.course.Get(ctx, *in.CourseID)
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Course")
+		}
+		course = &found
+	}
+
+	for attempt := 0; ; attempt++ {
+		tagihan, err := s.tagihan.Get(ctx, id)
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Tagihan")
+		}
+
+		now := s.now()
+		details := repository.TagihanDetails{
+			SiswaID:             tagihan.SiswaID,
+			SiswaNama:           tagihan.SiswaNama,
+			SiswaEmail:          tagihan.SiswaEmail,
+			CourseID:            tagihan.CourseID,
+			CourseName:          tagihan.CourseName,
+			DueDate:             tagihan.DueDate.Time(),
+			UpdatedAt:           now,
+			ExpectedLedgerCount: tagihan.LedgerCount,
+			ExpectedAmount:      tagihan.Amount,
+			ExpectedStatus:      tagihan.Status,
+		}
+		if in.DueDate != nil {
+			details.DueDate = *in.DueDate
+		}
+		if siswa != nil {
+			details.SiswaID = siswa.ID
+			details.SiswaNama = siswa.FullName
+			details.SiswaEmail = siswa.Email
+		}
+		if course != nil {
+			details.CourseID = course.ID
+			details.CourseName = course.Name
+			details.CourseItem = renameCourseItem(tagihan, course.Name)
+		}
+		details.Status, details.OverdueAt = overdueStatus(tagihan, details.DueDate, now)
+
+		err = s.tagihan.UpdateDetails(ctx, id, details)
+		if errors.Is(err, repository.ErrConflict) {
+			if attempt+1 < updateTagihanAttempts {
+				continue
+			}
+			return models.Tagihan{}, ErrTagihanChanged
+		}
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Tagihan")
+		}
+
+		updated, err := s.tagihan.Get(ctx, id)
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Tagihan")
+		}
+		return updated, nil
+	}
+}
+
+// overdueStatus menghitung status tagihan yang belum lunas jika jatuh temponya
+// menjadi due: terlambat jika sudah lewat, belum bayar atau sebagian jika belum.
+// overdue_at dipertahankan selama tagihan tetap terlambat.
+func overdueStatus(tagihan models.Tagihan, due, now time.Time) (string, *time.Time) {
+	status := tagihan.Status
+	switch status {
+	case models.TagihanStatusUnpaid, models.TagihanStatusPartial, models.TagihanStatusOverdue:
+		status = tagihanStatus(toCents(tagihan.Amount), toCents(tagihan.PaidAmount))
+		if due.Before(now) {
+			status = models.TagihanStatusOverdue
+		}
+	}
+	if status != models.TagihanStatusOverdue {
+		return status, nil
+	}
+	if tagihan.OverdueAt != nil {
+		overdueAt := tagihan.OverdueAt.Time()
+		return status, &overdueAt
+	}
+	return status, &now
+}
+
+// renameCourseItem membuat deskripsi baris biaya kursus untuk kursus baru.
+// Akhiran siklus seperti " (2/6)" dipertahankan; kosong jika tagihan tidak
+// punya baris biaya kursus.
+func renameCourseItem(tagihan models.Tagihan, courseName string) string {
+	for _, item := range tagihan.Items {
+		if item.Kind == models.TagihanItemCourse {
+			return courseName + strings.TrimPrefix(item.Description, tagihan.CourseName)
+		}
+	}
+	return ""
+}
+
+// courseItem membuat baris biaya kursus untuk rincian tagihan
+func courseItem(description string, amount float64, at time.Time) models.TagihanItem {
+	return models.TagihanItem{
+		Kind:        models.TagihanItemCourse,
+		Description: description,
+		Amount:      amount,
+		CreatedAt:   primitive.NewDateTimeFromTime(at),
+	}
+}
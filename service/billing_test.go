@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+)
+
+func TestUpdateTagihanKeepsBalanceAndFees(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	biola := models.Course{Name: "Biola", Cost: 250}
+	if err := repos.Course.Create(ctx, &biola); err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+
+	tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID, DueDate: clock.now.AddDate(0, 0, 7)})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	if _, _, err := svc.RecordPayment(ctx, tagihan.ID, PaymentInput{Amount: 40, Method: models.PaymentMethodCash}); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Jatuh tempo dimundurkan ke kemarin: tagihan langsung terlambat
+	pastDue := clock.now.AddDate(0, 0, -1)
+	updated, err := svc.UpdateTagihan(ctx, tagihan.ID, UpdateTagihanInput{DueDate: &pastDue})
+	if err != nil {
+		t.Fatalf("UpdateTagihan: %v", err)
+	}
+	if updated.Status != models.TagihanStatusOverdue || updated.OverdueAt == nil {
+		t.Errorf("status = %q, overdue at %v; want overdue", updated.Status, updated.OverdueAt)
+	}
+	if updated.PaidAmount != 40 || updated.LedgerCount != 1 {
+		t.Errorf("paid = %v from %d entries, want 40 from 1", updated.PaidAmount, updated.LedgerCount)
+	}
+
+	if err := svc.MarkOverdue(ctx, LateFeePolicy{Flat: 10}); err != nil {
+		t.Fatalf("MarkOverdue: %v", err)
+	}
+
+	// Kursus diganti dan jatuh tempo dimajukan: denda tetap ada, status kembali sebagian
+	future := clock.now.AddDate(0, 0, 14)
+	updated, err = svc.UpdateTagihan(ctx, tagihan.ID, UpdateTagihanInput{CourseID: &biola.ID, DueDate: &future})
+	if err != nil {
+		t.Fatalf("UpdateTagihan: %v", err)
+	}
+	if updated.Status != models.TagihanStatusPartial || updated.OverdueAt != nil {
+		t.Errorf("status = %q, overdue at %v; want partial", updated.Status, updated.OverdueAt)
+	}
+	if updated.Amount != 110 || updated.Outstanding != 70 || updated.LateFees != 1 {
+		t.Errorf("amount/outstanding/fees = %v/%v/%d, want 110/70/1", updated.Amount, updated.Outstanding, updated.LateFees)
+	}
+	if updated.CourseID != biola.ID || updated.CourseName != "Biola" {
+		t.Errorf("course = %s %q, want %s Biola", updated.CourseID.Hex(), updated.CourseName, biola.ID.Hex())
+	}
+	kinds := map[string]string{}
+	for _, item := range updated.Items {
+		kinds[item.Kind] = item.Description
+	}
+	if kinds[models.TagihanItemCourse] != "Biola" {
+		t.Errorf("course item = %q, want Biola", kinds[models.TagihanItemCourse])
+	}
+	if _, ok := kinds[models.TagihanItemLateFee]; !ok {
+		t.Errorf("late fee item was dropped: %+v", updated.Items)
+	}
+}
+
+func TestUpdateDetailsRejectsStaleTagihan(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, _, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	if _, _, err := svc.RecordPayment(ctx, tagihan.ID, PaymentInput{Amount: 40, Method: models.PaymentMethodCash}); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Perubahan yang dihitung dari tagihan sebelum pembayaran tidak boleh menimpa saldonya
+	err = repos.Tagihan.UpdateDetails(ctx, tagihan.ID, repository.TagihanDetails{
+		SiswaID:             tagihan.SiswaID,
+		CourseID:            tagihan.CourseID,
+		DueDate:             tagihan.DueDate.Time(),
+		Status:              models.TagihanStatusUnpaid,
+		ExpectedLedgerCount: tagihan.LedgerCount,
+		ExpectedAmount:      tagihan.Amount,
+		ExpectedStatus:      tagihan.Status,
+	})
+	if !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("UpdateDetails: error = %v, want %v", err, repository.ErrConflict)
+	}
+
+	stored, err := repos.Tagihan.Get(ctx, tagihan.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Status != models.TagihanStatusPartial || stored.PaidAmount != 40 {
+		t.Errorf("stored tagihan = %q/%v, want partial/40", stored.Status, stored.PaidAmount)
+	}
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/organisasi/tubesbackend/invoice"
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"github.com/organisasi/tubesbackend/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DocumentOptions mengatur kop, penomoran dan link verifikasi dokumen cetak
+type DocumentOptions struct {
+	Header        invoice.Header
+	InvoicePrefix string // Awalan nomor invoice, contoh "INV" -> INV/2026/000001
+	ReceiptPrefix string // Awalan nomor kuitansi
+	BaseURL       string // Alamat publik server untuk link verifikasi di QR code
+	Location      *time.Location
+}
+
+// DocumentService menerbitkan invoice PDF untuk tagihan, kuitansi untuk setiap
+// pembayaran tagihan, serta invoice dan kuitansi transaksi siswa. Nomor dokumen
+// berurutan per jenis per tahun tanpa loncatan dan diberikan sekali saat
+// dokumen pertama kali dicetak.
+type DocumentService struct {
+	documents repository.DocumentRepository
+	tagihan   repository.TagihanRepository
+	payments  repository.PaymentRepository
+	transaksi repository.TransaksiSiswaRepository
+	siswa     repository.SiswaRepository
+	opts      DocumentOptions
+	now       func() time.Time
+}
+
+// NewDocumentService membuat DocumentService di atas repository yang diberikan
+func NewDocumentService(repos *repository.Repositories, opts DocumentOptions) *DocumentService {
+	if opts.Location == nil {
+		opts.Location, _ = time.LoadLocation("Asia/Jakarta")
+	}
+	return &DocumentService{
+		documents: repos.Document,
+		tagihan:   repos.Tagihan,
+		payments:  repos.Payment,
+		transaksi: repos.TransaksiSiswa,
+		siswa:     repos.Siswa,
+		opts:      opts,
+		now:       time.Now,
+	}
+}
+
+// TagihanDocument menyusun invoice sebuah tagihan beserta rincian dan riwayat
+// pembayarannya. kind harus models.DocumentInvoice; kuitansi tagihan dicetak
+// per pembayaran lewat PaymentReceipt. Status berisi kode status tagihan.
+func (s *DocumentService) TagihanDocument(ctx context.Context, kind string, id primitive.ObjectID) (invoice.Document, error) {
+	if kind != models.DocumentInvoice {
+		return invoice.Document{}, ErrNotAPayment
+	}
+	tagihan, err := s.tagihan.Get(ctx, id)
+	if err != nil {
+		return invoice.Document{}, notFound(err, "Tagihan")
+	}
+	entries, err := s.payments.ListByTagihan(ctx, id)
+	if err != nil {
+		return invoice.Document{}, err
+	}
+	issued, err := s.issue(ctx, kind, models.DocumentEntityTagihan, id)
+	if err != nil {
+		return invoice.Document{}, err
+	}
+
+	doc := s.newDocument(issued)
+	doc.DueDate = tagihan.DueDate.Time().In(s.opts.Location)
+	doc.Status = tagihan.Status
+	doc.BillTo = s.billTo(ctx, tagihan.SiswaID, tagihan.SiswaNama, tagihan.SiswaEmail)
+	doc.Lines = tagihanLines(tagihan)
+	doc.Total = tagihan.Amount
+	doc.Paid = tagihan.PaidAmount
+	doc.Outstanding = tagihan.Outstanding
+	doc.Payments = s.paymentHistory(entries)
+	return doc, nil
+}
+
+// PaymentReceipt menyusun kuitansi satu pembayaran di ledger tagihan, sehingga
+// setiap cicilan atau pembayaran sebagian punya kuitansi bernomor sendiri.
+// Riwayat dan saldo di kuitansi adalah keadaan ledger sampai pembayaran itu.
+func (s *DocumentService) PaymentReceipt(ctx context.Context, tagihanID, paymentID primitive.ObjectID) (invoice.Document, error) {
+	tagihan, err := s.tagihan.Get(ctx, tagihanID)
+	if err != nil {
+		return invoice.Document{}, notFound(err, "Tagihan")
+	}
+	payment, err := s.payments.Get(ctx, paymentID)
+	if err != nil || payment.TagihanID != tagihanID {
+		return invoice.Document{}, notFound(repository.ErrNotFound, "Payment")
+	}
+	if payment.Kind != models.PaymentKindPayment {
+		return invoice.Document{}, ErrNotAPayment
+	}
+	entries, err := s.payments.ListByTagihan(ctx, tagihanID)
+	if err != nil {
+		return invoice.Document{}, err
+	}
+	for i, entry := range entries {
+		if entry.ID == paymentID {
+			entries = entries[:i+1]
+			break
+		}
+	}
+	issued, err := s.issue(ctx, models.DocumentReceipt, models.DocumentEntityPayment, paymentID)
+	if err != nil {
+		return invoice.Document{}, err
+	}
+
+	amount, paid := toCents(tagihan.Amount), netPaid(entries)
+	doc := s.newDocument(issued)
+	doc.DueDate = tagihan.DueDate.Time().In(s.opts.Location)
+	doc.Status = tagihanStatus(amount, paid)
+	doc.BillTo = s.billTo(ctx, tagihan.SiswaID, tagihan.SiswaNama, tagihan.SiswaEmail)
+	doc.Lines = tagihanLines(tagihan)
+	doc.Total = tagihan.Amount
+	doc.Paid = fromCents(paid)
+	doc.Outstanding = fromCents(amount - paid)
+	doc.Payments = s.paymentHistory(entries)
+	return doc, nil
+}
+
+// tagihanLines mengubah rincian tagihan menjadi baris dokumen
+func tagihanLines(tagihan models.Tagihan) []invoice.Line {
+	items := tagihan.Items
+	if len(items) == 0 {
+		// Tagihan lama belum punya rincian; seluruh nominalnya adalah biaya kursus
+		items = []models.TagihanItem{courseItem(tagihan.CourseName, tagihan.Amount, tagihan.CreatedAt.Time())}
+	}
+	lines := make([]invoice.Line, len(items))
+	for i, item := range items {
+		lines[i] = invoice.Line{Description: item.Description, Amount: item.Amount}
+	}
+	return lines
+}
+
+// TransaksiDocument menyusun invoice atau kuitansi sebuah transaksi siswa.
+// Kuitansi hanya bisa dicetak setelah transaksi dibayar. Status berisi kode
+// status transaksi.
+func (s *DocumentService) TransaksiDocument(ctx context.Context, kind string, id primitive.ObjectID) (invoice.Document, error) {
+	transaksi, err := s.transaksi.Get(ctx, id)
+	if err != nil {
+		return invoice.Document{}, notFound(err, "Transaksi")
+	}
+	paid := transaksi.Status == models.TransaksiStatusPaid
+	if kind == models.DocumentReceipt && !paid {
+		return invoice.Document{}, ErrTransaksiNotPaid
+	}
+	issued, err := s.issue(ctx, kind, models.DocumentEntityTransaksiSiswa, id)
+	if err != nil {
+		return invoice.Document{}, err
+	}
+
+	doc := s.newDocument(issued)
+	doc.Status = transaksi.Status
+	doc.BillTo = s.billTo(ctx, transaksi.SiswaID, "", "")
+	doc.Lines = []invoice.Line{{Description: transaksi.Item, Amount: transaksi.Harga}}
+	doc.Total = transaksi.Harga
+	doc.Outstanding = transaksi.Harga
+	if paid {
+		payment := invoice.Payment{Kind: models.PaymentKindPayment, Amount: transaksi.Harga}
+		if transaksi.PaidAt != nil {
+			// Transaksi yang dibayar sebelum PaidAt dicatat tampil tanpa tanggal
+			payment.Date = transaksi.PaidAt.Time().In(s.opts.Location)
+		}
+		doc.Payments = []invoice.Payment{payment}
+		doc.Paid = transaksi.Harga
+		doc.Outstanding = 0
+	}
+	return doc, nil
+}
+
+// Render membuat PDF dari dokumen; t menerjemahkan label ke bahasa request
+func (s *DocumentService) Render(doc invoice.Document, t func(string) string) ([]byte, error) {
+	return invoice.Render(s.opts.Header, doc, t)
+}
+
+// DocumentVerification adalah data yang ditampilkan saat QR code di dokumen
+// dipindai. Isinya cukup untuk mencocokkan dokumen cetak dengan data terbaru.
+type DocumentVerification struct {
+	Kind        string             `json:"kind"`
+	Number      string             `json:"number"`
+	IssuedAt    primitive.DateTime `json:"issued_at"`
+	Entity      string             `json:"entity"`
+	SiswaNama   string             `json:"siswa_nama,omitempty"`
+	Description string             `json:"description"`
+	Amount      float64            `json:"amount"`
+	PaidAmount  float64            `json:"paid_amount"`
+	Status      string             `json:"status"`
+	StatusLabel string             `json:"status_label,omitempty"`
+	Voided      bool               `json:"voided,omitempty"` // Pembayaran di kuitansi sudah dibatalkan
+}
+
+// Verify mencari dokumen dari kode di link verifikasi dan mengembalikan
+// keadaan terbaru tagihan, pembayaran atau transaksinya
+func (s *DocumentService) Verify(ctx context.Context, code string) (DocumentVerification, error) {
+	doc, err := s.documents.FindByCode(ctx, code)
+	if err != nil {
+		return DocumentVerification{}, notFound(err, "Document")
+	}
+	if doc.Number == "" {
+		// Belum selesai diterbitkan, jadi belum pernah tercetak
+		return DocumentVerification{}, &NotFoundError{Entity: "Document"}
+	}
+	result := DocumentVerification{Kind: doc.Kind, Number: doc.Number, IssuedAt: doc.IssuedAt, Entity: doc.Entity}
+
+	switch doc.Entity {
+	case models.DocumentEntityTagihan:
+		tagihan, err := s.tagihan.Get(ctx, doc.EntityID)
+		if err != nil {
+			return DocumentVerification{}, notFound(err, "Document")
+		}
+		result.SiswaNama = tagihan.SiswaNama
+		result.Description = tagihan.CourseName
+		result.Amount = tagihan.Amount
+		result.PaidAmount = tagihan.PaidAmount
+		result.Status = tagihan.Status
+	case models.DocumentEntityPayment:
+		payment, err := s.payments.Get(ctx, doc.EntityID)
+		if err != nil {
+			return DocumentVerification{}, notFound(err, "Document")
+		}
+		tagihan, err := s.tagihan.Get(ctx, payment.TagihanID)
+		if err != nil {
+			return DocumentVerification{}, notFound(err, "Document")
+		}
+		entries, err := s.payments.ListByTagihan(ctx, payment.TagihanID)
+		if err != nil {
+			return DocumentVerification{}, err
+		}
+		result.SiswaNama = tagihan.SiswaNama
+		result.Description = tagihan.CourseName
+		result.Amount = payment.Amount
+		result.PaidAmount = payment.Amount
+		result.Status = tagihan.Status
+		for _, entry := range entries {
+			if entry.Kind == models.PaymentKindVoid && entry.VoidOf != nil && *entry.VoidOf == payment.ID {
+				result.PaidAmount = 0
+				result.Voided = true
+			}
+		}
+	case models.DocumentEntityTransaksiSiswa:
+		transaksi, err := s.transaksi.Get(ctx, doc.EntityID)
+		if err != nil {
+			return DocumentVerification{}, notFound(err, "Document")
+		}
+		if siswa, err := s.siswa.Get(ctx, transaksi.SiswaID); err == nil {
+			result.SiswaNama = siswa.FullName
+		}
+		result.Description = transaksi.Item
+		result.Amount = transaksi.Harga
+		if transaksi.Status == models.TransaksiStatusPaid {
+			result.PaidAmount = transaksi.Harga
+		}
+		result.Status = transaksi.Status
+	default:
+		return DocumentVerification{}, &NotFoundError{Entity: "Document"}
+	}
+	return result, nil
+}
+
+// pendingNumberTimeout adalah batas waktu request yang menyimpan dokumen untuk
+// memberi nomornya. Setelah itu request tersebut dianggap gagal dan nomornya
+// diberikan oleh request berikutnya.
+const pendingNumberTimeout = time.Minute
+
+// issue mengembalikan dokumen yang sudah pernah diterbitkan untuk entitas, atau
+// menerbitkan dokumen baru. Dokumen disimpan tanpa nomor lebih dulu; hanya
+// request yang berhasil menyimpannya yang mengambil nomor urut, sehingga
+// request bersamaan tidak membuang nomor.
+func (s *DocumentService) issue(ctx context.Context, kind, entity string, entityID primitive.ObjectID) (models.Document, error) {
+	doc, err := s.documents.Find(ctx, kind, entity, entityID)
+	if errors.Is(err, repository.ErrNotFound) {
+		code, err := utils.GenerateRandomToken(16)
+		if err != nil {
+			return models.Document{}, err
+		}
+		doc = models.Document{
+			ID:       primitive.NewObjectID(),
+			Kind:     kind,
+			Entity:   entity,
+			EntityID: entityID,
+			Code:     code,
+			IssuedAt: primitive.NewDateTimeFromTime(s.now()),
+		}
+		err = s.documents.Create(ctx, &doc)
+		if err == nil {
+			return s.assignNumber(ctx, doc)
+		}
+		if !errors.Is(err, repository.ErrDuplicate) {
+			return models.Document{}, err
+		}
+		// Dicetak bersamaan oleh request lain; nomornya diberikan oleh request itu
+		doc, err = s.documents.Find(ctx, kind, entity, entityID)
+		if err != nil {
+			return models.Document{}, err
+		}
+	} else if err != nil {
+		return models.Document{}, err
+	}
+
+	if doc.Number == "" {
+		if s.now().Sub(doc.IssuedAt.Time()) < pendingNumberTimeout {
+			return models.Document{}, ErrDocumentPending
+		}
+		return s.assignNumber(ctx, doc)
+	}
+	return doc, nil
+}
+
+// assignNumber mengambil nomor urut berikutnya untuk tahun terbit dokumen lalu
+// menyimpannya di dokumen
+func (s *DocumentService) assignNumber(ctx context.Context, doc models.Document) (models.Document, error) {
+	prefix := s.opts.InvoicePrefix
+	if doc.Kind == models.DocumentReceipt {
+		prefix = s.opts.ReceiptPrefix
+	}
+	year := doc.IssuedAt.Time().In(s.opts.Location).Year()
+	seq, err := s.documents.NextSeq(ctx, fmt.Sprintf("%s-%d", doc.Kind, year))
+	if err != nil {
+		return models.Document{}, err
+	}
+
+	number := fmt.Sprintf("%s/%d/%06d", prefix, year, seq)
+	err = s.documents.SetNumber(ctx, doc.ID, number)
+	if errors.Is(err, repository.ErrConflict) {
+		// Sudah diberi nomor oleh request lain yang mengambil alih dokumen ini
+		return s.documents.Find(ctx, doc.Kind, doc.Entity, doc.EntityID)
+	}
+	if err != nil {
+		return models.Document{}, err
+	}
+	doc.Number = number
+	return doc, nil
+}
+
+// newDocument mengisi bagian dokumen yang sama untuk semua entitas
+func (s *DocumentService) newDocument(issued models.Document) invoice.Document {
+	return invoice.Document{
+		Kind:      issued.Kind,
+		Number:    issued.Number,
+		IssuedAt:  issued.IssuedAt.Time().In(s.opts.Location),
+		VerifyURL: strings.TrimRight(s.opts.BaseURL, "/") + "/documents/verify/" + issued.Code,
+	}
+}
+
+// billTo menyusun identitas penerima dari data siswa terbaru. Jika siswa sudah
+// dihapus, dipakai nama dan email yang disalin ke dokumen asalnya.
+func (s *DocumentService) billTo(ctx context.Context, siswaID primitive.ObjectID, nama, email string) []string {
+	address, phone := "", ""
+	if siswa, err := s.siswa.Get(ctx, siswaID); err == nil {
+		nama, email, address, phone = siswa.FullName, siswa.Email, siswa.Address, siswa.PhoneNumber
+	}
+	lines := []string{}
+	for _, line := range []string{nama, email, phone, address} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// paymentHistory mengubah entri ledger menjadi riwayat pembayaran. Refund dan
+// void yang mengurangi jumlah dibayar ditampilkan negatif.
+func (s *DocumentService) paymentHistory(entries []models.Payment) []invoice.Payment {
+	byID := make(map[primitive.ObjectID]models.Payment, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	history := make([]invoice.Payment, 0, len(entries))
+	for _, entry := range entries {
+		amount := entry.Amount
+		switch entry.Kind {
+		case models.PaymentKindRefund:
+			amount = -amount
+		case models.PaymentKindVoid:
+			if entry.VoidOf != nil && byID[*entry.VoidOf].Kind == models.PaymentKindPayment {
+				amount = -amount
+			}
+		}
+		history = append(history, invoice.Payment{
+			Date:      entry.ReceivedAt.Time().In(s.opts.Location),
+			Kind:      entry.Kind,
+			Method:    entry.Method,
+			Reference: entry.Reference,
+			Amount:    amount,
+		})
+	}
+	return history
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestDocuments membuat DocumentService yang memakai repository dan jam
+// yang sama dengan BillingService dari newTestBilling
+func newTestDocuments(repos *repository.Repositories, clock *testClock) *DocumentService {
+	docs := NewDocumentService(repos, DocumentOptions{InvoicePrefix: "INV", ReceiptPrefix: "KWT", Location: time.UTC})
+	docs.now = clock.Now
+	return docs
+}
+
+func TestPaymentReceiptsAreNumberedPerPayment(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	docs := newTestDocuments(repos, clock)
+
+	tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	var payments []models.Payment
+	for _, amount := range []float64{30, 70} {
+		payment, _, err := svc.RecordPayment(ctx, tagihan.ID, PaymentInput{Amount: amount, Method: models.PaymentMethodCash})
+		if err != nil {
+			t.Fatalf("RecordPayment: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+
+	tests := []struct {
+		payment     models.Payment
+		wantNumber  string
+		wantStatus  string
+		wantPaid    float64
+		wantEntries int
+	}{
+		{payment: payments[0], wantNumber: "KWT/2025/000001", wantStatus: models.TagihanStatusPartial, wantPaid: 30, wantEntries: 1},
+		{payment: payments[1], wantNumber: "KWT/2025/000002", wantStatus: models.TagihanStatusPaid, wantPaid: 100, wantEntries: 2},
+		// Dicetak ulang memakai nomor yang sama
+		{payment: payments[0], wantNumber: "KWT/2025/000001", wantStatus: models.TagihanStatusPartial, wantPaid: 30, wantEntries: 1},
+	}
+	for i, tt := range tests {
+		doc, err := docs.PaymentReceipt(ctx, tagihan.ID, tt.payment.ID)
+		if err != nil {
+			t.Fatalf("receipt %d: %v", i, err)
+		}
+		if doc.Number != tt.wantNumber {
+			t.Errorf("receipt %d: number = %q, want %q", i, doc.Number, tt.wantNumber)
+		}
+		if doc.Status != tt.wantStatus || doc.Paid != tt.wantPaid || len(doc.Payments) != tt.wantEntries {
+			t.Errorf("receipt %d: %q, paid %v from %d entries; want %q, %v from %d",
+				i, doc.Status, doc.Paid, len(doc.Payments), tt.wantStatus, tt.wantPaid, tt.wantEntries)
+		}
+	}
+
+	other, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	var missing *NotFoundError
+	if _, err := docs.PaymentReceipt(ctx, other.ID, payments[0].ID); !errors.As(err, &missing) {
+		t.Errorf("receipt through another tagihan: error = %v, want NotFoundError", err)
+	}
+	if _, err := docs.TagihanDocument(ctx, models.DocumentReceipt, tagihan.ID); !errors.Is(err, ErrNotAPayment) {
+		t.Errorf("tagihan receipt: error = %v, want %v", err, ErrNotAPayment)
+	}
+}
+
+func TestConcurrentIssueDoesNotSkipNumbers(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	docs := newTestDocuments(repos, clock)
+
+	var tagihans []models.Tagihan
+	for i := 0; i < 3; i++ {
+		tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+		if err != nil {
+			t.Fatalf("CreateTagihan: %v", err)
+		}
+		tagihans = append(tagihans, tagihan)
+	}
+
+	// Setiap tagihan dicetak beberapa kali bersamaan; request yang kalah tidak boleh mengambil nomor
+	var wg sync.WaitGroup
+	for _, tagihan := range tagihans {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(id primitive.ObjectID) {
+				defer wg.Done()
+				_, err := docs.TagihanDocument(ctx, models.DocumentInvoice, id)
+				if err != nil && !errors.Is(err, ErrDocumentPending) {
+					t.Errorf("TagihanDocument: %v", err)
+				}
+			}(tagihan.ID)
+		}
+	}
+	wg.Wait()
+
+	numbers := map[string]bool{}
+	for _, tagihan := range tagihans {
+		doc, err := docs.TagihanDocument(ctx, models.DocumentInvoice, tagihan.ID)
+		if err != nil {
+			t.Fatalf("TagihanDocument: %v", err)
+		}
+		numbers[doc.Number] = true
+	}
+	for _, want := range []string{"INV/2025/000001", "INV/2025/000002", "INV/2025/000003"} {
+		if !numbers[want] {
+			t.Errorf("numbers = %v, missing %s", numbers, want)
+		}
+	}
+}
+
+func TestStalePendingDocumentIsNumbered(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	docs := newTestDocuments(repos, clock)
+
+	tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	// Request yang menyimpan dokumen ini berhenti sebelum memberi nomor
+	pending := models.Document{
+		Kind:     models.DocumentInvoice,
+		Entity:   models.DocumentEntityTagihan,
+		EntityID: tagihan.ID,
+		Code:     "pending",
+		IssuedAt: primitive.NewDateTimeFromTime(clock.now),
+	}
+	if err := repos.Document.Create(ctx, &pending); err != nil {
+		t.Fatalf("create document: %v", err)
+	}
+
+	if _, err := docs.TagihanDocument(ctx, models.DocumentInvoice, tagihan.ID); !errors.Is(err, ErrDocumentPending) {
+		t.Fatalf("TagihanDocument: error = %v, want %v", err, ErrDocumentPending)
+	}
+	if _, err := docs.Verify(ctx, pending.Code); err == nil {
+		t.Errorf("Verify of an unnumbered document: want error")
+	}
+
+	clock.now = clock.now.Add(pendingNumberTimeout)
+	doc, err := docs.TagihanDocument(ctx, models.DocumentInvoice, tagihan.ID)
+	if err != nil {
+		t.Fatalf("TagihanDocument: %v", err)
+	}
+	if doc.Number != "INV/2025/000001" {
+		t.Errorf("number = %q, want INV/2025/000001", doc.Number)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EnrollmentService mengatur pendaftaran siswa dan aktivasinya lewat pembayaran
+type EnrollmentService struct {
+	siswa     repository.SiswaRepository
+	transaksi repository.TransaksiSiswaRepository
+	now       func() time.Time
+}
+
+// NewEnrollmentService membuat EnrollmentService di atas repository yang diberikan
+func NewEnrollmentService(repos *repository.Repositories) *EnrollmentService {
+	return &EnrollmentService{
+		siswa:     repos.Siswa,
+		transaksi: repos.TransaksiSiswa,
+		now:       time.Now,
+	}
+}
+
+// RegisterSiswa menyimpan siswa baru. Siswa baru selalu nonaktif sampai
+// transaksi pendaftarannya dibayar.
+func (s *EnrollmentService) RegisterSiswa(ctx context.Context, siswa models.Siswa) (models.Siswa, error) {
+	siswa.ID = primitive.NewObjectID()
+	siswa.Status = models.SiswaStatusInactive
+	if err := s.siswa.Create(ctx, &siswa); err != nil {
+		return models.Siswa{}, err
+	}
+	return siswa, nil
+}
+
+// CreateTransaksi mencatat transaksi siswa baru dengan tanggal saat ini
+func (s *EnrollmentService) CreateTransaksi(ctx context.Context, transaksi models.TransaksiSiswa) (models.TransaksiSiswa, error) {
+	if transaksi.Item == "" {
+		return models.TransaksiSiswa{}, &ValidationError{Field: "item", Message: "Item is required"}
+	}
+	if transaksi.Harga <= 0 {
+		return models.TransaksiSiswa{}, &ValidationError{Field: "harga", Message: "Harga must be greater than 0"}
+	}
+
+	transaksi.ID = primitive.NewObjectID()
+	transaksi.Status = models.TransaksiStatusPending // Transaksi baru selalu menunggu pembayaran
+	transaksi.Tanggal = primitive.NewDateTimeFromTime(s.now())
+	if err := s.transaksi.Create(ctx, &transaksi); err != nil {
+		return models.TransaksiSiswa{}, err
+	}
+	return transaksi, nil
+}
+
+// MarkTransaksiPaid melunasi transaksi lalu mengaktifkan siswa pemiliknya
+func (s *EnrollmentService) MarkTransaksiPaid(ctx context.Context, id primitive.ObjectID) error {
+	transaksi, err := s.transaksi.Get(ctx, id)
+	if err != nil {
+		return notFound(err, "Transaksi")
+	}
+	if transaksi.Status == models.TransaksiStatusPaid {
+		return ErrTransaksiAlreadyPaid
+	}
+
+	if err := s.transaksi.SetStatus(ctx, id, models.TransaksiStatusPaid); err != nil {
+		return notFound(err, "Transaksi")
+	}
+	if err := s.siswa.SetStatus(ctx, transaksi.SiswaID, models.SiswaStatusActive); err != nil {
+		return notFound(err, "Siswa")
+	}
+	return nil
+}
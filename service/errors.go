@@ -0,0 +1,61 @@
+// Package service berisi aturan bisnis yang tidak bergantung pada Gin,
+// sehingga bisa dipakai ulang oleh handler HTTP, CLI maupun scheduler.
+package service
+
+import (
+	"errors"
+
+	"github.com/organisasi/tubesbackend/repository"
+)
+
+// NotFoundError menandakan entitas yang dibutuhkan tidak ada
+type NotFoundError struct {
+	Entity string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entity + " not found"
+}
+
+// ValidationError menandakan input melanggar aturan bisnis
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ConflictError menandakan operasi bertabrakan dengan kondisi data saat ini
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// Error domain yang bisa dicek dengan errors.Is
+var (
+	ErrTransaksiAlreadyPaid = &ConflictError{Message: "Transaksi is already paid"}
+	ErrTagihanAlreadyPaid   = &ConflictError{Message: "Tagihan is already paid"}
+	ErrPayoutExists         = &ConflictError{Message: "This guru already has a payout for this month"}
+	ErrPaymentReference     = &ConflictError{Message: "A payment with this reference is already recorded"}
+	ErrEntryAlreadyVoided   = &ConflictError{Message: "This ledger entry is already voided"}
+	ErrLedgerChanged        = &ConflictError{Message: "The ledger changed in the meantime, please retry"}
+	ErrTagihanChanged       = &ConflictError{Message: "The tagihan changed in the meantime, please retry"}
+	ErrAlreadyEnrolled      = &ConflictError{Message: "This siswa already has an active billing schedule for this course"}
+	ErrScheduleNotActive    = &ConflictError{Message: "This billing schedule is no longer active"}
+	ErrNotAPayment          = &ConflictError{Message: "Receipts are only issued for payments"}
+	ErrDocumentPending      = &ConflictError{Message: "This document is still being issued, please retry"}
+	ErrTransaksiNotPaid     = &ConflictError{Message: "Transaksi has not been paid yet"}
+)
+
+// notFound mengubah repository.ErrNotFound menjadi NotFoundError untuk entitas tertentu
+func notFound(err error, entity string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &NotFoundError{Entity: entity}
+	}
+	return err
+}
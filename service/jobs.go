@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every menjalankan fn sekali saat dipanggil lalu setiap interval sampai ctx
+// selesai. Error dari fn hanya dicatat di log agar job tetap berjalan.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
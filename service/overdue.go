@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LateFeePolicy mengatur denda keterlambatan. Policy kosong berarti tagihan
+// hanya ditandai terlambat tanpa denda.
+type LateFeePolicy struct {
+	Flat       float64 // Nominal tetap per denda
+	Percent    float64 // Persen dari nominal tagihan sebelum denda
+	GraceDays  int     // Denda pertama dikenakan setelah terlambat lebih dari sekian hari
+	RepeatDays int     // Denda berikutnya setiap sekian hari; 0 berarti hanya sekali
+	MaxFees    int     // Batas jumlah denda per tagihan; 0 berarti tidak dibatasi
+}
+
+// enabled mengecek apakah policy mengenakan denda
+func (p LateFeePolicy) enabled() bool {
+	return p.Flat > 0 || p.Percent > 0
+}
+
+// feesDue menghitung berapa denda yang seharusnya sudah dikenakan untuk
+// tagihan yang terlambat sekian hari
+func (p LateFeePolicy) feesDue(daysLate int) int {
+	if !p.enabled() || daysLate <= p.GraceDays {
+		return 0
+	}
+	n := 1
+	if p.RepeatDays > 0 {
+		n += (daysLate - p.GraceDays - 1) / p.RepeatDays
+	}
+	if p.MaxFees > 0 && n > p.MaxFees {
+		n = p.MaxFees
+	}
+	return n
+}
+
+// MarkOverdue menandai tagihan yang belum lunas dan sudah lewat jatuh tempo
+// sebagai terlambat, lalu mengenakan denda sesuai policy. Dipanggil scheduler
+// secara berkala dan aman diulang: denda yang sudah dikenakan tidak dikenakan
+// lagi. Semua tagihan tetap diproses walaupun ada yang gagal.
+func (s *BillingService) MarkOverdue(ctx context.Context, policy LateFeePolicy) error {
+	now := s.now()
+	candidates, err := s.tagihan.List(ctx, repository.TagihanFilter{
+		Statuses:  []string{models.TagihanStatusUnpaid, models.TagihanStatusPartial, models.TagihanStatusOverdue},
+		DueBefore: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	var marked, fees int
+	for _, tagihan := range candidates {
+		if tagihan.Status != models.TagihanStatusOverdue {
+			err := s.tagihan.MarkOverdue(ctx, tagihan.ID, now)
+			if errors.Is(err, repository.ErrConflict) {
+				// Sudah dibayar atau diubah di antara List dan MarkOverdue
+				continue
+			}
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			tagihan.Status = models.TagihanStatusOverdue
+			marked++
+		}
+
+		n, err := s.applyLateFees(ctx, tagihan, policy, now)
+		fees += n
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if marked > 0 || fees > 0 {
+		log.Printf("Marked %d tagihan overdue and applied %d late fees", marked, fees)
+	}
+	return firstErr
+}
+
+// applyLateFees mengenakan denda yang belum dikenakan ke satu tagihan terlambat
+func (s *BillingService) applyLateFees(ctx context.Context, tagihan models.Tagihan, policy LateFeePolicy, now time.Time) (int, error) {
+	due := policy.feesDue(daysLate(tagihan.DueDate.Time(), now))
+	if due <= tagihan.LateFees {
+		return 0, nil
+	}
+
+	// Denda persen dihitung dari nominal sebelum denda agar tidak berbunga
+	base := toCents(tagihan.Amount)
+	for _, item := range tagihan.Items {
+		if item.Kind == models.TagihanItemLateFee {
+			base -= toCents(item.Amount)
+		}
+	}
+	fee := toCents(policy.Flat) + int64(math.Round(float64(base)*policy.Percent/100))
+	if fee <= 0 {
+		return 0, nil
+	}
+
+	var applied int
+	seeded := len(tagihan.Items) > 0
+	for seq := tagihan.LateFees + 1; seq <= due; seq++ {
+		item := models.TagihanItem{
+			Kind:        models.TagihanItemLateFee,
+			Description: fmt.Sprintf("Denda keterlambatan %d", seq),
+			Amount:      fromCents(fee),
+			CreatedAt:   primitive.NewDateTimeFromTime(now),
+		}
+		items := []models.TagihanItem{item}
+		if !seeded {
+			// Tagihan lama belum punya rincian; seluruh nominalnya adalah biaya kursus
+			items = []models.TagihanItem{courseItem(tagihan.CourseName, tagihan.Amount, tagihan.CreatedAt.Time()), item}
+			seeded = true
+		}
+
+		err := s.tagihan.AddLateFee(ctx, tagihan.ID, repository.TagihanLateFee{Seq: seq, Amount: fromCents(fee), Items: items, At: now})
+		if errors.Is(err, repository.ErrConflict) {
+			// Sudah dibayar atau denda ini sudah dikenakan proses lain
+			return applied, nil
+		}
+		if err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// daysLate menghitung hari penuh sejak jatuh tempo
+func daysLate(dueDate, now time.Time) int {
+	if !now.After(dueDate) {
+		return 0
+	}
+	return int(now.Sub(dueDate) / (24 * time.Hour))
+}
+
+// AgingBucket adalah satu kelompok umur piutang di laporan tagihan
+type AgingBucket struct {
+	Label       string  `json:"label"`
+	MinDays     int     `json:"min_days"`
+	MaxDays     int     `json:"max_days,omitempty"` // 0 berarti tidak ada batas atas
+	Count       int     `json:"count"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// agingBuckets adalah batas kelompok umur piutang, dalam hari setelah jatuh tempo
+var agingBuckets = []AgingBucket{
+	{Label: "0-30", MinDays: 0, MaxDays: 30},
+	{Label: "31-60", MinDays: 31, MaxDays: 60},
+	{Label: "61-90", MinDays: 61, MaxDays: 90},
+	{Label: "90+", MinDays: 91},
+}
+
+// Aging mengelompokkan sisa tagihan yang sudah lewat jatuh tempo per umur
+// keterlambatan pada waktu asOf. Tagihan yang lunas atau belum jatuh tempo
+// tidak dihitung.
+func Aging(tagihans []models.Tagihan, asOf time.Time) []AgingBucket {
+	buckets := make([]AgingBucket, len(agingBuckets))
+	copy(buckets, agingBuckets)
+	totals := make([]int64, len(buckets))
+
+	for _, t := range tagihans {
+		if t.Outstanding <= 0 || !t.DueDate.Time().Before(asOf) {
+			continue
+		}
+		days := daysLate(t.DueDate.Time(), asOf)
+		for i := range buckets {
+			if days >= buckets[i].MinDays && (buckets[i].MaxDays == 0 || days <= buckets[i].MaxDays) {
+				buckets[i].Count++
+				totals[i] += toCents(t.Outstanding)
+				break
+			}
+		}
+	}
+	for i := range buckets {
+		buckets[i].Outstanding = fromCents(totals[i])
+	}
+	return buckets
+}
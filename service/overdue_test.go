@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFeesDue(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   LateFeePolicy
+		daysLate int
+		want     int
+	}{
+		{name: "no fee configured", policy: LateFeePolicy{GraceDays: 0}, daysLate: 100, want: 0},
+		{name: "not late", policy: LateFeePolicy{Flat: 5000}, daysLate: 0, want: 0},
+		{name: "one day late without grace", policy: LateFeePolicy{Flat: 5000}, daysLate: 1, want: 1},
+		{name: "last grace day", policy: LateFeePolicy{Flat: 5000, GraceDays: 3}, daysLate: 3, want: 0},
+		{name: "first day after grace", policy: LateFeePolicy{Flat: 5000, GraceDays: 3}, daysLate: 4, want: 1},
+		{name: "single fee never repeats", policy: LateFeePolicy{Percent: 2, GraceDays: 3}, daysLate: 365, want: 1},
+		{name: "before first repeat", policy: LateFeePolicy{Flat: 5000, GraceDays: 3, RepeatDays: 7}, daysLate: 10, want: 1},
+		{name: "first repeat", policy: LateFeePolicy{Flat: 5000, GraceDays: 3, RepeatDays: 7}, daysLate: 11, want: 2},
+		{name: "second repeat", policy: LateFeePolicy{Flat: 5000, GraceDays: 3, RepeatDays: 7}, daysLate: 18, want: 3},
+		{name: "capped", policy: LateFeePolicy{Flat: 5000, GraceDays: 3, RepeatDays: 7, MaxFees: 2}, daysLate: 100, want: 2},
+		{name: "below cap", policy: LateFeePolicy{Flat: 5000, GraceDays: 3, RepeatDays: 7, MaxFees: 5}, daysLate: 11, want: 2},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.feesDue(tt.daysLate); got != tt.want {
+			t.Errorf("%s: feesDue(%d) = %d, want %d", tt.name, tt.daysLate, got, tt.want)
+		}
+	}
+}
+
+func TestDaysLate(t *testing.T) {
+	due := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{now: due.Add(-time.Hour), want: 0},
+		{now: due, want: 0},
+		{now: due.Add(23 * time.Hour), want: 0},
+		{now: due.Add(24 * time.Hour), want: 1},
+		{now: due.AddDate(0, 0, 30).Add(time.Hour), want: 30},
+	}
+	for _, tt := range tests {
+		if got := daysLate(due, tt.now); got != tt.want {
+			t.Errorf("daysLate(%s) = %d, want %d", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestAgingBucketEdges(t *testing.T) {
+	asOf := time.Date(2025, time.June, 30, 8, 0, 0, 0, time.UTC)
+	tagihanDue := func(due time.Time, outstanding float64) models.Tagihan {
+		return models.Tagihan{ID: primitive.NewObjectID(), DueDate: primitive.NewDateTimeFromTime(due), Outstanding: outstanding}
+	}
+
+	tests := []struct {
+		name    string
+		tagihan models.Tagihan
+		want    string // Label kelompok; kosong berarti tidak dihitung
+	}{
+		{name: "due an hour ago", tagihan: tagihanDue(asOf.Add(-time.Hour), 10), want: "0-30"},
+		{name: "30 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -30), 10), want: "0-30"},
+		{name: "31 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -31), 10), want: "31-60"},
+		{name: "60 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -60), 10), want: "31-60"},
+		{name: "61 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -61), 10), want: "61-90"},
+		{name: "90 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -90), 10), want: "61-90"},
+		{name: "91 days late", tagihan: tagihanDue(asOf.AddDate(0, 0, -91), 10), want: "90+"},
+		{name: "a year late", tagihan: tagihanDue(asOf.AddDate(-1, 0, 0), 10), want: "90+"},
+		{name: "due now", tagihan: tagihanDue(asOf, 10)},
+		{name: "not yet due", tagihan: tagihanDue(asOf.AddDate(0, 0, 1), 10)},
+		{name: "paid", tagihan: tagihanDue(asOf.AddDate(0, 0, -45), 0)},
+		{name: "overpaid", tagihan: tagihanDue(asOf.AddDate(0, 0, -45), -5)},
+	}
+
+	for _, tt := range tests {
+		buckets := Aging([]models.Tagihan{tt.tagihan}, asOf)
+		if len(buckets) != len(agingBuckets) {
+			t.Fatalf("%s: %d buckets, want %d", tt.name, len(buckets), len(agingBuckets))
+		}
+		for _, bucket := range buckets {
+			wantCount, wantOutstanding := 0, 0.0
+			if bucket.Label == tt.want {
+				wantCount, wantOutstanding = 1, tt.tagihan.Outstanding
+			}
+			if bucket.Count != wantCount || bucket.Outstanding != wantOutstanding {
+				t.Errorf("%s: bucket %s = %d/%v, want %d/%v", tt.name, bucket.Label, bucket.Count, bucket.Outstanding, wantCount, wantOutstanding)
+			}
+		}
+	}
+}
+
+func TestAgingSumsInCents(t *testing.T) {
+	asOf := time.Date(2025, time.June, 30, 8, 0, 0, 0, time.UTC)
+	due := primitive.NewDateTimeFromTime(asOf.AddDate(0, 0, -10))
+	tagihans := []models.Tagihan{
+		{DueDate: due, Outstanding: 0.1},
+		{DueDate: due, Outstanding: 0.2},
+		{DueDate: due, Outstanding: 0.3},
+	}
+
+	buckets := Aging(tagihans, asOf)
+	if buckets[0].Count != 3 || buckets[0].Outstanding != 0.6 {
+		t.Errorf("bucket %s = %d/%v, want 3/0.6", buckets[0].Label, buckets[0].Count, buckets[0].Outstanding)
+	}
+	// Hasil Aging tidak boleh mengubah batas kelompok bawaan
+	if agingBuckets[0].Count != 0 {
+		t.Errorf("agingBuckets was modified: %+v", agingBuckets[0])
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PaymentInput adalah data satu entri payment atau refund
+type PaymentInput struct {
+	Amount     float64 // Untuk RecordPayment, 0 berarti melunasi sisa tagihan
+	Method     string
+	Reference  string
+	Notes      string
+	ReceivedBy *primitive.ObjectID
+	ReceivedAt time.Time // Kosong berarti sekarang
+}
+
+// Ledger mengembalikan tagihan beserta semua entri ledger pembayarannya
+func (s *BillingService) Ledger(ctx context.Context, tagihanID primitive.ObjectID) (models.Tagihan, []models.Payment, error) {
+	tagihan, err := s.tagihan.Get(ctx, tagihanID)
+	if err != nil {
+		return models.Tagihan{}, nil, notFound(err, "Tagihan")
+	}
+	entries, err := s.payments.ListByTagihan(ctx, tagihanID)
+	if err != nil {
+		return models.Tagihan{}, nil, err
+	}
+	return tagihan, entries, nil
+}
+
+// RecordPayment mencatat uang yang diterima untuk tagihan. Pembayaran boleh
+// sebagian; pembayaran yang melebihi sisa tagihan membuat status Lebih Bayar.
+func (s *BillingService) RecordPayment(ctx context.Context, tagihanID primitive.ObjectID, in PaymentInput) (models.Payment, models.Tagihan, error) {
+	tagihan, entries, err := s.Ledger(ctx, tagihanID)
+	if err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+
+	if in.Amount == 0 {
+		outstanding := toCents(tagihan.Amount) - netPaid(entries)
+		if outstanding <= 0 {
+			return models.Payment{}, models.Tagihan{}, ErrTagihanAlreadyPaid
+		}
+		in.Amount = fromCents(outstanding)
+	}
+
+	payment, err := s.newEntry(tagihanID, models.PaymentKindPayment, in, entries)
+	if err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+	if err := s.appendEntry(ctx, &payment); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return models.Payment{}, models.Tagihan{}, ErrPaymentReference
+		}
+		return models.Payment{}, models.Tagihan{}, err
+	}
+
+	tagihan, err = s.rebalance(ctx, tagihan)
+	return payment, tagihan, err
+}
+
+// RefundPayment mencatat uang yang dikembalikan ke siswa. Refund tidak boleh
+// melebihi total yang sudah dibayar.
+func (s *BillingService) RefundPayment(ctx context.Context, tagihanID primitive.ObjectID, in PaymentInput) (models.Payment, models.Tagihan, error) {
+	tagihan, entries, err := s.Ledger(ctx, tagihanID)
+	if err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+	if toCents(in.Amount) > netPaid(entries) {
+		return models.Payment{}, models.Tagihan{}, &ValidationError{Field: "amount", Message: "Refund exceeds the amount paid"}
+	}
+
+	refund, err := s.newEntry(tagihanID, models.PaymentKindRefund, in, entries)
+	if err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+	if err := s.appendEntry(ctx, &refund); err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+
+	tagihan, err = s.rebalance(ctx, tagihan)
+	return refund, tagihan, err
+}
+
+// VoidEntry membatalkan payment atau refund yang salah catat dengan entri void.
+// Entri aslinya tetap ada di ledger.
+func (s *BillingService) VoidEntry(ctx context.Context, tagihanID, entryID primitive.ObjectID, notes string, by *primitive.ObjectID) (models.Payment, models.Tagihan, error) {
+	tagihan, entries, err := s.Ledger(ctx, tagihanID)
+	if err != nil {
+		return models.Payment{}, models.Tagihan{}, err
+	}
+
+	var target *models.Payment
+	for i := range entries {
+		if entries[i].ID == entryID {
+			target = &entries[i]
+		}
+		if entries[i].VoidOf != nil && *entries[i].VoidOf == entryID {
+			return models.Payment{}, models.Tagihan{}, ErrEntryAlreadyVoided
+		}
+	}
+	if target == nil {
+		return models.Payment{}, models.Tagihan{}, &NotFoundError{Entity: "Payment"}
+	}
+	if target.Kind == models.PaymentKindVoid {
+		return models.Payment{}, models.Tagihan{}, &ValidationError{Field: "payment_id", Message: "A void entry cannot be voided"}
+	}
+
+	// Membatalkan payment yang sudah di-refund akan membuat total bayar negatif
+	if target.Kind == models.PaymentKindPayment && toCents(target.Amount) > netPaid(entries) {
+		return models.Payment{}, models.Tagihan{}, &ValidationError{Field: "payment_id", Message: "Void the refunds of this tagihan first"}
+	}
+
+	now := s.now()
+	void := models.Payment{
+		ID:         primitive.NewObjectID(),
+		TagihanID:  tagihanID,
+		Kind:       models.PaymentKindVoid,
+		Amount:     target.Amount,
+		Notes:      notes,
+		Seq:        len(entries) + 1,
+		VoidOf:     &target.ID,
+		ReceivedBy: by,
+		ReceivedAt: primitive.NewDateTimeFromTime(now),
+		CreatedAt:  primitive.NewDateTimeFromTime(now),
+	}
+	if err := s.appendEntry(ctx, &void); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return models.Payment{}, models.Tagihan{}, ErrEntryAlreadyVoided
+		}
+		return models.Payment{}, models.Tagihan{}, err
+	}
+
+	tagihan, err = s.rebalance(ctx, tagihan)
+	return void, tagihan, err
+}
+
+// appendEntry menyimpan entri sebagai entri berikutnya di ledger. Seq entri
+// diisi dari jumlah entri yang dibaca saat saldo dicek, sehingga jika request
+// lain sudah menambah entri sejak itu, penyimpanan ditolak dan batas refund
+// atau sisa tagihan tidak bisa terlewati.
+func (s *BillingService) appendEntry(ctx context.Context, entry *models.Payment) error {
+	err := s.payments.Create(ctx, entry)
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrLedgerChanged
+	}
+	return err
+}
+
+// newEntry menyiapkan entri payment atau refund baru setelah entries
+func (s *BillingService) newEntry(tagihanID primitive.ObjectID, kind string, in PaymentInput, entries []models.Payment) (models.Payment, error) {
+	if toCents(in.Amount) <= 0 {
+		return models.Payment{}, &ValidationError{Field: "amount", Message: "Amount must be greater than zero"}
+	}
+	now := s.now()
+	receivedAt := in.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = now
+	}
+	if receivedAt.After(now) {
+		return models.Payment{}, &ValidationError{Field: "received_at", Message: "Received date cannot be in the future"}
+	}
+	return models.Payment{
+		ID:         primitive.NewObjectID(),
+		TagihanID:  tagihanID,
+		Kind:       kind,
+		Seq:        len(entries) + 1,
+		Amount:     in.Amount,
+		Method:     in.Method,
+		Reference:  in.Reference,
+		Notes:      in.Notes,
+		ReceivedBy: in.ReceivedBy,
+		ReceivedAt: primitive.NewDateTimeFromTime(receivedAt),
+		CreatedAt:  primitive.NewDateTimeFromTime(now),
+	}, nil
+}
+
+// rebalanceAttempts membatasi pengulangan rebalance saat amount tagihan
+// berubah di tengah jalan (misalnya denda keterlambatan ditambahkan)
+const rebalanceAttempts = 3
+
+// rebalance menghitung ulang saldo tagihan dari seluruh ledger lalu
+// menyimpannya. Ledger dibaca ulang agar entri dari request lain ikut terhitung,
+// dan hasilnya adalah tagihan yang benar-benar tersimpan.
+func (s *BillingService) rebalance(ctx context.Context, tagihan models.Tagihan) (models.Tagihan, error) {
+	for attempt := 0; ; attempt++ {
+		entries, err := s.payments.ListByTagihan(ctx, tagihan.ID)
+		if err != nil {
+			return models.Tagihan{}, err
+		}
+
+		now := s.now()
+		paid := netPaid(entries)
+		amount := toCents(tagihan.Amount)
+		balance := repository.TagihanBalance{
+			Amount:      tagihan.Amount,
+			PaidAmount:  fromCents(paid),
+			Outstanding: fromCents(amount - paid),
+			Status:      tagihanStatus(amount, paid),
+			LedgerCount: len(entries),
+			UpdatedAt:   now,
+		}
+		// Tagihan yang belum lunas dan sudah lewat jatuh tempo tetap terlambat
+		if (balance.Status == models.TagihanStatusUnpaid || balance.Status == models.TagihanStatusPartial) && tagihan.DueDate.Time().Before(now) {
+			balance.Status = models.TagihanStatusOverdue
+		}
+		// Tanggal lunas tidak berubah selama tagihan tetap lunas
+		if balance.Status == models.TagihanStatusPaid || balance.Status == models.TagihanStatusOverpaid {
+			paidAt := now
+			if tagihan.PaidAt != nil {
+				paidAt = tagihan.PaidAt.Time()
+			}
+			balance.PaidAt = &paidAt
+		}
+
+		if err := s.tagihan.SetBalance(ctx, tagihan.ID, balance); err != nil {
+			return models.Tagihan{}, err
+		}
+
+		latest, err := s.tagihan.Get(ctx, tagihan.ID)
+		if err != nil {
+			return models.Tagihan{}, notFound(err, "Tagihan")
+		}
+		// Amount berubah sebelum saldo tersimpan; hitung ulang dengan amount terbaru
+		if latest.LedgerCount < len(entries) && latest.Amount != tagihan.Amount && attempt+1 < rebalanceAttempts {
+			tagihan = latest
+			continue
+		}
+		return latest, nil
+	}
+}
+
+// netPaid menjumlahkan ledger dalam satuan sen: payment menambah, refund
+// mengurangi, dan void membalik entri yang dibatalkannya.
+func netPaid(entries []models.Payment) int64 {
+	signs := make(map[primitive.ObjectID]int64, len(entries))
+	for _, e := range entries {
+		switch e.Kind {
+		case models.PaymentKindPayment:
+			signs[e.ID] = 1
+		case models.PaymentKindRefund:
+			signs[e.ID] = -1
+		}
+	}
+
+	var total int64
+	for _, e := range entries {
+		switch e.Kind {
+		case models.PaymentKindVoid:
+			if e.VoidOf != nil {
+				total -= signs[*e.VoidOf] * toCents(e.Amount)
+			}
+		default:
+			total += signs[e.ID] * toCents(e.Amount)
+		}
+	}
+	return total
+}
+
+// tagihanStatus menurunkan status tagihan dari nominal dan total bayar (sen)
+func tagihanStatus(amount, paid int64) string {
+	switch {
+	case paid == amount:
+		return models.TagihanStatusPaid
+	case paid <= 0:
+		return models.TagihanStatusUnpaid
+	case paid < amount:
+		return models.TagihanStatusPartial
+	default:
+		return models.TagihanStatusOverpaid
+	}
+}
+
+// toCents dan fromCents menghindari galat pembulatan float saat menjumlah uang
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testClock adalah waktu palsu untuk BillingService di pengujian
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+// newTestBilling membuat BillingService di atas repository in-memory beserta
+// satu siswa dan satu kursus seharga 100
+func newTestBilling(t *testing.T, plan models.BillingPlan, duration int) (*BillingService, *repository.Repositories, *testClock, models.Siswa, models.Course) {
+	t.Helper()
+	ctx := context.Background()
+	repos := repository.NewMemoryRepositories()
+
+	siswa := models.Siswa{FullName: "Budi", Email: "budi@example.com"}
+	if err := repos.Siswa.Create(ctx, &siswa); err != nil {
+		t.Fatalf("create siswa: %v", err)
+	}
+	course := models.Course{Name: "Piano", Cost: 100, Duration: duration, BillingPlan: plan}
+	if err := repos.Course.Create(ctx, &course); err != nil {
+		t.Fatalf("create course: %v", err)
+	}
+
+	clock := &testClock{now: time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC)}
+	svc := NewBillingService(repos)
+	svc.now = clock.Now
+	return svc, repos, clock, siswa, course
+}
+
+// ledgerStep adalah satu operasi ledger. void berisi indeks entri (urutan
+// dicatat di langkah sebelumnya) yang dibatalkan.
+type ledgerStep struct {
+	op      string // "pay", "refund" atau "void"
+	amount  float64
+	void    int
+	wantErr string
+}
+
+func TestLedgerStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name            string
+		pastDue         bool
+		steps           []ledgerStep
+		wantStatus      string
+		wantPaid        float64
+		wantOutstanding float64
+	}{
+		{
+			name:            "partial payment",
+			steps:           []ledgerStep{{op: "pay", amount: 40}},
+			wantStatus:      models.TagihanStatusPartial,
+			wantPaid:        40,
+			wantOutstanding: 60,
+		},
+		{
+			name:            "partial payments add up to paid",
+			steps:           []ledgerStep{{op: "pay", amount: 40}, {op: "pay", amount: 60}},
+			wantStatus:      models.TagihanStatusPaid,
+			wantPaid:        100,
+			wantOutstanding: 0,
+		},
+		{
+			name:            "zero amount pays the outstanding balance",
+			steps:           []ledgerStep{{op: "pay", amount: 25.5}, {op: "pay", amount: 0}},
+			wantStatus:      models.TagihanStatusPaid,
+			wantPaid:        100,
+			wantOutstanding: 0,
+		},
+		{
+			name:            "zero amount on a paid tagihan",
+			steps:           []ledgerStep{{op: "pay", amount: 100}, {op: "pay", amount: 0, wantErr: ErrTagihanAlreadyPaid.Error()}},
+			wantStatus:      models.TagihanStatusPaid,
+			wantPaid:        100,
+			wantOutstanding: 0,
+		},
+		{
+			name:            "overpay",
+			steps:           []ledgerStep{{op: "pay", amount: 150}},
+			wantStatus:      models.TagihanStatusOverpaid,
+			wantPaid:        150,
+			wantOutstanding: -50,
+		},
+		{
+			name:            "refund of an overpayment",
+			steps:           []ledgerStep{{op: "pay", amount: 150}, {op: "refund", amount: 50}},
+			wantStatus:      models.TagihanStatusPaid,
+			wantPaid:        100,
+			wantOutstanding: 0,
+		},
+		{
+			name:            "partial refund",
+			steps:           []ledgerStep{{op: "pay", amount: 100}, {op: "refund", amount: 30}},
+			wantStatus:      models.TagihanStatusPartial,
+			wantPaid:        70,
+			wantOutstanding: 30,
+		},
+		{
+			name:            "full refund",
+			steps:           []ledgerStep{{op: "pay", amount: 100}, {op: "refund", amount: 100}},
+			wantStatus:      models.TagihanStatusUnpaid,
+			wantPaid:        0,
+			wantOutstanding: 100,
+		},
+		{
+			name:            "refund above the amount paid",
+			steps:           []ledgerStep{{op: "pay", amount: 40}, {op: "refund", amount: 40.01, wantErr: "Refund exceeds the amount paid"}},
+			wantStatus:      models.TagihanStatusPartial,
+			wantPaid:        40,
+			wantOutstanding: 60,
+		},
+		{
+			name:            "void payment",
+			steps:           []ledgerStep{{op: "pay", amount: 40}, {op: "void", void: 0}},
+			wantStatus:      models.TagihanStatusUnpaid,
+			wantPaid:        0,
+			wantOutstanding: 100,
+		},
+		{
+			name:            "void refund",
+			steps:           []ledgerStep{{op: "pay", amount: 100}, {op: "refund", amount: 30}, {op: "void", void: 1}},
+			wantStatus:      models.TagihanStatusPaid,
+			wantPaid:        100,
+			wantOutstanding: 0,
+		},
+		{
+			name: "void twice",
+			steps: []ledgerStep{
+				{op: "pay", amount: 40},
+				{op: "void", void: 0},
+				{op: "void", void: 0, wantErr: ErrEntryAlreadyVoided.Error()},
+			},
+			wantStatus:      models.TagihanStatusUnpaid,
+			wantPaid:        0,
+			wantOutstanding: 100,
+		},
+		{
+			name: "void a refunded payment",
+			steps: []ledgerStep{
+				{op: "pay", amount: 100},
+				{op: "refund", amount: 30},
+				{op: "void", void: 0, wantErr: "Void the refunds of this tagihan first"},
+			},
+			wantStatus:      models.TagihanStatusPartial,
+			wantPaid:        70,
+			wantOutstanding: 30,
+		},
+		{
+			name:            "partial payment after the due date",
+			pastDue:         true,
+			steps:           []ledgerStep{{op: "pay", amount: 40}},
+			wantStatus:      models.TagihanStatusOverdue,
+			wantPaid:        40,
+			wantOutstanding: 60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, _, clock, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+
+			dueDate := clock.now.AddDate(0, 0, 7)
+			if tt.pastDue {
+				dueDate = clock.now.AddDate(0, 0, -1)
+			}
+			tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID, DueDate: dueDate})
+			if err != nil {
+				t.Fatalf("CreateTagihan: %v", err)
+			}
+
+			var entries []models.Payment
+			for i, step := range tt.steps {
+				var entry models.Payment
+				var updated models.Tagihan
+				switch step.op {
+				case "pay":
+					entry, updated, err = svc.RecordPayment(ctx, tagihan.ID, PaymentInput{Amount: step.amount, Method: models.PaymentMethodCash})
+				case "refund":
+					entry, updated, err = svc.RefundPayment(ctx, tagihan.ID, PaymentInput{Amount: step.amount, Method: models.PaymentMethodCash})
+				case "void":
+					entry, updated, err = svc.VoidEntry(ctx, tagihan.ID, entries[step.void].ID, "", nil)
+				}
+				if step.wantErr != "" {
+					if err == nil || err.Error() != step.wantErr {
+						t.Fatalf("step %d (%s): error = %v, want %q", i, step.op, err, step.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d (%s): %v", i, step.op, err)
+				}
+				tagihan = updated
+				entries = append(entries, entry)
+			}
+
+			// Saldo tersimpan harus sama dengan hasil langkah terakhir
+			stored, _, err := svc.Ledger(ctx, tagihan.ID)
+			if err != nil {
+				t.Fatalf("Ledger: %v", err)
+			}
+			if stored.Status != tagihan.Status || stored.Outstanding != tagihan.Outstanding {
+				t.Errorf("stored tagihan = %q/%v, returned %q/%v", stored.Status, stored.Outstanding, tagihan.Status, tagihan.Outstanding)
+			}
+
+			if tagihan.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", tagihan.Status, tt.wantStatus)
+			}
+			if tagihan.PaidAmount != tt.wantPaid {
+				t.Errorf("paid amount = %v, want %v", tagihan.PaidAmount, tt.wantPaid)
+			}
+			if tagihan.Outstanding != tt.wantOutstanding {
+				t.Errorf("outstanding = %v, want %v", tagihan.Outstanding, tt.wantOutstanding)
+			}
+			if wantPaid := tt.wantStatus == models.TagihanStatusPaid || tt.wantStatus == models.TagihanStatusOverpaid; tagihan.Paid != wantPaid {
+				t.Errorf("paid = %v, want %v", tagihan.Paid, wantPaid)
+			}
+			if tagihan.LedgerCount != len(entries) {
+				t.Errorf("ledger count = %d, want %d", tagihan.LedgerCount, len(entries))
+			}
+		})
+	}
+}
+
+func TestAppendEntryRejectsStaleLedger(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, siswa, course := newTestBilling(t, models.BillingPlan{}, 1)
+	tagihan, err := svc.CreateTagihan(ctx, CreateTagihanInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("CreateTagihan: %v", err)
+	}
+	if _, _, err := svc.RecordPayment(ctx, tagihan.ID, PaymentInput{Amount: 100, Method: models.PaymentMethodCash}); err != nil {
+		t.Fatalf("RecordPayment: %v", err)
+	}
+
+	// Dua refund yang membaca ledger yang sama; yang kedua harus ditolak
+	_, entries, err := svc.Ledger(ctx, tagihan.ID)
+	if err != nil {
+		t.Fatalf("Ledger: %v", err)
+	}
+	for i, want := range []error{nil, ErrLedgerChanged} {
+		refund, err := svc.newEntry(tagihan.ID, models.PaymentKindRefund, PaymentInput{Amount: 100, Method: models.PaymentMethodCash}, entries)
+		if err != nil {
+			t.Fatalf("newEntry: %v", err)
+		}
+		if err := svc.appendEntry(ctx, &refund); !errors.Is(err, want) {
+			t.Fatalf("refund %d: error = %v, want %v", i, err, want)
+		}
+	}
+
+	_, entries, err = svc.Ledger(ctx, tagihan.ID)
+	if err != nil {
+		t.Fatalf("Ledger: %v", err)
+	}
+	if got := netPaid(entries); got != 0 {
+		t.Errorf("net paid = %d cents, want 0", got)
+	}
+}
+
+func TestTagihanStatus(t *testing.T) {
+	tests := []struct {
+		amount, paid int64
+		want         string
+	}{
+		{amount: 10000, paid: 0, want: models.TagihanStatusUnpaid},
+		{amount: 10000, paid: -1, want: models.TagihanStatusUnpaid},
+		{amount: 10000, paid: 1, want: models.TagihanStatusPartial},
+		{amount: 10000, paid: 9999, want: models.TagihanStatusPartial},
+		{amount: 10000, paid: 10000, want: models.TagihanStatusPaid},
+		{amount: 10000, paid: 10001, want: models.TagihanStatusOverpaid},
+		{amount: 0, paid: 0, want: models.TagihanStatusPaid},
+	}
+	for _, tt := range tests {
+		if got := tagihanStatus(tt.amount, tt.paid); got != tt.want {
+			t.Errorf("tagihanStatus(%d, %d) = %q, want %q", tt.amount, tt.paid, got, tt.want)
+		}
+	}
+}
+
+func TestNetPaid(t *testing.T) {
+	payment := models.Payment{ID: primitive.NewObjectID(), Kind: models.PaymentKindPayment, Amount: 0.1}
+	refund := models.Payment{ID: primitive.NewObjectID(), Kind: models.PaymentKindRefund, Amount: 0.05}
+	voidPayment := models.Payment{ID: primitive.NewObjectID(), Kind: models.PaymentKindVoid, Amount: 0.1, VoidOf: &payment.ID}
+	voidRefund := models.Payment{ID: primitive.NewObjectID(), Kind: models.PaymentKindVoid, Amount: 0.05, VoidOf: &refund.ID}
+
+	tests := []struct {
+		name    string
+		entries []models.Payment
+		want    int64
+	}{
+		{name: "empty", want: 0},
+		{name: "payment", entries: []models.Payment{payment}, want: 10},
+		{name: "payment and refund", entries: []models.Payment{payment, refund}, want: 5},
+		{name: "voided payment", entries: []models.Payment{payment, voidPayment}, want: 0},
+		{name: "voided refund", entries: []models.Payment{payment, refund, voidRefund}, want: 10},
+	}
+	for _, tt := range tests {
+		if got := netPaid(tt.entries); got != tt.want {
+			t.Errorf("%s: netPaid = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
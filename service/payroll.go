@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// payoutTimeLayout adalah format created_at transaksi guru
+const payoutTimeLayout = "02-01-2006 15:04:05 WIB"
+
+// PayrollService mengatur pembayaran gaji guru
+type PayrollService struct {
+	transaksi repository.TransaksiGuruRepository
+	guru      repository.GuruRepository
+	location  *time.Location
+	now       func() time.Time
+}
+
+// NewPayrollService membuat PayrollService; loc menentukan zona waktu periode gaji
+func NewPayrollService(repos *repository.Repositories, loc *time.Location) *PayrollService {
+	if loc == nil {
+		loc, _ = time.LoadLocation("Asia/Jakarta")
+	}
+	return &PayrollService{
+		transaksi: repos.TransaksiGuru,
+		guru:      repos.Guru,
+		location:  loc,
+		now:       time.Now,
+	}
+}
+
+// CreatePayout mencatat gaji guru. Setiap guru hanya boleh dibayar sekali per bulan.
+func (s *PayrollService) CreatePayout(ctx context.Context, guruID primitive.ObjectID, amount float64, notes string) (models.TransaksiGuru, error) {
+	now := s.now().In(s.location)
+
+	existing, err := s.transaksi.List(ctx, repository.TransaksiGuruFilter{
+		GuruID: &guruID,
+		Period: now.Format("01-2006"), // Cari transaksi dalam bulan & tahun yang sama
+	})
+	if err != nil {
+		return models.TransaksiGuru{}, err
+	}
+	if len(existing) > 0 {
+		return models.TransaksiGuru{}, ErrPayoutExists
+	}
+
+	guru, err := s.guru.Get(ctx, guruID)
+	if err != nil {
+		return models.TransaksiGuru{}, notFound(err, "Guru")
+	}
+
+	transaksi := models.TransaksiGuru{
+		ID:        primitive.NewObjectID(),
+		GuruID:    guruID,
+		GuruName:  guru.FullName,
+		Amount:    amount,
+		CreatedAt: now.Format(payoutTimeLayout),
+		Notes:     notes,
+	}
+	if err := s.transaksi.Create(ctx, &transaksi); err != nil {
+		return models.TransaksiGuru{}, err
+	}
+	return transaksi, nil
+}
+
+// MonthlyReport mengambil semua pembayaran gaji pada bulan tertentu
+func (s *PayrollService) MonthlyReport(ctx context.Context, year int, month time.Month) ([]models.TransaksiGuru, error) {
+	period := time.Date(year, month, 1, 0, 0, 0, 0, s.location).Format("01-2006")
+	return s.transaksi.List(ctx, repository.TransaksiGuruFilter{Period: period})
+}
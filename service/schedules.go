@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EnrollInput adalah data pendaftaran siswa ke kursus berbayar
+type EnrollInput struct {
+	SiswaID   primitive.ObjectID
+	CourseID  primitive.ObjectID
+	StartDate time.Time // Tanggal siklus pertama; kosong berarti sekarang
+}
+
+// Enroll membuat seluruh jadwal tagihan siswa untuk sebuah kursus sesuai
+// rencana penagihan kursus, lalu langsung menerbitkan tagihan siklus yang
+// sudah jatuh waktu terbit (biasanya siklus pertama).
+func (s *BillingService) Enroll(ctx context.Context, in EnrollInput) (models.BillingSchedule, []models.Tagihan, error) {
+	siswa, err := s.siswa.Get(ctx, in.SiswaID)
+	if err != nil {
+		return models.BillingSchedule{}, nil, notFound(err, "Siswa")
+	}
+	course, err := s.course.Get(ctx, in.CourseID)
+	if err != nil {
+		return models.BillingSchedule{}, nil, notFound(err, "Course")
+	}
+
+	active, err := s.schedules.List(ctx, repository.BillingScheduleFilter{
+		SiswaID:  &siswa.ID,
+		CourseID: &course.ID,
+		Status:   models.BillingScheduleActive,
+	})
+	if err != nil {
+		return models.BillingSchedule{}, nil, err
+	}
+	if len(active) > 0 {
+		return models.BillingSchedule{}, nil, ErrAlreadyEnrolled
+	}
+
+	now := s.now()
+	start := in.StartDate
+	if start.IsZero() {
+		start = now
+	}
+	cycles, err := planCycles(course, start)
+	if err != nil {
+		return models.BillingSchedule{}, nil, err
+	}
+
+	var total int64
+	for _, cycle := range cycles {
+		total += toCents(cycle.Amount)
+	}
+	schedule := models.BillingSchedule{
+		ID:          primitive.NewObjectID(),
+		SiswaID:     siswa.ID,
+		CourseID:    course.ID,
+		Plan:        course.BillingPlan,
+		Total:       fromCents(total),
+		Cycles:      cycles,
+		Status:      models.BillingScheduleActive,
+		NextIssueAt: &cycles[0].IssueDate,
+		CreatedAt:   primitive.NewDateTimeFromTime(now),
+		UpdatedAt:   primitive.NewDateTimeFromTime(now),
+	}
+	if schedule.Plan.Type == "" {
+		schedule.Plan.Type = models.BillingPlanOneTime
+	}
+	if err := s.schedules.Create(ctx, &schedule); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return models.BillingSchedule{}, nil, ErrAlreadyEnrolled
+		}
+		return models.BillingSchedule{}, nil, err
+	}
+
+	issued, err := s.issueCycles(ctx, &schedule, siswa, course, now)
+	return schedule, issued, err
+}
+
+// Schedule mengembalikan satu jadwal tagihan
+func (s *BillingService) Schedule(ctx context.Context, id primitive.ObjectID) (models.BillingSchedule, error) {
+	schedule, err := s.schedules.Get(ctx, id)
+	if err != nil {
+		return models.BillingSchedule{}, notFound(err, "Billing schedule")
+	}
+	return schedule, nil
+}
+
+// CancelSchedule menghentikan jadwal tagihan. Tagihan yang sudah terbit tidak
+// berubah; siklus yang belum terbit tidak akan ditagihkan.
+func (s *BillingService) CancelSchedule(ctx context.Context, id primitive.ObjectID) (models.BillingSchedule, error) {
+	schedule, err := s.Schedule(ctx, id)
+	if err != nil {
+		return models.BillingSchedule{}, err
+	}
+	if schedule.Status != models.BillingScheduleActive {
+		return models.BillingSchedule{}, ErrScheduleNotActive
+	}
+
+	now := s.now()
+	err = s.schedules.SetProgress(ctx, id, models.BillingScheduleCancelled, nil, now)
+	if errors.Is(err, repository.ErrConflict) {
+		// Selesai atau dibatalkan proses lain setelah dibaca
+		return models.BillingSchedule{}, ErrScheduleNotActive
+	}
+	if err != nil {
+		return models.BillingSchedule{}, notFound(err, "Billing schedule")
+	}
+	schedule.Status = models.BillingScheduleCancelled
+	schedule.NextIssueAt = nil
+	schedule.UpdatedAt = primitive.NewDateTimeFromTime(now)
+	return schedule, nil
+}
+
+// IssueDueTagihan menerbitkan tagihan untuk semua siklus yang tanggal terbitnya
+// sudah tiba. Dipanggil scheduler secara berkala; aman dijalankan bersamaan di
+// beberapa instance karena index unik (schedule_id, cycle) hanya mengizinkan
+// satu tagihan per siklus. Semua jadwal tetap diproses walaupun ada yang gagal.
+func (s *BillingService) IssueDueTagihan(ctx context.Context) error {
+	now := s.now()
+	schedules, err := s.schedules.Due(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	var issued int
+	for i := range schedules {
+		schedule := &schedules[i]
+		siswa, errSiswa := s.siswa.Get(ctx, schedule.SiswaID)
+		course, errCourse := s.course.Get(ctx, schedule.CourseID)
+		if errors.Is(errSiswa, repository.ErrNotFound) || errors.Is(errCourse, repository.ErrNotFound) {
+			// Siswa atau kursus sudah dihapus; siklus berikutnya tidak lagi ditagihkan
+			log.Printf("Cancelling billing schedule %s: siswa or course no longer exists", schedule.ID.Hex())
+			err := s.schedules.SetProgress(ctx, schedule.ID, models.BillingScheduleCancelled, nil, now)
+			if err != nil && !errors.Is(err, repository.ErrConflict) && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := errors.Join(errSiswa, errCourse); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		tagihans, err := s.issueCycles(ctx, schedule, siswa, course, now)
+		issued += len(tagihans)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if issued > 0 {
+		log.Printf("Issued %d scheduled tagihan", issued)
+	}
+	return firstErr
+}
+
+// issueCycles menerbitkan tagihan untuk siklus yang belum terbit dan sudah
+// tiba tanggal terbitnya, lalu memperbarui status dan NextIssueAt jadwal.
+// Tagihan disimpan lebih dulu baru siklusnya diklaim, sehingga proses yang
+// berhenti di antaranya tidak meninggalkan siklus yang diklaim tanpa tagihan;
+// tagihan yang sudah ada diklaim pada putaran berikutnya. Jika jadwal
+// dibatalkan di tengah jalan, penerbitan berhenti tanpa error.
+func (s *BillingService) issueCycles(ctx context.Context, schedule *models.BillingSchedule, siswa models.Siswa, course models.Course, now time.Time) ([]models.Tagihan, error) {
+	issued := []models.Tagihan{}
+	due := primitive.NewDateTimeFromTime(now)
+	for i := range schedule.Cycles {
+		cycle := &schedule.Cycles[i]
+		if cycle.TagihanID != nil || cycle.IssueDate > due {
+			continue
+		}
+
+		tagihan := models.Tagihan{
+			ID:          primitive.NewObjectID(),
+			SiswaID:     siswa.ID,
+			SiswaNama:   siswa.FullName,
+			SiswaEmail:  siswa.Email,
+			CourseID:    course.ID,
+			CourseName:  course.Name,
+			Amount:      cycle.Amount,
+			Items:       []models.TagihanItem{courseItem(cycleDescription(course.Name, *cycle, len(schedule.Cycles)), cycle.Amount, now)},
+			Outstanding: cycle.Amount,
+			DueDate:     cycle.DueDate,
+			Status:      models.TagihanStatusUnpaid,
+			ScheduleID:  &schedule.ID,
+			Cycle:       cycle.Seq,
+			CreatedAt:   due,
+		}
+		created := true
+		err := s.tagihan.Create(ctx, &tagihan)
+		if errors.Is(err, repository.ErrDuplicate) {
+			// Diterbitkan proses lain, atau proses yang berhenti sebelum sempat mengklaim siklusnya
+			created = false
+			tagihan, err = s.tagihan.FindByCycle(ctx, schedule.ID, cycle.Seq)
+		}
+		if err != nil {
+			return issued, err
+		}
+
+		err = s.schedules.ClaimCycle(ctx, schedule.ID, cycle.Seq, tagihan.ID)
+		if errors.Is(err, repository.ErrConflict) {
+			// Sudah diklaim proses lain, atau jadwalnya sudah tidak aktif
+			stopped, err := s.scheduleStopped(ctx, schedule)
+			if stopped && created {
+				// Jadwal dibatalkan sebelum siklus ini terbit; tagihannya ditarik ke trash
+				if err := s.tagihan.Delete(ctx, tagihan.ID, nil); err != nil {
+					log.Printf("Failed to withdraw tagihan %s of cancelled schedule %s: %v", tagihan.ID.Hex(), schedule.ID.Hex(), err)
+				}
+			}
+			if stopped || err != nil {
+				return issued, err
+			}
+			continue
+		}
+		if err != nil {
+			return issued, err
+		}
+		cycle.TagihanID = &tagihan.ID
+		if created {
+			issued = append(issued, tagihan)
+		}
+	}
+
+	// Siklus yang diklaim proses lain juga dihitung sudah terbit lewat data terbaru
+	latest, err := s.schedules.Get(ctx, schedule.ID)
+	if err != nil {
+		return issued, err
+	}
+	if latest.Status != models.BillingScheduleActive {
+		*schedule = latest
+		return issued, nil
+	}
+	status, next := models.BillingScheduleCompleted, (*time.Time)(nil)
+	for _, cycle := range latest.Cycles {
+		if cycle.TagihanID == nil {
+			issueAt := cycle.IssueDate.Time()
+			status, next = models.BillingScheduleActive, &issueAt
+			break
+		}
+	}
+	err = s.schedules.SetProgress(ctx, schedule.ID, status, next, now)
+	if errors.Is(err, repository.ErrConflict) {
+		// Dibatalkan setelah data terbaru dibaca; pembatalan tidak ditimpa
+		_, err = s.scheduleStopped(ctx, schedule)
+		return issued, err
+	}
+	if err != nil {
+		return issued, err
+	}
+
+	latest.Status = status
+	latest.NextIssueAt = nil
+	if next != nil {
+		nextIssueAt := primitive.NewDateTimeFromTime(*next)
+		latest.NextIssueAt = &nextIssueAt
+	}
+	latest.UpdatedAt = due
+	*schedule = latest
+	return issued, nil
+}
+
+// scheduleStopped membaca ulang jadwal dan mengecek apakah jadwal sudah tidak
+// aktif. Jika sudah berhenti, schedule diganti dengan data terbaru.
+func (s *BillingService) scheduleStopped(ctx context.Context, schedule *models.BillingSchedule) (bool, error) {
+	latest, err := s.schedules.Get(ctx, schedule.ID)
+	if err != nil {
+		return false, err
+	}
+	if latest.Status == models.BillingScheduleActive {
+		return false, nil
+	}
+	*schedule = latest
+	return true, nil
+}
+
+// cycleDescription menamai baris tagihan sebuah siklus, contoh "Piano (2/6)"
+func cycleDescription(courseName string, cycle models.BillingCycle, total int) string {
+	if total == 1 {
+		return courseName
+	}
+	return fmt.Sprintf("%s (%d/%d)", courseName, cycle.Seq, total)
+}
+
+// planCycles menyusun siklus tagihan dari rencana penagihan kursus. Course.Cost
+// adalah harga total kursus untuk semua rencana, jadi jumlah semua siklus selalu
+// sama dengan Cost. Siklus ke-n terbit n-1 bulan setelah start dan jatuh tempo
+// DueDays hari kemudian.
+func planCycles(course models.Course, start time.Time) ([]models.BillingCycle, error) {
+	plan := course.BillingPlan
+	cost := toCents(course.Cost)
+
+	var amounts []int64
+	switch plan.Type {
+	case "", models.BillingPlanOneTime:
+		amounts = []int64{cost}
+	case models.BillingPlanMonthly:
+		if course.Duration < 1 {
+			return nil, &ValidationError{Field: "course_id", Message: "Monthly billing needs a course duration of at least one month"}
+		}
+		amounts = splitCents(cost, course.Duration)
+	case models.BillingPlanInstallments:
+		if plan.Installments < 2 {
+			return nil, &ValidationError{Field: "course_id", Message: "Installment billing needs at least two installments"}
+		}
+		amounts = splitCents(cost, plan.Installments)
+	default:
+		return nil, &ValidationError{Field: "course_id", Message: "Unknown billing plan"}
+	}
+
+	dueDays := plan.DueDays
+	if dueDays <= 0 {
+		dueDays = defaultDueDays
+	}
+	cycles := make([]models.BillingCycle, len(amounts))
+	for i, amount := range amounts {
+		issueDate := addMonths(start, i)
+		cycles[i] = models.BillingCycle{
+			Seq:       i + 1,
+			Amount:    fromCents(amount),
+			IssueDate: primitive.NewDateTimeFromTime(issueDate),
+			DueDate:   primitive.NewDateTimeFromTime(issueDate.AddDate(0, 0, dueDays)),
+		}
+	}
+	return cycles, nil
+}
+
+// splitCents membagi total menjadi n bagian yang jumlahnya tetap total. Sisa
+// pembagian dibebankan satu sen per bagian, mulai dari bagian pertama.
+func splitCents(total int64, n int) []int64 {
+	parts := make([]int64, n)
+	for i := range parts {
+		parts[i] = total / int64(n)
+		if int64(i) < total%int64(n) {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// addMonths menambah n bulan tanpa melewati akhir bulan, sehingga siklus yang
+// dimulai 31 Januari terbit 28/29 Februari, bukan awal Maret
+func addMonths(t time.Time, n int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/organisasi/tubesbackend/models"
+	"github.com/organisasi/tubesbackend/repository"
+)
+
+func TestAddMonths(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 30, 0, 0, time.UTC) }
+	tests := []struct {
+		start time.Time
+		n     int
+		want  time.Time
+	}{
+		{start: date(2025, time.January, 15), n: 0, want: date(2025, time.January, 15)},
+		{start: date(2025, time.January, 15), n: 1, want: date(2025, time.February, 15)},
+		{start: date(2025, time.January, 31), n: 1, want: date(2025, time.February, 28)},
+		{start: date(2024, time.January, 31), n: 1, want: date(2024, time.February, 29)},
+		{start: date(2025, time.January, 31), n: 2, want: date(2025, time.March, 31)},
+		{start: date(2025, time.January, 30), n: 1, want: date(2025, time.February, 28)},
+		{start: date(2025, time.August, 31), n: 1, want: date(2025, time.September, 30)},
+		{start: date(2025, time.December, 31), n: 2, want: date(2026, time.February, 28)},
+		{start: date(2025, time.March, 31), n: -1, want: date(2025, time.February, 28)},
+	}
+	for _, tt := range tests {
+		if got := addMonths(tt.start, tt.n); !got.Equal(tt.want) {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.start.Format("2006-01-02"), tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPlanCycles(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		course      models.Course
+		wantAmounts []float64
+		wantIssue   []string // Tanggal terbit tiap siklus, format 2006-01-02
+		wantDueDays int
+		wantErr     bool
+	}{
+		{
+			name:        "one time",
+			course:      models.Course{Cost: 100},
+			wantAmounts: []float64{100},
+			wantIssue:   []string{"2025-01-31"},
+			wantDueDays: defaultDueDays,
+		},
+		{
+			name:        "monthly across month ends",
+			course:      models.Course{Cost: 100, Duration: 4, BillingPlan: models.BillingPlan{Type: models.BillingPlanMonthly, DueDays: 10}},
+			wantAmounts: []float64{25, 25, 25, 25},
+			wantIssue:   []string{"2025-01-31", "2025-02-28", "2025-03-31", "2025-04-30"},
+			wantDueDays: 10,
+		},
+		{
+			name:        "installments spread the remainder",
+			course:      models.Course{Cost: 100, BillingPlan: models.BillingPlan{Type: models.BillingPlanInstallments, Installments: 3}},
+			wantAmounts: []float64{33.34, 33.33, 33.33},
+			wantIssue:   []string{"2025-01-31", "2025-02-28", "2025-03-31"},
+			wantDueDays: defaultDueDays,
+		},
+		{
+			name:        "monthly spreads the remainder",
+			course:      models.Course{Cost: 100, Duration: 3, BillingPlan: models.BillingPlan{Type: models.BillingPlanMonthly}},
+			wantAmounts: []float64{33.34, 33.33, 33.33},
+			wantIssue:   []string{"2025-01-31", "2025-02-28", "2025-03-31"},
+			wantDueDays: defaultDueDays,
+		},
+		{
+			name:    "monthly without duration",
+			course:  models.Course{Cost: 100, BillingPlan: models.BillingPlan{Type: models.BillingPlanMonthly}},
+			wantErr: true,
+		},
+		{
+			name:    "single installment",
+			course:  models.Course{Cost: 100, BillingPlan: models.BillingPlan{Type: models.BillingPlanInstallments, Installments: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown plan",
+			course:  models.Course{Cost: 100, BillingPlan: models.BillingPlan{Type: "weekly"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles, err := planCycles(tt.course, start)
+			if tt.wantErr {
+				var validation *ValidationError
+				if !errors.As(err, &validation) {
+					t.Fatalf("error = %v, want ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planCycles: %v", err)
+			}
+			if len(cycles) != len(tt.wantAmounts) {
+				t.Fatalf("got %d cycles, want %d", len(cycles), len(tt.wantAmounts))
+			}
+			var total int64
+			for i, cycle := range cycles {
+				total += toCents(cycle.Amount)
+				if cycle.Seq != i+1 {
+					t.Errorf("cycle %d: seq = %d", i, cycle.Seq)
+				}
+				if cycle.Amount != tt.wantAmounts[i] {
+					t.Errorf("cycle %d: amount = %v, want %v", i, cycle.Amount, tt.wantAmounts[i])
+				}
+				issue := cycle.IssueDate.Time().UTC()
+				if got := issue.Format("2006-01-02"); got != tt.wantIssue[i] {
+					t.Errorf("cycle %d: issue date = %s, want %s", i, got, tt.wantIssue[i])
+				}
+				if due := cycle.DueDate.Time().UTC(); !due.Equal(issue.AddDate(0, 0, tt.wantDueDays)) {
+					t.Errorf("cycle %d: due date = %s, want %d days after %s", i, due, tt.wantDueDays, issue)
+				}
+			}
+			// Cost adalah harga total kursus untuk semua rencana penagihan
+			if total != toCents(tt.course.Cost) {
+				t.Errorf("cycles add up to %d cents, want %d", total, toCents(tt.course.Cost))
+			}
+		})
+	}
+}
+
+func TestIssueDueTagihanIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{Type: models.BillingPlanMonthly}, 3)
+
+	schedule, issued, err := svc.Enroll(ctx, EnrollInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	if len(issued) != 1 {
+		t.Fatalf("Enroll issued %d tagihan, want 1", len(issued))
+	}
+
+	steps := []struct {
+		name       string
+		advance    int // Bulan yang dilewati sejak langkah sebelumnya
+		wantTotal  int
+		wantStatus string
+	}{
+		{name: "same day", advance: 0, wantTotal: 1, wantStatus: models.BillingScheduleActive},
+		{name: "second cycle", advance: 1, wantTotal: 2, wantStatus: models.BillingScheduleActive},
+		{name: "second cycle again", advance: 0, wantTotal: 2, wantStatus: models.BillingScheduleActive},
+		{name: "last cycle", advance: 1, wantTotal: 3, wantStatus: models.BillingScheduleCompleted},
+		{name: "after completion", advance: 1, wantTotal: 3, wantStatus: models.BillingScheduleCompleted},
+	}
+	start := clock.now
+	months := 0
+	for _, step := range steps {
+		months += step.advance
+		clock.now = addMonths(start, months)
+		if err := svc.IssueDueTagihan(ctx); err != nil {
+			t.Fatalf("%s: IssueDueTagihan: %v", step.name, err)
+		}
+		// Dijalankan dua kali untuk meniru dua scheduler di waktu yang sama
+		if err := svc.IssueDueTagihan(ctx); err != nil {
+			t.Fatalf("%s: IssueDueTagihan: %v", step.name, err)
+		}
+
+		tagihans, err := repos.Tagihan.List(ctx, repository.TagihanFilter{SiswaID: &siswa.ID})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(tagihans) != step.wantTotal {
+			t.Errorf("%s: %d tagihan, want %d", step.name, len(tagihans), step.wantTotal)
+		}
+		cycles := map[int]bool{}
+		for _, tagihan := range tagihans {
+			if cycles[tagihan.Cycle] {
+				t.Errorf("%s: cycle %d issued twice", step.name, tagihan.Cycle)
+			}
+			cycles[tagihan.Cycle] = true
+		}
+
+		latest, err := svc.Schedule(ctx, schedule.ID)
+		if err != nil {
+			t.Fatalf("Schedule: %v", err)
+		}
+		if latest.Status != step.wantStatus {
+			t.Errorf("%s: schedule status = %q, want %q", step.name, latest.Status, step.wantStatus)
+		}
+	}
+}
+
+func TestCancelledScheduleStopsIssuing(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{Type: models.BillingPlanMonthly}, 3)
+
+	schedule, _, err := svc.Enroll(ctx, EnrollInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	if _, err := svc.CancelSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("CancelSchedule: %v", err)
+	}
+	if _, err := svc.CancelSchedule(ctx, schedule.ID); !errors.Is(err, ErrScheduleNotActive) {
+		t.Errorf("second CancelSchedule: error = %v, want %v", err, ErrScheduleNotActive)
+	}
+
+	// Jadwal yang dibaca sebelum dibatalkan tidak boleh menerbitkan atau menghidupkannya lagi
+	clock.now = addMonths(clock.now, 2)
+	issued, err := svc.issueCycles(ctx, &schedule, siswa, course, clock.now)
+	if err != nil {
+		t.Fatalf("issueCycles: %v", err)
+	}
+	if len(issued) != 0 {
+		t.Errorf("issued %d tagihan from a cancelled schedule", len(issued))
+	}
+	if schedule.Status != models.BillingScheduleCancelled {
+		t.Errorf("schedule status = %q, want %q", schedule.Status, models.BillingScheduleCancelled)
+	}
+
+	if err := svc.IssueDueTagihan(ctx); err != nil {
+		t.Fatalf("IssueDueTagihan: %v", err)
+	}
+	tagihans, err := repos.Tagihan.List(ctx, repository.TagihanFilter{SiswaID: &siswa.ID})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tagihans) != 1 {
+		t.Errorf("%d tagihan after cancelling, want 1", len(tagihans))
+	}
+	latest, err := svc.Schedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if latest.Status != models.BillingScheduleCancelled {
+		t.Errorf("stored schedule status = %q, want %q", latest.Status, models.BillingScheduleCancelled)
+	}
+}
+
+func TestIssueDueTagihanClaimsOrphanedTagihan(t *testing.T) {
+	ctx := context.Background()
+	svc, repos, clock, siswa, course := newTestBilling(t, models.BillingPlan{Type: models.BillingPlanMonthly}, 3)
+
+	schedule, _, err := svc.Enroll(ctx, EnrollInput{SiswaID: siswa.ID, CourseID: course.ID})
+	if err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+
+	// Tagihan siklus kedua tersimpan, tetapi prosesnya berhenti sebelum siklusnya diklaim
+	orphan := models.Tagihan{SiswaID: siswa.ID, CourseID: course.ID, ScheduleID: &schedule.ID, Cycle: 2, Status: models.TagihanStatusUnpaid}
+	if err := repos.Tagihan.Create(ctx, &orphan); err != nil {
+		t.Fatalf("create tagihan: %v", err)
+	}
+
+	clock.now = addMonths(clock.now, 1)
+	if err := svc.IssueDueTagihan(ctx); err != nil {
+		t.Fatalf("IssueDueTagihan: %v", err)
+	}
+
+	latest, err := svc.Schedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if claimed := latest.Cycles[1].TagihanID; claimed == nil || *claimed != orphan.ID {
+		t.Errorf("cycle 2 claimed by %v, want %s", claimed, orphan.ID.Hex())
+	}
+	tagihans, err := repos.Tagihan.List(ctx, repository.TagihanFilter{SiswaID: &siswa.ID})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tagihans) != 2 {
+		t.Errorf("%d tagihan, want 2", len(tagihans))
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/organisasi/tubesbackend/repository"
+)
+
+// PurgeTrash menghapus permanen data yang sudah berada di trash lebih lama
+// dari retention. Semua koleksi tetap diproses walaupun ada yang gagal.
+func PurgeTrash(ctx context.Context, repos *repository.Repositories, retention time.Duration) error {
+	before := time.Now().Add(-retention)
+	purgers := repos.Purgers()
+
+	names := make([]string, 0, len(purgers))
+	for name := range purgers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var firstErr error
+	for _, name := range names {
+		n, err := purgers[name].Purge(ctx, before)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if n > 0 {
+			log.Printf("Purged %d %s from trash", n, name)
+		}
+	}
+	return firstErr
+}
@@ -6,34 +6,55 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Secret key untuk JWT
-var jwtSecret = []byte("your-secret-key")
+// Masa berlaku token. Access token sengaja dibuat pendek karena bisa
+// diperbarui lewat refresh token. Nilainya bisa diganti dari konfigurasi.
+var (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
 
-// GenerateJWT untuk membuat token JWT dengan UserID dan Role
-func GenerateJWT(userID, role string) (string, error) {
+// GenerateJWT untuk membuat access token JWT dengan UserID, Role dan jti sesi
+func GenerateJWT(userID, role, jti string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // Token berlaku 24 jam
+		"jti":     jti,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtSecret)
+	// Tanda tangani dengan kunci aktif dan cantumkan kid-nya di header
+	key := currentKeySet().signingKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	signedToken, err := token.SignedString(key.SignKey)
 	if err != nil {
 		return "", err
 	}
 	return signedToken, nil
 }
 
+// ChallengeTokenTTL adalah masa berlaku token tantangan 2FA
+const ChallengeTokenTTL = 5 * time.Minute
+
+// GenerateChallengeJWT membuat token tantangan berumur pendek untuk langkah
+// 2FA. Token ini tidak punya jti sehingga ditolak oleh AuthMiddleware.
+func GenerateChallengeJWT(userID, purpose string) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"purpose": purpose,
+		"exp":     time.Now().Add(ChallengeTokenTTL).Unix(),
+	}
+
+	key := currentKeySet().signingKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SignKey)
+}
+
 // VerifyJWT untuk memverifikasi dan mengekstrak claims dari token JWT
 func VerifyJWT(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Pastikan algoritma yang digunakan benar
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return jwtSecret, nil
-	})
+	// Kunci verifikasi dipilih berdasarkan kid, algoritmanya harus cocok dengan kunci tersebut
+	token, err := jwt.Parse(tokenString, currentKeySet().keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -45,4 +66,4 @@ func VerifyJWT(tokenString string) (jwt.MapClaims, error) {
 	}
 
 	return nil, jwt.ErrSignatureInvalid
-}
\ No newline at end of file
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey adalah satu kunci JWT yang dikenali lewat kid.
+// Untuk HS256 SignKey dan VerifyKey berisi secret yang sama. Kunci lama yang
+// hanya disimpan public key-nya tetap bisa memverifikasi token tetapi tidak
+// bisa dipakai untuk menandatangani.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   interface{}
+	VerifyKey interface{}
+}
+
+// KeySet berisi semua kunci yang diterima saat verifikasi dan kid kunci aktif untuk signing
+type KeySet struct {
+	ActiveKID string
+	Keys      map[string]*SigningKey
+}
+
+var (
+	keySetOnce sync.Once
+	keySet     *KeySet
+)
+
+// SetKeySet mengganti key set yang dipakai GenerateJWT dan VerifyJWT
+func SetKeySet(ks *KeySet) {
+	keySetOnce.Do(func() {})
+	keySet = ks
+}
+
+// currentKeySet memuat key set dari environment saat pertama kali dibutuhkan
+func currentKeySet() *KeySet {
+	keySetOnce.Do(func() {
+		ks, err := LoadKeySetFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to load JWT keys: %v", err)
+		}
+		keySet = ks
+	})
+	return keySet
+}
+
+// LoadKeySetFromEnv membaca konfigurasi kunci JWT dari environment:
+//
+//	JWT_KEYS_DIR    folder berisi <kid>.pem (RSA/Ed25519, private atau public) dan <kid>.secret (HMAC)
+//	JWT_ACTIVE_KID  kid yang dipakai untuk menandatangani token baru
+//	JWT_SECRET      secret HS256 sederhana (kid "default") jika JWT_KEYS_DIR tidak diisi
+//
+// Jika tidak ada yang dikonfigurasi, dibuat secret acak sehingga semua token
+// tidak berlaku lagi setelah server restart.
+func LoadKeySetFromEnv() (*KeySet, error) {
+	return LoadKeySet(os.Getenv("JWT_KEYS_DIR"), os.Getenv("JWT_SECRET"), os.Getenv("JWT_ACTIVE_KID"))
+}
+
+// LoadKeySet membuat key set dari folder kunci, secret HS256 dan kid aktif
+// (lihat LoadKeySetFromEnv untuk arti masing-masing parameter)
+func LoadKeySet(keysDir, secret, activeKID string) (*KeySet, error) {
+	ks := &KeySet{Keys: map[string]*SigningKey{}}
+
+	if keysDir != "" {
+		if err := ks.loadDir(keysDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if secret != "" {
+		ks.Keys["default"] = newHMACKey("default", []byte(secret))
+	}
+
+	if len(ks.Keys) == 0 {
+		log.Println("JWT_SECRET / JWT_KEYS_DIR not set, using a random ephemeral signing key")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		ks.Keys["ephemeral"] = newHMACKey("ephemeral", secret)
+	}
+
+	ks.ActiveKID = activeKID
+	if ks.ActiveKID == "" {
+		if _, ok := ks.Keys["default"]; ok {
+			ks.ActiveKID = "default"
+		} else if len(ks.Keys) == 1 {
+			for kid := range ks.Keys {
+				ks.ActiveKID = kid
+			}
+		} else {
+			return nil, errors.New("JWT_ACTIVE_KID is required when several keys are configured")
+		}
+	}
+
+	active, ok := ks.Keys[ks.ActiveKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found", ks.ActiveKID)
+	}
+	if active.SignKey == nil {
+		return nil, fmt.Errorf("active key %q has no private key", ks.ActiveKID)
+	}
+	return ks, nil
+}
+
+// loadDir memuat semua file kunci di dalam folder; nama file tanpa ekstensi menjadi kid
+func (ks *KeySet) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		kid := strings.TrimSuffix(name, ext)
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		switch ext {
+		case ".secret":
+			ks.Keys[kid] = newHMACKey(kid, []byte(strings.TrimSpace(string(data))))
+		case ".pem":
+			key, err := parsePEMKey(kid, data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			ks.Keys[kid] = key
+		}
+	}
+	return nil
+}
+
+func newHMACKey(kid string, secret []byte) *SigningKey {
+	return &SigningKey{ID: kid, Method: jwt.SigningMethodHS256, SignKey: secret, VerifyKey: secret}
+}
+
+// parsePEMKey mengenali private key PKCS#1/PKCS#8 atau public key PKIX untuk RSA dan Ed25519
+func parsePEMKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, SignKey: k, VerifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, VerifyKey: k}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, SignKey: k, VerifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, VerifyKey: k}, nil
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+// signingKey mengembalikan kunci aktif untuk menandatangani token
+func (ks *KeySet) signingKey() *SigningKey {
+	return ks.Keys[ks.ActiveKID]
+}
+
+// keyFunc dipakai jwt.Parse untuk memilih kunci verifikasi berdasarkan header kid
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = ks.ActiveKID
+	}
+
+	key, ok := ks.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	// Pastikan algoritma token sama dengan algoritma kunci (mencegah alg confusion)
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.VerifyKey, nil
+}
+
+// JWK adalah representasi public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicJWKS mengembalikan semua public key (RSA dan Ed25519) dalam key set.
+// Secret HMAC tidak pernah dipublikasikan.
+func PublicJWKS() []JWK {
+	ks := currentKeySet()
+	b64 := base64.RawURLEncoding
+
+	kids := make([]string, 0, len(ks.Keys))
+	for kid := range ks.Keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := []JWK{}
+	for _, kid := range kids {
+		key := ks.Keys[kid]
+		switch pub := key.VerifyKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   b64.EncodeToString(pub.N.Bytes()),
+				E:   b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   b64.EncodeToString(pub),
+			})
+		}
+	}
+	return keys
+}
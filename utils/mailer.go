@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mail adalah satu email teks sederhana
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah antarmuka pengirim email sehingga implementasinya bisa diganti
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// SMTPMailer mengirim email lewat server SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send mengirim email lewat SMTP (STARTTLS jika didukung server, PLAIN auth
+// jika Username diisi). Koneksi mengikuti deadline dan pembatalan ctx agar
+// server SMTP yang macet tidak menahan request selamanya.
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + mail.To,
+		"Subject: " + mail.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		mail.Body,
+	}, "\r\n")
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Pembatalan tanpa deadline memutus koneksi sehingga operasi yang sedang menunggu gagal
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return m.ctxErr(ctx, err)
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return m.ctxErr(ctx, err)
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	if err := client.Rcpt(mail.To); err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	if err := w.Close(); err != nil {
+		return m.ctxErr(ctx, err)
+	}
+	return m.ctxErr(ctx, client.Quit())
+}
+
+// ctxErr mengutamakan error dari ctx, karena error koneksi yang diputus
+// karena timeout atau pembatalan kurang jelas bagi pemanggil
+func (m *SMTPMailer) ctxErr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// LogMailer tidak mengirim email, hanya menulisnya ke file atau ke log.
+// Dipakai untuk development dan testing lokal.
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Send menulis email ke file (append) atau ke log jika Path kosong
+func (m *LogMailer) Send(ctx context.Context, mail Mail) error {
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n----\n",
+		time.Now().Format(time.RFC3339), mail.To, mail.Subject, mail.Body)
+
+	if m.Path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
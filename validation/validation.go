@@ -0,0 +1,133 @@
+// Package validation mendaftarkan aturan validasi tambahan ke validator Gin dan
+// mengubah error binding menjadi daftar error per field yang bisa ditampilkan form.
+//
+// Aturan tambahan yang bisa dipakai di tag binding:
+//
+//	objectid  string hex ObjectID MongoDB
+//	phone_id  nomor telepon Indonesia, contoh 081234567890 atau +6281234567890
+//	date      tanggal dengan format YYYY-MM-DD
+//	month     bulan dengan format YYYY-MM
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// phonePattern menerima awalan 0, 62 atau +62 diikuti nomor seluler 8xx
+var phonePattern = regexp.MustCompile(`^(\+62|62|0)8[1-9][0-9]{6,11}$`)
+
+// FieldError adalah satu field yang gagal validasi
+type FieldError struct {
+	Field   string `json:"field"`           // Nama field sesuai JSON, contoh "email" atau "ids[2]"
+	Rule    string `json:"rule"`            // Aturan yang dilanggar, contoh "required"
+	Param   string `json:"param,omitempty"` // Parameter aturan, contoh "8" untuk min=8
+	Message string `json:"message"`
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Pakai nama field JSON di error, bukan nama field struct Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("objectid", func(fl validator.FieldLevel) bool {
+		return primitive.IsValidObjectID(fl.Field().String())
+	})
+	_ = v.RegisterValidation("phone_id", func(fl validator.FieldLevel) bool {
+		return phonePattern.MatchString(NormalizePhone(fl.Field().String()))
+	})
+	_ = v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("2006-01-02", fl.Field().String())
+		return err == nil
+	})
+	_ = v.RegisterValidation("month", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse("2006-01", fl.Field().String())
+		return err == nil
+	})
+}
+
+// NormalizePhone membuang spasi, tanda hubung dan tanda kurung dari nomor telepon
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+// Fields mengubah error dari ShouldBind menjadi daftar FieldError. Hasilnya nil
+// jika error bukan kesalahan per field (misalnya body bukan JSON yang valid).
+// Message diisi dengan key katalog i18n, contoh "validation.required".
+func Fields(err error) []FieldError {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make([]FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: messageKey(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: "validation.type",
+		}}
+	}
+	return nil
+}
+
+// fieldPath membuang nama struct di depan namespace, "Request.ids[0]" -> "ids[0]"
+func fieldPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// messageKey memilih key pesan. Aturan panjang (min, max, len) punya pesan
+// berbeda untuk teks dan daftar.
+func messageKey(fe validator.FieldError) string {
+	key := "validation." + fe.Tag()
+	switch fe.Tag() {
+	case "min", "max", "len":
+		switch fe.Kind() {
+		case reflect.String:
+			key += ".string"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key += ".list"
+		}
+	}
+	return key
+}